@@ -1,9 +1,14 @@
 package cmd
 
 import (
+	"bufio"
 	"fmt"
-	"opencode-cli/internal/core"
 	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
 
 	"github.com/spf13/cobra"
 )
@@ -14,74 +19,145 @@ var applyCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
 		silent, _ := cmd.Flags().GetBool("silent")
+		pseudo, _ := cmd.Flags().GetBool("pseudo")
+		strategyName, _ := cmd.Flags().GetString("strategy")
+		jobs, _ := cmd.Flags().GetInt("jobs")
+		interactive, _ := cmd.Flags().GetBool("interactive")
+		only, _ := cmd.Flags().GetStringSlice("only")
+		exclude, _ := cmd.Flags().GetStringSlice("exclude")
+		flavor, _ := cmd.Flags().GetString("flavor")
+		commit, _ := cmd.Flags().GetBool("commit")
+		if commit && dryRun {
+			fmt.Println("错误: --commit 与 --dry-run 不能同时使用")
+			os.Exit(1)
+		}
+
+		strategy, err := i18n.ParseStrategy(strategyName)
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
+		// 模拟运行只读取源码
+		lockMode := core.LockExclusive
+		if dryRun {
+			lockMode = core.LockShared
+		}
+		release, ok := lockTargets(lockMode, lockSource)
+		if !ok {
+			os.Exit(1)
+		}
+		defer release()
+
+		// Ctrl-C 时停止处理剩余文件，并把已写入的文件还原为应用前的内容
+		ctx, rollback, finish := interruptible()
+		defer finish()
 
-		i18n, err := core.NewI18n()
+		engine, err := i18n.Default(os.Stdout)
 		if err != nil {
 			fmt.Printf("错误: 初始化失败: %v\n", err)
 			os.Exit(1)
 		}
 
-		configs, err := i18n.LoadConfig()
+		rules, err := engine.Rules(ctx)
 		if err != nil {
 			fmt.Printf("错误: 加载配置失败: %v\n", err)
 			os.Exit(1)
 		}
 
+		// 未指定 --flavor 时使用已保存的风味
+		if !cmd.Flags().Changed("flavor") {
+			flavor = savedFlavor()
+		}
+		rules, err = engine.Select(rules, i18n.Selection{Only: only, Exclude: exclude, Flavor: flavor})
+		if err != nil {
+			fmt.Printf("错误: %v\n", err)
+			os.Exit(1)
+		}
+
 		if !silent {
+			if pseudo {
+				fmt.Println("使用伪本地化模式 (编译运行后仍为普通英文的文本即未被汉化包覆盖)")
+			}
 			if dryRun {
 				fmt.Println("模拟应用汉化配置...")
 			} else {
 				fmt.Println("应用汉化配置...")
 			}
-			fmt.Printf("找到 %d 个配置文件\n", len(configs))
+			fmt.Printf("找到 %d 个配置文件\n", len(rules))
+			if flavor != "" && flavor != i18n.FlavorNone {
+				fmt.Printf("使用汉化风味: %s\n", flavor)
+			}
 		}
 
-		stats := struct {
-			Files struct {
-				Total   int
-				Success int
-				Skipped int
-				Failed  int
-			}
-			Replacements struct {
-				Total   int
-				Success int
-				Failed  int
+		opts := i18n.ApplyOptions{
+			DryRun:   dryRun,
+			Pseudo:   pseudo,
+			Strategy: strategy,
+			Workers:  jobs,
+		}
+		if commit {
+			// 汉化分支会被重置，中断时恢复分支位置和原来的检出（工作区不干净时提交会被拒绝，无需恢复）
+			if snapshot, err := core.SnapshotRepo(engine.SourceDir()); err == nil && snapshot.Clean() {
+				rollback.Add("恢复到 "+snapshot.String()+" 并还原汉化分支", snapshot.Restore)
 			}
-		}{}
-
-		for _, config := range configs {
-			result := i18n.ApplyConfig(config, dryRun)
-
-			stats.Files.Total++
-			if result.Skipped {
-				stats.Files.Skipped++
-			} else if result.Success {
-				stats.Files.Success++
-				if !silent {
-					fmt.Printf("  ✓ %s (%d/%d 处替换)\n", config.File, result.Replacements.Success, result.Replacements.Total)
-				}
-			} else {
-				stats.Files.Failed++
-				if !silent {
-					fmt.Printf("  ✗ %s 失败\n", config.File)
+		} else if !dryRun {
+			journal := &core.FileJournal{}
+			opts.Journal = journal
+			rollback.Add("还原已修改的源码文件", func() error {
+				restored, err := journal.Restore()
+				for _, path := range restored {
+					if rel, err := filepath.Rel(engine.SourceDir(), path); err == nil {
+						path = rel
+					}
+					fmt.Printf("    %s\n", path)
 				}
-			}
-
-			stats.Replacements.Total += result.Replacements.Total
-			stats.Replacements.Success += result.Replacements.Success
-			stats.Replacements.Failed += result.Replacements.Failed
+				return err
+			})
+		}
+		stdin := bufio.NewReader(os.Stdin)
+		if interactive {
+			opts.Review = promptOccurrence(stdin, engine.SourceDir())
 		}
 
-		if !silent {
-			fmt.Println("")
-			if dryRun {
-				fmt.Println("汉化模拟完成:")
-			} else {
-				fmt.Println("汉化应用完成:")
+		if commit {
+			branch, err := engine.CommitBranch(ctx, rules, "", opts)
+			if branch != nil && branch.Apply != nil && !silent {
+				printApplyReport(branch.Apply, false)
+			}
+			if ctx.Err() != nil {
+				return
 			}
-			fmt.Printf("  📁 文件: %d 成功, %d 跳过, %d 失败\n", stats.Files.Success, stats.Files.Skipped, stats.Files.Failed)
-			fmt.Printf("  📝 替换: %d/%d 成功\n", stats.Replacements.Success, stats.Replacements.Total)
+			if err != nil {
+				fmt.Printf("✗ %v\n", err)
+				os.Exit(1)
+			}
+			printBranchReport(branch)
+			if interactive {
+				saveReviewChoices(stdin, engine, branch.Apply)
+			}
+			if branch.Apply.Runtime != nil && !printRuntimeReport(branch.Apply.Runtime, false, silent) {
+				os.Exit(1)
+			}
+			return
+		}
+
+		report, err := engine.Apply(ctx, rules, opts)
+		if report != nil && !silent {
+			printApplyReport(report, dryRun)
+		}
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			fmt.Printf("✗ %v\n", err)
+			os.Exit(1)
+		}
+		if interactive {
+			saveReviewChoices(stdin, engine, report)
+		}
+		if report.Runtime != nil && !printRuntimeReport(report.Runtime, dryRun, silent) {
+			os.Exit(1)
 		}
 	},
 }
@@ -90,4 +166,179 @@ func init() {
 	rootCmd.AddCommand(applyCmd)
 	applyCmd.Flags().Bool("dry-run", false, "Simulate the application without modifying files")
 	applyCmd.Flags().Bool("silent", false, "Suppress output")
+	applyCmd.Flags().Bool("pseudo", false, "Apply a pseudo locale generated from the pack (accented and about 35% longer) to find untranslated or truncated strings")
+	applyCmd.Flags().BoolP("interactive", "i", false, "Confirm each match of rules that hit more than once or in suspicious places")
+	applyCmd.Flags().StringSlice("only", nil, "Only apply these modules from config.json (e.g. dialogs,routes)")
+	applyCmd.Flags().StringSlice("exclude", nil, "Skip these modules from config.json")
+	applyCmd.Flags().String("flavor", "", "Flavor to apply on top of the pack (overrides the saved one, \"none\" disables it)")
+	applyCmd.Flags().Bool("commit", false, "Apply on top of the upstream commit and commit the result on the "+core.TranslationBranch+" branch")
+	applyCmd.Flags().IntP("jobs", "j", 0, "Number of target files processed concurrently (0 = number of CPUs)")
+	applyCmd.Flags().String("strategy", "replace", "Apply strategy: replace (in-place) or runtime (dictionary module, language chosen by OPENCODE_LANG)")
+}
+
+// promptOccurrence 返回在终端中逐处确认匹配的审查回调
+func promptOccurrence(stdin *bufio.Reader, sourceDir string) i18n.OccurrenceReviewer {
+	return func(occ i18n.Occurrence, total int) i18n.ReviewDecision {
+		rel, err := filepath.Rel(sourceDir, occ.Path)
+		if err != nil {
+			rel = occ.Path
+		}
+
+		fmt.Println("")
+		fmt.Printf("%s  %q → %q  (第 %d/%d 处)\n", occ.Config.FileName, occ.From, occ.To, occ.Index, total)
+		mark := ""
+		if occ.Kind.Suspicious() {
+			mark = " ⚠ 可能误伤代码"
+		}
+		fmt.Printf("  %s:%d:%d  [%s]%s\n", filepath.ToSlash(rel), occ.Line, occ.Column, occ.Kind, mark)
+		for _, line := range occ.Context {
+			prefix := "   "
+			if line.Number == occ.Line {
+				prefix = " > "
+			}
+			fmt.Printf("    %s%5d | %s\n", prefix, line.Number, line.Text)
+		}
+
+		for {
+			fmt.Print("  替换此处? [y] 接受  [n] 跳过  [a] 本规则剩余全部接受: ")
+			answer, err := stdin.ReadString('\n')
+			switch strings.ToLower(strings.TrimSpace(answer)) {
+			case "y", "yes":
+				return i18n.ReviewAccept
+			case "n", "no":
+				return i18n.ReviewSkip
+			case "a", "all":
+				return i18n.ReviewAcceptRule
+			}
+			if err != nil {
+				// 输入结束时保守地跳过
+				fmt.Println("")
+				return i18n.ReviewSkip
+			}
+		}
+	}
+}
+
+// saveReviewChoices 询问是否将交互审查的选择保存为规则的匹配过滤器
+func saveReviewChoices(stdin *bufio.Reader, engine *i18n.Engine, report *i18n.ApplyReport) {
+	type pending struct {
+		rule    i18n.Rule
+		filters map[string][]int
+	}
+	var choices []pending
+	count := 0
+	for n, result := range report.Files {
+		if len(result.Selections) > 0 {
+			choices = append(choices, pending{rule: report.Configs[n], filters: result.Selections})
+			count += len(result.Selections)
+		}
+	}
+	if count == 0 {
+		return
+	}
+
+	fmt.Println("")
+	if _, ok := engine.PackDir(); !ok {
+		fmt.Printf("提示: %d 条规则的选择未保存 (当前使用内置汉化配置)\n", count)
+		return
+	}
+
+	fmt.Printf("将 %d 条规则的选择保存为 occurrences 过滤器 (下次无需确认)? [y/N]: ", count)
+	answer, _ := stdin.ReadString('\n')
+	if a := strings.ToLower(strings.TrimSpace(answer)); a != "y" && a != "yes" {
+		return
+	}
+
+	for _, choice := range choices {
+		if err := engine.SaveOccurrenceFilters(choice.rule, choice.filters); err != nil {
+			fmt.Printf("  ✗ %s: %v\n", choice.rule.FileName, err)
+			continue
+		}
+		if choice.rule.Glob != "" {
+			fmt.Printf("  ✓ %s (%s)\n", choice.rule.ConfigPath, choice.rule.File)
+			continue
+		}
+		fmt.Printf("  ✓ %s\n", choice.rule.ConfigPath)
+	}
+}
+
+// printBranchReport 输出汉化分支的提交结果和未能重新应用的规则
+func printBranchReport(report *i18n.BranchReport) {
+	fmt.Println("")
+	fmt.Printf("✓ 汉化已提交到分支 %s (%s，基于 %s)\n", i18n.TranslationBranch, core.ShortHash(report.Commit), core.ShortHash(report.Base))
+	if report.Carried > 0 {
+		fmt.Printf("  已迁移 %d 个手工提交\n", report.Carried)
+	}
+
+	missed := report.MissedRules()
+	if len(missed) > 0 {
+		fmt.Printf("  ⚠️ %d 条规则未能重新应用:\n", len(missed))
+		for _, rule := range missed {
+			fmt.Printf("     - %s: %s\n", rule.Config.FileName, core.Truncate(quoteOneLine(rule.From), 50))
+		}
+	}
+	fmt.Printf("  查看改动: git show %s\n", core.ShortHash(report.Commit))
+}
+
+// printApplyReport 输出每个文件的结果和汇总
+func printApplyReport(report *i18n.ApplyReport, dryRun bool) {
+	for n, result := range report.Files {
+		config := report.Configs[n]
+		file := config.File
+		if config.Glob != "" {
+			file = fmt.Sprintf("%s (%s)", file, config.Glob)
+		}
+		if result.Skipped {
+			continue
+		}
+		if result.Success {
+			fmt.Printf("  ✓ %s (%d/%d 处替换)\n", file, result.Replacements.Success, result.Replacements.Total)
+		} else {
+			fmt.Printf("  ✗ %s 失败\n", file)
+		}
+	}
+
+	for _, glob := range report.Globs {
+		if glob.Warning != "" {
+			fmt.Printf("  ⚠️ %s: %s\n", glob.Config.FileName, glob.Warning)
+		}
+	}
+
+	stats := report.Stats
+	fmt.Println("")
+	if dryRun {
+		fmt.Println("汉化模拟完成:")
+	} else {
+		fmt.Println("汉化应用完成:")
+	}
+	fmt.Printf("  📁 文件: %d 成功, %d 跳过, %d 失败\n", stats.Files.Success, stats.Files.Skipped, stats.Files.Failed)
+	fmt.Printf("  📝 替换: %d/%d 成功\n", stats.Replacements.Success, stats.Replacements.Total)
+}
+
+// printRuntimeReport 输出运行时字典策略的结果，存在缺失条目时返回 false
+func printRuntimeReport(runtime *i18n.RuntimeReport, dryRun, silent bool) bool {
+	if !silent {
+		fmt.Printf("  📖 运行时字典: %d 个条目\n", runtime.Entries)
+		if len(runtime.Skipped) > 0 {
+			fmt.Println("")
+			fmt.Printf("以下 %d 条规则无法改写为字典查找 (保持英文):\n", len(runtime.Skipped))
+			for _, skip := range runtime.Skipped {
+				fmt.Printf("  - %s: %s (%s)\n", skip.Config.FileName, core.Truncate(skip.From, 40), skip.Reason)
+			}
+		}
+	}
+
+	if len(runtime.Missing) > 0 {
+		fmt.Printf("✗ %d 个调用点缺少字典条目:\n", len(runtime.Missing))
+		for _, m := range runtime.Missing {
+			fmt.Printf("  - %s\n", m)
+		}
+		return false
+	}
+
+	if !silent && !dryRun {
+		fmt.Printf("  字典模块: %s\n", runtime.ModulePath)
+		fmt.Printf("  运行时通过 %s=%s 或 %s=en 切换语言\n", core.RuntimeLangEnv, core.RuntimeLocale, core.RuntimeLangEnv)
+	}
+	return true
 }
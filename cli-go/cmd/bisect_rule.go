@@ -0,0 +1,119 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+var bisectRuleCmd = &cobra.Command{
+	Use:   "bisect-rule <config|rule-id>",
+	Short: "查找导致汉化规则失效的上游提交",
+	Long: `Find the first upstream commit where a rule stopped matching its target file.
+Only the commits between supportedCommit (or --good) and HEAD (or --bad) that touch the
+file are checked, by reading the file from git, so nothing is built or checked out.
+Pass a rule ID such as dialogs/dialog-model#1a2b3c4d, or a config name to check every
+rule of that config that no longer matches (narrow it down with --from).`,
+	Args: cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		from, _ := cmd.Flags().GetString("from")
+		good, _ := cmd.Flags().GetString("good")
+		bad, _ := cmd.Flags().GetString("bad")
+		if !runBisectRule(args[0], from, good, bad) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	bisectRuleCmd.Flags().String("from", "", "Only bisect the rule with this source text")
+	bisectRuleCmd.Flags().String("good", "", "Commit where the rule still matched (defaults to supportedCommit in config.json)")
+	bisectRuleCmd.Flags().String("bad", "HEAD", "Commit where the rule no longer matches")
+	rootCmd.AddCommand(bisectRuleCmd)
+}
+
+// runBisectRule 执行规则二分查找，返回是否成功
+func runBisectRule(spec, from, good, bad string) bool {
+	fmt.Println("\n▶ 查找规则失效的提交")
+
+	release, ok := lockTargets(core.LockShared, lockSource)
+	if !ok {
+		return false
+	}
+	defer release()
+
+	engine, err := i18n.Default(os.Stdout)
+	if err != nil {
+		fmt.Printf("✗ 初始化失败: %v\n", err)
+		return false
+	}
+	ctx := context.Background()
+	configs, err := engine.Rules(ctx)
+	if err != nil {
+		fmt.Printf("✗ 加载配置失败: %v\n", err)
+		return false
+	}
+	rules, err := i18n.FindRules(configs, spec, from)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return false
+	}
+
+	if good == "" {
+		if good, err = loadSupportedCommit(io.Discard); err != nil {
+			fmt.Printf("✗ %v，请使用 --good 指定仍然匹配的提交\n", err)
+			return false
+		}
+	}
+
+	// 指定配置时只检查在 bad 上已失效的规则
+	if len(rules) > 1 {
+		var broken []i18n.RuleBisect
+		for _, rule := range rules {
+			if !engine.RuleMatchesAt(rule, bad) {
+				broken = append(broken, rule)
+			}
+		}
+		if len(broken) == 0 {
+			fmt.Printf("✓ %s 的 %d 条规则在 %s 上全部匹配\n", spec, len(rules), bad)
+			return true
+		}
+		rules = broken
+	}
+
+	success := true
+	for _, rule := range rules {
+		result, err := engine.BisectRule(ctx, rule, good, bad)
+		fmt.Println("")
+		if err != nil {
+			fmt.Printf("✗ %s  %s\n  %v\n", rule.ID, core.Truncate(quoteOneLine(rule.From), 50), err)
+			success = false
+			continue
+		}
+		printRuleBisect(result)
+	}
+	return success
+}
+
+// printRuleBisect 输出规则失效的提交及相关 diff
+func printRuleBisect(result *core.RuleBisect) {
+	fmt.Printf("✗ %s  %s\n", result.ID, core.Truncate(quoteOneLine(result.From), 50))
+	fmt.Printf("  文件: %s\n", result.Path)
+	fmt.Printf("  首个失效提交: %s  %s  %s\n", core.ShortHash(result.Bad), result.Date, result.Author)
+	fmt.Printf("                %s\n", result.Subject)
+	fmt.Printf("  最后匹配提交: %s\n", core.ShortHash(result.Good))
+	fmt.Printf("  (范围内 %d 个提交修改了该文件，共检查 %d 个提交)\n", result.Commits, result.Steps)
+	if result.Hunks != "" {
+		fmt.Println("")
+		for _, line := range strings.Split(result.Hunks, "\n") {
+			fmt.Printf("    %s\n", line)
+		}
+	}
+}
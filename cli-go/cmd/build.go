@@ -1,22 +1,48 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
 	"os"
+	"path/filepath"
 
 	"github.com/spf13/cobra"
 )
 
+// errLocked 目录被其他进程锁定（详情已输出）
+var errLocked = errors.New("目录正被其他进程使用")
+
 var buildCmd = &cobra.Command{
 	Use:   "build",
 	Short: "编译构建 OpenCode",
+	Long:  "Build OpenCode from the source checkout, or with --worktree in an isolated git worktree per ref and locale so the main checkout stays pristine",
 	Run: func(cmd *cobra.Command, args []string) {
 		platform, _ := cmd.Flags().GetString("platform")
 		deploy, _ := cmd.Flags().GetBool("deploy")
 		silent, _ := cmd.Flags().GetBool("silent")
+		worktree, _ := cmd.Flags().GetBool("worktree")
+		ref, _ := cmd.Flags().GetString("ref")
+		locale, _ := cmd.Flags().GetString("locale")
+
+		if !worktree {
+			if cmd.Flags().Changed("ref") || cmd.Flags().Changed("locale") {
+				fmt.Println("错误: --ref / --locale 需要配合 --worktree 使用")
+				os.Exit(1)
+			}
+			if err := RunBuild(platform, deploy, silent); err != nil {
+				os.Exit(1)
+			}
+			return
+		}
 
-		if err := RunBuild(platform, deploy, silent); err != nil {
+		// worktree 构建默认不覆盖本地 bin 中的 opencode，需显式 --deploy
+		if !cmd.Flags().Changed("deploy") {
+			deploy = false
+		}
+		if err := RunWorktreeBuild(ref, locale, platform, deploy, silent); err != nil {
 			os.Exit(1)
 		}
 	},
@@ -25,8 +51,11 @@ var buildCmd = &cobra.Command{
 func init() {
 	rootCmd.AddCommand(buildCmd)
 	buildCmd.Flags().StringP("platform", "p", "", "Target platform (windows-x64, darwin-arm64, linux-x64)")
-	buildCmd.Flags().BoolP("deploy", "d", true, "Deploy to local bin directory")
+	buildCmd.Flags().BoolP("deploy", "d", true, "Deploy to local bin directory (off by default with --worktree)")
 	buildCmd.Flags().Bool("silent", false, "Suppress output")
+	buildCmd.Flags().Bool("worktree", false, "Build in an isolated worktree under ~/.opencode-i18n/worktrees instead of the main checkout")
+	buildCmd.Flags().String("ref", "HEAD", "Tag, branch or commit to build with --worktree")
+	buildCmd.Flags().String("locale", core.RuntimeLocale, "Locale to build with --worktree (zh-CN applies the pack, en builds upstream as is)")
 }
 
 // RunBuild 供外部调用的构建函数
@@ -34,18 +63,141 @@ func init() {
 // deploy: 构建后是否部署到本地 bin 目录
 // silent: 是否抑制输出
 // 返回: 构建错误，nil 表示成功
+// Ctrl-C 会终止 bun 进程，并删除本次未安装完成的 node_modules
 func RunBuild(platform string, deploy bool, silent bool) error {
-	if platform == "" {
-		platform = core.DetectPlatform()
+	targets := []lockTarget{lockSource}
+	if deploy {
+		targets = append(targets, lockBuild)
+	}
+	release, ok := lockTargets(core.LockExclusive, targets...)
+	if !ok {
+		return errLocked
 	}
+	defer release()
+
+	ctx, _, finish := interruptible()
+	defer finish()
 
 	builder, err := core.NewBuilder()
 	if err != nil {
 		fmt.Printf("错误: 初始化构建器失败: %v\n", err)
 		return err
 	}
+	return runBuilder(ctx, builder, platform, deploy, silent)
+}
 
-	err = builder.Build(platform, silent)
+// RunWorktreeBuild 在独立 worktree 中应用汉化并构建，主源码目录保持不变
+// ref: 要构建的标签 / 分支 / 提交；locale: zh-CN 应用汉化包，en 构建原版
+func RunWorktreeBuild(ref, locale, platform string, deploy bool, silent bool) error {
+	// 中断时工作区保持半应用状态即可，下次构建会强制重新检出
+	ctx, _, finish := interruptible()
+	defer finish()
+
+	locale, err := core.ParseLocale(locale)
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		return err
+	}
+
+	opencodeDir, err := core.GetOpencodeDir()
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		return err
+	}
+
+	// 主源码只读；同一 ref 和语言的工作区同时只能有一个构建
+	worktreesDir, err := core.GetWorktreesDir()
+	if err != nil {
+		fmt.Printf("错误: %v\n", err)
+		return err
+	}
+	releaseSource, ok := lockDirs(core.LockShared, opencodeDir)
+	if !ok {
+		return errLocked
+	}
+	defer releaseSource()
+	releaseWorktree, ok := lockDirs(core.LockExclusive, filepath.Join(worktreesDir, core.WorktreeName(ref, locale)))
+	if !ok {
+		return errLocked
+	}
+	defer releaseWorktree()
+	if deploy {
+		releaseBuild, ok := lockTargets(core.LockExclusive, lockBuild)
+		if !ok {
+			return errLocked
+		}
+		defer releaseBuild()
+	}
+
+	wt, err := core.CreateWorktree(opencodeDir, ref, locale)
+	if err != nil {
+		fmt.Printf("错误: 创建工作区失败: %v\n", err)
+		return err
+	}
+	fmt.Printf("工作区: %s\n", wt.Path)
+
+	if locale == core.RuntimeLocale {
+		if err := applyInWorktree(ctx, wt.Path, silent); err != nil {
+			fmt.Printf("错误: 应用汉化失败: %v\n", err)
+			return err
+		}
+	}
+
+	builder, err := core.NewBuilderAt(wt.Path)
+	if err != nil {
+		fmt.Printf("错误: 初始化构建器失败: %v\n", err)
+		return err
+	}
+	if err := runBuilder(ctx, builder, platform, deploy, silent); err != nil {
+		return err
+	}
+	if !deploy && !silent {
+		fmt.Printf("产物: %s\n", builder.GetDistPath(resolvePlatform(platform)))
+	}
+	return nil
+}
+
+// applyInWorktree 使用已保存的风味将汉化包应用到 worktree
+func applyInWorktree(ctx context.Context, dir string, silent bool) error {
+	engine, err := i18n.Default(os.Stdout)
+	if err != nil {
+		return err
+	}
+	engine = engine.WithSourceDir(dir)
+
+	rules, err := engine.Rules(ctx)
+	if err != nil {
+		return err
+	}
+	if settings, err := core.LoadSettings(); err == nil {
+		if rules, err = engine.ApplyFlavor(rules, settings.Flavor); err != nil {
+			return err
+		}
+	}
+
+	report, err := engine.Apply(ctx, rules, i18n.ApplyOptions{})
+	if report != nil && !silent {
+		printApplyReport(report, false)
+	}
+	return err
+}
+
+// resolvePlatform 空平台时自动检测
+func resolvePlatform(platform string) string {
+	if platform == "" {
+		return core.DetectPlatform()
+	}
+	return platform
+}
+
+// runBuilder 构建并按需部署
+func runBuilder(ctx context.Context, builder *core.Builder, platform string, deploy bool, silent bool) error {
+	platform = resolvePlatform(platform)
+
+	err := builder.Build(ctx, platform, silent)
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
 	if err != nil {
 		fmt.Printf("错误: 构建失败: %v\n", err)
 		return err
@@ -33,6 +33,17 @@ func init() {
 func runDeploy(createShortcut bool) {
 	fmt.Println("\n▶ 部署全局命令")
 
+	releaseBuild, ok := lockTargets(core.LockShared, lockBuild)
+	if !ok {
+		return
+	}
+	defer releaseBuild()
+	releaseDeploy, ok := lockTargets(core.LockExclusive, lockDeploy)
+	if !ok {
+		return
+	}
+	defer releaseDeploy()
+
 	binDir, err := core.GetBinDir()
 	if err != nil {
 		fmt.Printf("✗ 获取 bin 目录失败: %v\n", err)
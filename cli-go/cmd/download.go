@@ -3,6 +3,7 @@ package cmd
 import (
 	"archive/zip"
 	"bufio"
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -131,6 +132,16 @@ func runDownload() {
 		return
 	}
 
+	unlock, ok := lockTargets(core.LockExclusive, lockDeploy)
+	if !ok {
+		return
+	}
+	defer unlock()
+
+	// 确认后再进入可中断阶段，避免 Ctrl-C 时阻塞在上面的输入上
+	ctx, rollback, finish := interruptible()
+	defer finish()
+
 	// 4. 创建临时目录
 	tempDir := filepath.Join(os.TempDir(), "opencode-download")
 	os.RemoveAll(tempDir) // 清理旧的临时文件
@@ -139,6 +150,9 @@ func runDownload() {
 		return
 	}
 	defer os.RemoveAll(tempDir)
+	rollback.Add("删除临时下载目录 "+tempDir, func() error {
+		return os.RemoveAll(tempDir)
+	})
 
 	zipPath := filepath.Join(tempDir, assetName)
 
@@ -146,7 +160,10 @@ func runDownload() {
 	fmt.Println("")
 	fmt.Println("▶ 正在下载...")
 
-	if err := downloadFile(downloadURL, zipPath); err != nil {
+	if err := downloadFile(ctx, downloadURL, zipPath); err != nil {
+		if ctx.Err() != nil {
+			return
+		}
 		fmt.Printf("✗ 下载失败: %v\n", err)
 		fmt.Println("")
 		fmt.Println("  可能的解决方案:")
@@ -207,11 +224,27 @@ func runDownload() {
 
 	targetPath := filepath.Join(binDir, exeName)
 
-	// 复制文件
-	if err := copyFileWithProgress(exePath, targetPath); err != nil {
+	// 先复制到临时文件再替换，中断时不会留下半个可执行文件
+	tmpPath := targetPath + ".tmp"
+	rollback.Add("删除未完成的 "+filepath.Base(tmpPath), func() error {
+		if err := os.Remove(tmpPath); err != nil && !os.IsNotExist(err) {
+			return err
+		}
+		return nil
+	})
+	if err := copyFileWithProgress(ctx, exePath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		if ctx.Err() != nil {
+			return
+		}
 		fmt.Printf("✗ 复制文件失败: %v\n", err)
 		return
 	}
+	if err := os.Rename(tmpPath, targetPath); err != nil {
+		os.Remove(tmpPath)
+		fmt.Printf("✗ 替换文件失败: %v\n", err)
+		return
+	}
 
 	// 设置可执行权限 (Unix)
 	if runtime.GOOS != "windows" {
@@ -275,9 +308,13 @@ func getLatestRelease() (*GitHubRelease, error) {
 	return &release, nil
 }
 
-// downloadFile 下载文件（带进度显示）
-func downloadFile(url, dest string) error {
-	resp, err := http.Get(url)
+// downloadFile 下载文件（带进度显示），ctx 取消时中止下载并删除未完成的文件
+func downloadFile(ctx context.Context, url, dest string) (err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := http.DefaultClient.Do(req)
 	if err != nil {
 		return err
 	}
@@ -291,7 +328,12 @@ func downloadFile(url, dest string) error {
 	if err != nil {
 		return err
 	}
-	defer out.Close()
+	defer func() {
+		out.Close()
+		if err != nil {
+			os.Remove(dest)
+		}
+	}()
 
 	// 简单进度显示
 	totalSize := resp.ContentLength
@@ -301,7 +343,9 @@ func downloadFile(url, dest string) error {
 	for {
 		n, err := resp.Body.Read(buf)
 		if n > 0 {
-			out.Write(buf[:n])
+			if _, err := out.Write(buf[:n]); err != nil {
+				return err
+			}
 			downloaded += int64(n)
 
 			if totalSize > 0 {
@@ -315,6 +359,10 @@ func downloadFile(url, dest string) error {
 			break
 		}
 		if err != nil {
+			fmt.Println()
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
 			return err
 		}
 	}
@@ -380,8 +428,8 @@ func unzip(src, dest string) error {
 	return nil
 }
 
-// copyFileWithProgress 复制文件
-func copyFileWithProgress(src, dst string) error {
+// copyFileWithProgress 复制文件，ctx 取消时中止
+func copyFileWithProgress(ctx context.Context, src, dst string) error {
 	sourceFile, err := os.Open(src)
 	if err != nil {
 		return err
@@ -394,10 +442,23 @@ func copyFileWithProgress(src, dst string) error {
 	}
 	defer destFile.Close()
 
-	_, err = io.Copy(destFile, sourceFile)
+	_, err = io.Copy(destFile, contextReader{ctx, sourceFile})
 	return err
 }
 
+// contextReader 在 ctx 取消后让读取返回 ctx 的错误
+type contextReader struct {
+	ctx context.Context
+	r   io.Reader
+}
+
+func (r contextReader) Read(p []byte) (int, error) {
+	if err := r.ctx.Err(); err != nil {
+		return 0, err
+	}
+	return r.r.Read(p)
+}
+
 // configurePathForDownload 配置 PATH 环境变量
 func configurePathForDownload(binDir string) error {
 	switch runtime.GOOS {
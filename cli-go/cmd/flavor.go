@@ -0,0 +1,117 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"sort"
+
+	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+var flavorCmd = &cobra.Command{
+	Use:   "flavor [name]",
+	Short: "查看或选择汉化风味 (术语保留英文等)",
+	Long: `List the flavors defined in config.json, or select one. A flavor is a set of term
+substitutions applied on top of the pack (for example keeping Provider and Model in English).
+The selection is saved in ~/.opencode-i18n/settings.json and used by apply and full.
+Use "none" to go back to the plain pack.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		if len(args) == 0 {
+			if !listFlavors() {
+				os.Exit(1)
+			}
+			return
+		}
+		if !selectFlavor(args[0]) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	rootCmd.AddCommand(flavorCmd)
+}
+
+// listFlavors 列出汉化包中的风味并标记当前选择
+func listFlavors() bool {
+	engine, err := i18n.Default(os.Stdout)
+	if err != nil {
+		fmt.Printf("✗ 初始化失败: %v\n", err)
+		return false
+	}
+	manifest, err := engine.Manifest()
+	if err != nil {
+		fmt.Printf("✗ 读取 %s 失败: %v\n", core.ManifestFileName, err)
+		return false
+	}
+	settings, err := core.LoadSettings()
+	if err != nil {
+		fmt.Printf("✗ 读取设置失败: %v\n", err)
+		return false
+	}
+
+	if len(manifest.Flavors) == 0 {
+		fmt.Println("汉化包未定义任何风味")
+		return true
+	}
+
+	names := make([]string, 0, len(manifest.Flavors))
+	for name := range manifest.Flavors {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("可用的汉化风味:")
+	for _, name := range names {
+		mark := " "
+		if name == settings.Flavor {
+			mark = "*"
+		}
+		flavor := manifest.Flavors[name]
+		fmt.Printf("  %s %-16s %s (%d 个术语)\n", mark, name, flavor.Description, len(flavor.Terms))
+	}
+	if settings.Flavor == "" {
+		fmt.Println("\n当前未使用风味，运行 opencode-cli flavor <name> 选择")
+	}
+	return true
+}
+
+// selectFlavor 选择风味并保存到用户设置
+func selectFlavor(name string) bool {
+	settings, err := core.LoadSettings()
+	if err != nil {
+		fmt.Printf("✗ 读取设置失败: %v\n", err)
+		return false
+	}
+
+	if name == core.FlavorNone {
+		settings.Flavor = ""
+	} else {
+		engine, err := i18n.Default(os.Stdout)
+		if err != nil {
+			fmt.Printf("✗ 初始化失败: %v\n", err)
+			return false
+		}
+		// 借助 ApplyFlavor 校验风味名称
+		if _, err := engine.ApplyFlavor(nil, name); err != nil {
+			fmt.Printf("✗ %v\n", err)
+			return false
+		}
+		settings.Flavor = name
+	}
+
+	if err := core.SaveSettings(settings); err != nil {
+		fmt.Printf("✗ 保存设置失败: %v\n", err)
+		return false
+	}
+	if settings.Flavor == "" {
+		fmt.Println("✓ 已取消汉化风味，下次 apply 使用原始汉化包")
+	} else {
+		fmt.Printf("✓ 已选择汉化风味: %s (下次 apply 时生效)\n", settings.Flavor)
+	}
+	return true
+}
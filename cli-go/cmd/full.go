@@ -1,6 +1,11 @@
 package cmd
 
 import (
+	"strings"
+
+	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
+
 	"github.com/spf13/cobra"
 )
 
@@ -13,12 +18,39 @@ var fullCmd = &cobra.Command{
 3. Apply: Apply Chinese translation patches
 4. Verify: Verify translation integrity
 5. Build: Compile the binary using Bun
-6. Deploy: Install to system path`,
+6. Deploy: Install to system path
+
+--only, --exclude, --flavor and --commit are passed to the apply step. Once the translation is
+committed on the ` + core.TranslationBranch + ` branch, update re-creates that branch with the same module and flavor
+selection and the apply step is skipped; if re-creating it fails, the translation is applied to the updated
+source instead.`,
 	Run: func(cmd *cobra.Command, args []string) {
+		// 将模块与风味选择转交给 apply 步骤，以及 update 中汉化分支的重建
+		sel := i18n.Selection{Flavor: savedFlavor()}
+		sel.Only, _ = cmd.Flags().GetStringSlice("only")
+		sel.Exclude, _ = cmd.Flags().GetStringSlice("exclude")
+		for _, name := range []string{"only", "exclude"} {
+			if cmd.Flags().Changed(name) {
+				modules, _ := cmd.Flags().GetStringSlice(name)
+				applyCmd.Flags().Set(name, strings.Join(modules, ","))
+			}
+		}
+		if cmd.Flags().Changed("flavor") {
+			sel.Flavor, _ = cmd.Flags().GetString("flavor")
+			applyCmd.Flags().Set("flavor", sel.Flavor)
+		}
+		rebuildSelection = &sel
+		if commit, _ := cmd.Flags().GetBool("commit"); commit {
+			applyCmd.Flags().Set("commit", "true")
+		}
 		runFullWorkflow()
 	},
 }
 
 func init() {
+	fullCmd.Flags().StringSlice("only", nil, "Only apply these modules from config.json (e.g. dialogs,routes)")
+	fullCmd.Flags().StringSlice("exclude", nil, "Skip these modules from config.json")
+	fullCmd.Flags().Bool("commit", false, "Commit the translation on the "+core.TranslationBranch+" branch instead of leaving a dirty tree")
+	fullCmd.Flags().String("flavor", "", "Flavor to apply on top of the pack (overrides the saved one, \"none\" disables it)")
 	rootCmd.AddCommand(fullCmd)
 }
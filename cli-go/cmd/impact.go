@@ -0,0 +1,132 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+var impactCmd = &cobra.Command{
+	Use:   "impact",
+	Short: "列出每条规则在源码中的全部匹配位置",
+	Long:  "List every location each rule matches (file:line, context and a classification such as string, JSX text, identifier, comment or import path) without modifying files",
+	Run: func(cmd *cobra.Command, args []string) {
+		rule, _ := cmd.Flags().GetString("rule")
+		kinds, _ := cmd.Flags().GetStringSlice("kind")
+		suspicious, _ := cmd.Flags().GetBool("suspicious")
+		contextLines, _ := cmd.Flags().GetInt("context")
+		if !runImpact(rule, kinds, suspicious, contextLines) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	impactCmd.Flags().String("rule", "", "Only show rules whose source text contains this string")
+	impactCmd.Flags().StringSlice("kind", nil, "Only show matches of these kinds (string, jsx-text, identifier, comment, import, code)")
+	impactCmd.Flags().Bool("suspicious", false, "Only show matches in identifiers, import paths or other code")
+	impactCmd.Flags().IntP("context", "C", 1, "Lines of context around each match")
+	rootCmd.AddCommand(impactCmd)
+}
+
+// runImpact 执行影响分析，返回是否成功
+func runImpact(rule string, kinds []string, suspicious bool, contextLines int) bool {
+	fmt.Println("\n▶ 汉化规则影响分析")
+
+	release, ok := lockTargets(core.LockShared, lockSource)
+	if !ok {
+		return false
+	}
+	defer release()
+
+	engine, err := i18n.Default(os.Stdout)
+	if err != nil {
+		fmt.Printf("✗ 初始化失败: %v\n", err)
+		return false
+	}
+	ctx := context.Background()
+	configs, err := engine.Rules(ctx)
+	if err != nil {
+		fmt.Printf("✗ 加载配置失败: %v\n", err)
+		return false
+	}
+
+	occurrences, err := engine.Occurrences(ctx, configs, contextLines)
+	if err != nil {
+		fmt.Printf("✗ 分析失败: %v\n", err)
+		return false
+	}
+
+	kindFilter := make(map[core.HitKind]bool)
+	for _, kind := range kinds {
+		kindFilter[core.HitKind(strings.TrimSpace(kind))] = true
+	}
+
+	var shown []core.Occurrence
+	for _, occ := range occurrences {
+		if rule != "" && !strings.Contains(occ.From, rule) {
+			continue
+		}
+		if len(kindFilter) > 0 && !kindFilter[occ.Kind] {
+			continue
+		}
+		if suspicious && !occ.Kind.Suspicious() {
+			continue
+		}
+		shown = append(shown, occ)
+	}
+
+	opencodeDir := engine.SourceDir()
+	byKind := make(map[core.HitKind]int)
+	rules := 0
+
+	for n, occ := range shown {
+		// 同一规则的匹配连续出现，在第一处打印规则标题
+		if n == 0 || occ.Config != shown[n-1].Config || occ.From != shown[n-1].From {
+			rules++
+			fmt.Println("")
+			fmt.Printf("%s  %s → %s\n",
+				occ.Config.FileName, core.Truncate(quoteOneLine(occ.From), 50), core.Truncate(quoteOneLine(occ.To), 50))
+		}
+
+		byKind[occ.Kind]++
+		rel, err := filepath.Rel(opencodeDir, occ.Path)
+		if err != nil {
+			rel = occ.Path
+		}
+
+		mark := " "
+		if occ.Kind.Suspicious() {
+			mark = "⚠"
+		}
+		fmt.Printf("  %s %s:%d:%d  [%s]\n", mark, filepath.ToSlash(rel), occ.Line, occ.Column, occ.Kind)
+		for _, line := range occ.Context {
+			prefix := "   "
+			if line.Number == occ.Line {
+				prefix = " > "
+			}
+			fmt.Printf("    %s%5d | %s\n", prefix, line.Number, line.Text)
+		}
+	}
+
+	fmt.Println("")
+	fmt.Printf("  共 %d 处匹配，涉及 %d 条规则\n", len(shown), rules)
+	for _, kind := range []core.HitKind{core.KindString, core.KindJSXText, core.KindIdentifier, core.KindComment, core.KindImport, core.KindCode} {
+		if byKind[kind] > 0 {
+			fmt.Printf("    %-10s %d\n", kind, byKind[kind])
+		}
+	}
+	return true
+}
+
+// quoteOneLine 将规则文本转为单行显示
+func quoteOneLine(s string) string {
+	return fmt.Sprintf("%q", s)
+}
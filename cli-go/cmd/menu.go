@@ -1,7 +1,9 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"path/filepath"
@@ -10,6 +12,7 @@ import (
 
 	"opencode-cli/internal/core"
 	"opencode-cli/internal/tui"
+	"opencode-cli/pkg/i18n"
 
 	"github.com/spf13/cobra"
 )
@@ -87,6 +90,16 @@ func getStatus() tui.StatusInfo {
 	// 获取 Bun 版本
 	bunVersion := getBunVersion()
 
+	// 源码相对汉化包 supportedCommit 的位置
+	var supported core.SupportedStatus
+	if sourceExists {
+		if engine, err := i18n.Default(io.Discard); err == nil {
+			if manifest, err := engine.Manifest(); err == nil {
+				supported = core.GetSupportedStatus(opencodeDir, manifest.SupportedCommit)
+			}
+		}
+	}
+
 	return tui.StatusInfo{
 		Version:       "v" + core.VERSION,
 		Path:          opencodeDir,
@@ -95,6 +108,7 @@ func getStatus() tui.StatusInfo {
 		BinaryExists:  binaryExists,
 		BunVersion:    bunVersion,
 		BunRecommend:  "1.3.8",
+		Supported:     supported,
 		CheckComplete: false, // 暂时不检测更新
 	}
 }
@@ -120,6 +134,10 @@ func executeAction(action string) {
 		fmt.Println("\n▶ 更新源码...")
 		updateCmd.Run(updateCmd, []string{})
 
+	case "versions":
+		fmt.Println("\n▶ 选择上游版本...")
+		selectVersion(context.Background())
+
 	case "restore":
 		fmt.Println("\n▶ 恢复源码...")
 		if dir, err := core.GetOpencodeDir(); err == nil {
@@ -250,6 +268,13 @@ func executeAction(action string) {
 
 // runFullWorkflow 完整工作流
 func runFullWorkflow() {
+	// 整个流程持有锁，避免其他终端在步骤之间修改源码或构建产物；各步骤沿用这些锁
+	release, ok := holdWorkflowLocks(lockSource, lockBuild, lockDeploy)
+	if !ok {
+		return
+	}
+	defer release()
+
 	// 0. 恢复源码 (确保纯净环境)
 	fmt.Println("▶ 正在准备纯净环境...")
 	if dir, err := core.GetOpencodeDir(); err == nil {
@@ -262,7 +287,12 @@ func runFullWorkflow() {
 	updateCmd.Run(updateCmd, []string{})
 
 	fmt.Println("\n[2/5] 应用汉化配置")
-	applyCmd.Run(applyCmd, []string{})
+	if dir, err := core.GetOpencodeDir(); err == nil && translationRebuilt && core.GitCurrentBranch(dir) == core.TranslationBranch {
+		// update 已在新的上游提交之上重建汉化分支（重建失败时仍需应用）
+		fmt.Printf("汉化已提交在分支 %s，跳过\n", core.TranslationBranch)
+	} else {
+		applyCmd.Run(applyCmd, []string{})
+	}
 
 	fmt.Println("\n[3/5] 验证汉化配置")
 	runVerify(false, false)
@@ -0,0 +1,72 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+
+	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+var packCmd = &cobra.Command{
+	Use:   "pack",
+	Short: "汉化包维护工具",
+	Long:  "Maintenance commands for the i18n pack (config.json manifest and rule files)",
+}
+
+var packFmtCmd = &cobra.Command{
+	Use:   "fmt",
+	Short: "规范化汉化包 (重写 modules、统一缩进和键顺序)",
+	Long:  "Rewrite config.json modules from the files on disk and canonicalize indentation and key order of all pack files",
+	Run: func(cmd *cobra.Command, args []string) {
+		check, _ := cmd.Flags().GetBool("check")
+		if !runPackFmt(check) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	packFmtCmd.Flags().Bool("check", false, "Only list files that need formatting, do not write")
+	packCmd.AddCommand(packFmtCmd)
+	rootCmd.AddCommand(packCmd)
+}
+
+// runPackFmt 规范化外部汉化包，返回是否成功（check 模式下有待格式化文件视为失败）
+func runPackFmt(check bool) bool {
+	engine, err := i18n.Default(os.Stdout)
+	if err != nil {
+		fmt.Printf("✗ 初始化失败: %v\n", err)
+		return false
+	}
+
+	packDir, ok := engine.PackDir()
+	if !ok {
+		fmt.Println("✗ 当前使用内置汉化配置，无法格式化")
+		fmt.Println("  请在汉化项目目录中运行，或设置 OPENCODE_PROJECT_DIR")
+		return false
+	}
+
+	changed, err := core.FormatPack(packDir, check)
+	if err != nil {
+		fmt.Printf("✗ 格式化失败: %v\n", err)
+		return false
+	}
+
+	if len(changed) == 0 {
+		fmt.Println("✓ 汉化包格式已规范")
+		return true
+	}
+
+	if check {
+		fmt.Printf("✗ %d 个文件需要格式化:\n", len(changed))
+	} else {
+		fmt.Printf("✓ 已格式化 %d 个文件:\n", len(changed))
+	}
+	for _, file := range changed {
+		fmt.Printf("  - %s\n", file)
+	}
+	return !check
+}
@@ -3,6 +3,7 @@ package cmd
 import (
 	"fmt"
 	"opencode-cli/internal/core"
+	"os"
 	"path/filepath"
 	"runtime"
 
@@ -13,6 +14,15 @@ var packageCmd = &cobra.Command{
 	Use:   "package",
 	Short: "打包发布版 (支持六平台)",
 	Run: func(cmd *cobra.Command, args []string) {
+		release, ok := lockTargets(core.LockExclusive, lockSource)
+		if !ok {
+			return
+		}
+		defer release()
+
+		ctx, rollback, finish := interruptible()
+		defer finish()
+
 		platform, _ := cmd.Flags().GetString("platform")
 		all, _ := cmd.Flags().GetBool("all")
 		// skipBinaries, _ := cmd.Flags().GetBool("skip-binaries")
@@ -72,7 +82,19 @@ var packageCmd = &cobra.Command{
 		var packages []*core.PackageInfo
 
 		for _, p := range platforms {
-			pkgInfo, err := packager.PackagePlatform(p, versionDir)
+			pkgInfo, err := packager.PackagePlatform(ctx, p, versionDir)
+			if ctx.Err() != nil {
+				// 已完成平台的压缩包保留，不生成只覆盖部分平台的发布说明和校验文件
+				for _, path := range packager.PartialFiles(p, versionDir) {
+					if core.Exists(path) {
+						rollback.Add("删除未完成的 "+filepath.Base(path), func() error {
+							return os.RemoveAll(path)
+						})
+					}
+				}
+				fmt.Printf("\n已跳过发布说明和校验文件 (%d 个平台已完成打包)\n", len(packages))
+				return
+			}
 			if err != nil {
 				fmt.Printf("打包 %s 失败: %v\n", p, err)
 			} else {
@@ -0,0 +1,122 @@
+package cmd
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
+
+	"github.com/spf13/cobra"
+)
+
+var patchBinaryCmd = &cobra.Command{
+	Use:   "patch-binary <opencode 可执行文件>",
+	Short: "汉化官方预编译二进制 (无需 Bun)",
+	Long:  "Apply the i18n pack to the JavaScript bundle embedded in an official compiled opencode executable and write a new executable",
+	Args:  cobra.ExactArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		output, _ := cmd.Flags().GetString("output")
+		dryRun, _ := cmd.Flags().GetBool("dry-run")
+		bunVersion, _ := cmd.Flags().GetString("bun-version")
+		if !runPatchBinary(args[0], output, bunVersion, dryRun) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	patchBinaryCmd.Flags().StringP("output", "o", "", "Output path (default: <input>-zh)")
+	patchBinaryCmd.Flags().Bool("dry-run", false, "Only report which rules can be applied, do not write")
+	patchBinaryCmd.Flags().String("bun-version", "", "Bun version the executable was built with (default: detect)")
+	rootCmd.AddCommand(patchBinaryCmd)
+}
+
+// defaultPatchedPath 返回默认输出路径：opencode -> opencode-zh, opencode.exe -> opencode-zh.exe
+func defaultPatchedPath(input string) string {
+	ext := filepath.Ext(input)
+	if !strings.EqualFold(ext, ".exe") {
+		ext = ""
+	}
+	return strings.TrimSuffix(input, ext) + "-zh" + ext
+}
+
+// runPatchBinary 汉化预编译二进制，返回是否成功
+func runPatchBinary(input, output, bunVersion string, dryRun bool) bool {
+	fmt.Println("\n▶ 汉化预编译二进制")
+
+	info, err := os.Stat(input)
+	if err != nil {
+		fmt.Printf("✗ 无法读取可执行文件: %v\n", err)
+		return false
+	}
+	data, err := os.ReadFile(input)
+	if err != nil {
+		fmt.Printf("✗ 无法读取可执行文件: %v\n", err)
+		return false
+	}
+
+	engine, err := i18n.Default(os.Stdout)
+	if err != nil {
+		fmt.Printf("✗ 初始化失败: %v\n", err)
+		return false
+	}
+	configs, err := engine.Rules(context.Background())
+	if err != nil {
+		fmt.Printf("✗ 加载配置失败: %v\n", err)
+		return false
+	}
+
+	result, err := core.PatchBunBinary(data, configs, bunVersion)
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return false
+	}
+
+	fmt.Printf("  格式: %s, Bun %s, 模块图 %.2f MB (%d 个源文件)\n",
+		result.Format, result.Bundle.Version, float64(result.Bundle.End-result.Bundle.Start)/(1024*1024), result.Modules)
+	if result.Modules == 0 {
+		fmt.Println("  ⚠ 模块图中没有源文件路径注释 (产物可能已压缩)，无法确定规则对应的代码范围")
+	}
+
+	if len(result.Issues) > 0 {
+		fmt.Printf("\n以下 %d 条规则无法安全应用:\n", len(result.Issues))
+		for _, issue := range result.Issues {
+			fmt.Printf("  - %s: %s (%s)\n", issue.Config.FileName, core.Truncate(issue.From, 40), issue.Reason)
+		}
+	}
+
+	fmt.Println("")
+	fmt.Printf("  📝 规则: %d 条已应用 (%d 处替换), %d 条跳过\n", result.Rules, result.Occurrences, len(result.Issues))
+
+	if dryRun {
+		return true
+	}
+	if result.Rules == 0 {
+		fmt.Println("✗ 没有可应用的规则，未生成文件")
+		return false
+	}
+
+	if output == "" {
+		output = defaultPatchedPath(input)
+	}
+	if err := os.WriteFile(output, data, info.Mode().Perm()); err != nil {
+		fmt.Printf("✗ 写入失败: %v\n", err)
+		return false
+	}
+	fmt.Printf("✓ 已生成: %s\n", output)
+
+	switch result.Format {
+	case core.FormatMachO:
+		fmt.Println("")
+		fmt.Println("  ⚠ 修改后原签名失效，macOS 上需要重新签名才能运行:")
+		fmt.Printf("    codesign --force --sign - %s\n", output)
+	case core.FormatPE:
+		fmt.Println("")
+		fmt.Println("  ⚠ 修改后原 Authenticode 签名失效，Windows 可能提示未知发布者")
+	}
+	return true
+}
@@ -1,8 +1,14 @@
 package cmd
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
 
 	"opencode-cli/internal/core"
 
@@ -20,9 +26,164 @@ var rootCmd = &cobra.Command{
 	},
 }
 
+// interrupt 记录 Ctrl-C / SIGTERM 的状态
+// depth > 0 表示有可中断的操作在进行，由它在结束时回滚并退出；否则收到信号时直接退出
+var interrupt struct {
+	sync.Mutex
+	ctx   context.Context
+	depth int
+}
+
 func Execute() {
-	if err := rootCmd.Execute(); err != nil {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	interrupt.ctx = ctx
+
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	go func() {
+		<-signals
+		// 恢复默认处理：回滚卡住时再按一次 Ctrl-C 可强制退出
+		signal.Stop(signals)
+		cancel()
+
+		interrupt.Lock()
+		defer interrupt.Unlock()
+		if interrupt.depth == 0 {
+			fmt.Println("\n操作已中断")
+			os.Exit(130)
+		}
+	}()
+
+	if err := rootCmd.ExecuteContext(ctx); err != nil {
 		fmt.Fprintln(os.Stderr, err)
 		os.Exit(1)
 	}
 }
+
+// interruptible 开始一个可中断的操作
+// 返回的 ctx 在收到 Ctrl-C / SIGTERM 时取消，调用方把清理步骤登记到 Rollback，
+// 并在操作结束时调用 finish：若已被中断则逆序执行回滚、输出恢复了什么，最外层以 130 退出
+func interruptible() (context.Context, *core.Rollback, func()) {
+	interrupt.Lock()
+	defer interrupt.Unlock()
+
+	ctx := interrupt.ctx
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	interrupt.depth++
+	rollback := &core.Rollback{}
+
+	finish := func() {
+		interrupt.Lock()
+		defer interrupt.Unlock()
+		interrupt.depth--
+		if ctx.Err() == nil {
+			return
+		}
+		if rollback.Len() > 0 {
+			fmt.Println("\n⚠ 操作已中断，正在回滚...")
+			if !rollback.Run(os.Stdout) {
+				fmt.Println("部分内容未能恢复，请检查上面的错误")
+			}
+		}
+		if interrupt.depth == 0 {
+			fmt.Println("\n操作已中断")
+			os.Exit(130)
+		}
+	}
+	return ctx, rollback, finish
+}
+
+// lockTarget 受目录锁保护的共享目录
+type lockTarget int
+
+const (
+	// lockSource OpenCode 源码目录 (~/.opencode-i18n/opencode)
+	lockSource lockTarget = iota
+	// lockBuild 构建产物目录 (~/.opencode-i18n/build)
+	lockBuild
+	// lockDeploy 部署目录 (~/.opencode-i18n/bin)
+	lockDeploy
+)
+
+func (t lockTarget) dir() (string, error) {
+	switch t {
+	case lockBuild:
+		return core.GetBinDir()
+	case lockDeploy:
+		return getDeployDir()
+	}
+	return core.GetOpencodeDir()
+}
+
+// workflowLocks 完整工作流已独占持有的目录，由 runFullWorkflow 登记，其中的各步骤不再重复加锁
+// 目录锁在同一进程内不可重入，外层必须显式把持有的锁交给嵌套步骤
+var workflowLocks map[lockTarget]bool
+
+// holdWorkflowLocks 独占锁定 targets 并登记给完整工作流的各步骤，返回的 release 同时取消登记
+func holdWorkflowLocks(targets ...lockTarget) (func(), bool) {
+	release, ok := lockTargets(core.LockExclusive, targets...)
+	if !ok {
+		return nil, false
+	}
+	workflowLocks = make(map[lockTarget]bool)
+	for _, target := range targets {
+		workflowLocks[target] = true
+	}
+	return func() {
+		workflowLocks = nil
+		release()
+	}, true
+}
+
+// lockTargets 获取命令需要的目录锁，见 lockDirs；完整工作流已持有的目录跳过
+func lockTargets(mode core.LockMode, targets ...lockTarget) (func(), bool) {
+	dirs := make([]string, 0, len(targets))
+	for _, target := range targets {
+		if workflowLocks[target] {
+			continue
+		}
+		dir, err := target.dir()
+		if err != nil {
+			fmt.Printf("✗ 获取目录失败: %v\n", err)
+			return nil, false
+		}
+		dirs = append(dirs, dir)
+	}
+	return lockDirs(mode, dirs...)
+}
+
+// lockDirs 获取目录锁，防止两个终端同时修改同一源码、构建或部署目录
+// 被其他进程占用时输出占用者并返回 false；返回的 release 在命令结束时调用，
+// 进程被强制结束时残留的锁文件会在持有进程不存在后自动失效
+func lockDirs(mode core.LockMode, dirs ...string) (func(), bool) {
+	command := strings.Join(append([]string{"opencode-cli"}, os.Args[1:]...), " ")
+	if len(os.Args) == 1 {
+		command += " (交互菜单)"
+	}
+
+	var locks []*core.Lock
+	release := func() {
+		for n := len(locks) - 1; n >= 0; n-- {
+			locks[n].Release()
+		}
+	}
+	for _, dir := range dirs {
+		lock, err := core.AcquireLock(dir, mode, command)
+		if err != nil {
+			release()
+			var busy *core.LockBusyError
+			if errors.As(err, &busy) {
+				fmt.Printf("✗ %v\n", busy)
+				fmt.Println("  请等待该命令结束后重试 (持有进程退出后锁自动失效)")
+			} else {
+				fmt.Printf("✗ 获取目录锁失败: %v\n", err)
+			}
+			return nil, false
+		}
+		locks = append(locks, lock)
+	}
+	return release, true
+}
@@ -101,6 +101,16 @@ func runUninstall(force, all, keepConfig bool) {
 		}
 	}
 
+	targets := []lockTarget{lockBuild, lockDeploy}
+	if all {
+		targets = append(targets, lockSource)
+	}
+	release, ok := lockTargets(core.LockExclusive, targets...)
+	if !ok {
+		return
+	}
+	defer release()
+
 	fmt.Println()
 	fmt.Println("开始卸载...")
 	fmt.Println()
@@ -1,12 +1,15 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
+	"io"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
 
 	"github.com/spf13/cobra"
 )
@@ -14,10 +17,73 @@ import (
 var updateCmd = &cobra.Command{
 	Use:   "update",
 	Short: "更新 OpenCode 源码",
+	Long: `Clone or update the OpenCode source. By default the dev branch (falling back to main) is pulled to its tip.
+Use --ref to pin a tag, branch or commit, or --supported to check out the pack's supportedCommit from config.json.
+
+When the source has a local `+core.TranslationBranch+` branch (created by apply --commit), it is re-created
+on top of the updated upstream and rules that no longer apply are reported.
+
+Upstreams are read from ~/.opencode-i18n/settings.json ("upstreams": [{"name", "url"}]). An existing source
+fetches from its current origin; only when that fails (and origin is one of the upstreams) are the others probed
+in order. A fresh clone probes them in order. --mirror switches origin to one and remembers the choice once the
+fetch succeeds.
+
+A fresh clone can be made shallow (--depth) and sparse (--sparse); it then only fetches dev, main and the
+remote's default branch (whichever exist), and --ref / --supported deepen the history up to that commit or tag.
+An interrupted clone is kept and resumed with its original options the next time update runs.`,
 	Run: func(cmd *cobra.Command, args []string) {
-		repoURL := "https://github.com/anomalyco/opencode.git"
-		// 使用 Gitee 镜像加速 (可选，可以通过 flag 控制)
-		// repoURL = "https://gitee.com/mirrors/opencode.git"
+		translationRebuilt = false
+		ref, _ := cmd.Flags().GetString("ref")
+		supported, _ := cmd.Flags().GetBool("supported")
+		if supported {
+			if ref != "" {
+				fmt.Println("错误: --ref 与 --supported 不能同时使用")
+				return
+			}
+			commit, err := loadSupportedCommit(os.Stdout)
+			if err != nil {
+				fmt.Printf("错误: %v\n", err)
+				return
+			}
+			ref = commit
+			fmt.Printf("固定到汉化包支持的版本: %s\n", ref)
+		}
+
+		mirror, _ := cmd.Flags().GetString("mirror")
+		listMirrors, _ := cmd.Flags().GetBool("list-mirrors")
+
+		settings, err := core.LoadSettings()
+		if err != nil {
+			fmt.Printf("警告: 读取设置失败，使用默认上游: %v\n", err)
+			settings = &core.Settings{}
+		}
+		if listMirrors {
+			printUpstreams(settings)
+			return
+		}
+
+		release, ok := lockTargets(core.LockExclusive, lockSource)
+		if !ok {
+			return
+		}
+		defer release()
+
+		// Ctrl-C 时终止 git，恢复暂存的更改和原来的检出位置；未完成的克隆保留以便继续
+		ctx, rollback, finish := interruptible()
+		defer finish()
+
+		upstreams := settings.Upstreams()
+		var selected *core.Upstream
+		if mirror != "" {
+			upstream, ok := core.FindUpstream(upstreams, mirror)
+			if !ok {
+				fmt.Printf("错误: 未知镜像 %q，可用: %s\n", mirror, upstreamNames(upstreams))
+				return
+			}
+			// 指定镜像时只使用该镜像，获取成功后记住选择
+			selected = &upstream
+			upstreams = []core.Upstream{upstream}
+		}
 
 		opencodeDir, err := core.GetOpencodeDir()
 		if err != nil {
@@ -25,18 +91,18 @@ var updateCmd = &cobra.Command{
 			return
 		}
 
-		if core.Exists(opencodeDir) {
+		if core.Exists(opencodeDir) && !core.CloneIncomplete(opencodeDir) {
 			fmt.Println("检测到现有源码，正在更新...")
-			
+
 			// 检查远程 URL（安全验证）
 			currentRemote, err := core.GetGitRemoteURL(opencodeDir)
 			if err != nil {
 				fmt.Printf("警告: 无法获取远程 URL: %v\n", err)
 			} else {
 				fmt.Printf("当前远程: %s\n", currentRemote)
-				
+
 				// 安全检查：确保不是汉化项目仓库
-				if strings.Contains(currentRemote, "OpenCodeChineseTranslation") {
+				if core.IsTranslationRepo(currentRemote) {
 					fmt.Println("❌ 错误: 目标目录似乎是汉化项目仓库，而非 OpenCode 源码目录！")
 					fmt.Println("   这可能导致覆盖你的工作。操作已中止。")
 					fmt.Printf("   目录: %s\n", opencodeDir)
@@ -45,13 +111,47 @@ var updateCmd = &cobra.Command{
 				}
 			}
 
+			// 拉取远程更新：指定镜像时切换到该镜像，否则先使用当前 origin
+			if err := fetchUpstream(ctx, opencodeDir, settings, selected, currentRemote); err != nil {
+				if ctx.Err() == nil {
+					fmt.Printf("错误: %v\n", err)
+				}
+				return
+			}
+			rememberMirror(settings, selected)
+
 			// 暂存更改（如果有）
-			if err := core.GitStash(opencodeDir); err != nil {
+			stashed, err := core.GitStashChanges(opencodeDir)
+			if err != nil {
 				fmt.Printf("警告: 暂存失败: %v\n", err)
 			}
+			if stashed {
+				rollback.Add("恢复暂存的更改", func() error {
+					_, err := core.ExecInDirQuiet(opencodeDir, "git", "stash", "pop")
+					return err
+				})
+			}
+			if snapshot, err := core.SnapshotRepo(opencodeDir); err == nil {
+				rollback.Add("恢复到 "+snapshot.String(), snapshot.Restore)
+			}
 
-			// 拉取远程更新
-			core.ExecLive("git", "-C", opencodeDir, "fetch", "origin")
+			if ref != "" {
+				if err := core.DeepenForCommit(ctx, opencodeDir, "", ref); err != nil {
+					fmt.Printf("错误: 获取 %s 失败: %v\n", ref, err)
+					return
+				}
+				if err := core.GitCheckoutRef(opencodeDir, ref); err != nil {
+					fmt.Printf("错误: 切换到 %s 失败: %v\n", ref, err)
+					return
+				}
+				printSupportedStatus(opencodeDir)
+				rebuildTranslationBranch(ctx, opencodeDir)
+				if ctx.Err() != nil {
+					return
+				}
+				fmt.Println("源码更新完成！")
+				return
+			}
 
 			// 尝试切换到 dev 分支
 			// 1. 如果本地已有 dev，直接 checkout
@@ -72,9 +172,18 @@ var updateCmd = &cobra.Command{
 					core.GitPull(opencodeDir)
 				}
 			}
+			if ctx.Err() != nil {
+				return
+			}
+			rebuildTranslationBranch(ctx, opencodeDir)
+			if ctx.Err() != nil {
+				return
+			}
 			
 		} else {
-			fmt.Println("正在克隆 OpenCode 源码...")
+			if !core.CloneIncomplete(opencodeDir) {
+				fmt.Println("正在克隆 OpenCode 源码...")
+			}
 			fmt.Printf("目标目录: %s\n", opencodeDir)
 			
 			// 确保父目录存在
@@ -84,18 +193,196 @@ var updateCmd = &cobra.Command{
 				return
 			}
 			
-			if err := core.GitClone(repoURL, opencodeDir); err != nil {
-				fmt.Printf("错误: 克隆失败: %v\n", err)
-				// 克隆失败，清理目录
-				os.RemoveAll(opencodeDir)
+			upstream, err := core.SelectUpstream(upstreams, nil)
+			if err != nil {
+				fmt.Printf("错误: %v\n", err)
+				return
+			}
+			depth, _ := cmd.Flags().GetInt("depth")
+			sparse, _ := cmd.Flags().GetBool("sparse")
+			fmt.Printf("正在克隆仓库 %s...\n", upstream.URL)
+			err = core.CloneRepo(ctx, opencodeDir, core.CloneOptions{
+				URL:         upstream.URL,
+				Depth:       depth,
+				Sparse:      sparse,
+				SparsePaths: settings.SparsePaths,
+			})
+			if err != nil {
+				// 保留已下载的内容，再次运行 update 时继续
+				if ctx.Err() != nil {
+					fmt.Printf("\n克隆已中断，已下载的内容保留在 %s\n", opencodeDir)
+				} else {
+					fmt.Printf("错误: 克隆失败: %v\n", err)
+				}
+				fmt.Println("提示: 重新运行 update 将从中断处继续")
 				return
 			}
+			rememberMirror(settings, selected)
+
+			if ref != "" {
+				if err := core.DeepenForCommit(ctx, opencodeDir, "", ref); err != nil {
+					fmt.Printf("错误: 获取 %s 失败: %v\n", ref, err)
+					return
+				}
+				if err := core.GitCheckoutRef(opencodeDir, ref); err != nil {
+					fmt.Printf("错误: 切换到 %s 失败: %v\n", ref, err)
+					return
+				}
+			}
 		}
 
+		printSupportedStatus(opencodeDir)
 		fmt.Println("源码更新完成！")
 	},
 }
 
 func init() {
+	updateCmd.Flags().String("ref", "", "Check out this tag, branch or commit instead of the tip of dev")
+	updateCmd.Flags().Bool("supported", false, "Check out the supportedCommit declared in the pack's config.json")
+	updateCmd.Flags().String("mirror", "", "Use this upstream mirror (name or URL from settings.json upstreams) and remember the choice")
+	updateCmd.Flags().Bool("list-mirrors", false, "List configured upstreams and probe their connectivity")
+	updateCmd.Flags().Int("depth", 0, "Shallow clone with this many commits of history (deepened automatically for --ref / --supported)")
+	updateCmd.Flags().Bool("sparse", false, "Only check out the packages needed to build (sparsePaths in settings.json) and fetch file contents on demand")
 	rootCmd.AddCommand(updateCmd)
 }
+
+// translationRebuilt 本次 update 是否成功重建了汉化分支，全流程据此决定是否跳过 apply
+var translationRebuilt bool
+
+// rebuildSelection 重建汉化分支时使用的模块与风味选择，由全流程按 --only / --exclude / --flavor 设置
+// 为 nil 时使用全部模块和已保存的风味
+var rebuildSelection *i18n.Selection
+
+// rebuildTranslationBranch 源码中存在汉化分支时，在更新后的上游提交之上重建它
+func rebuildTranslationBranch(ctx context.Context, opencodeDir string) {
+	if !core.GitBranchExists(opencodeDir, i18n.TranslationBranch) {
+		return
+	}
+	if ctx == nil {
+		ctx = context.Background()
+	}
+
+	fmt.Printf("\n正在重建汉化分支 %s...\n", i18n.TranslationBranch)
+	engine, err := i18n.Default(os.Stdout)
+	if err != nil {
+		fmt.Printf("✗ 初始化失败: %v\n", err)
+		return
+	}
+	sel := i18n.Selection{Flavor: savedFlavor()}
+	if rebuildSelection != nil {
+		sel = *rebuildSelection
+	}
+
+	report, err := engine.RebuildBranch(ctx, sel)
+	if err != nil {
+		fmt.Printf("✗ 重建汉化分支失败: %v\n", err)
+		return
+	}
+	translationRebuilt = true
+	printBranchReport(report)
+}
+
+// savedFlavor 返回 settings.json 中保存的汉化风味，读取失败时返回空（不使用风味）
+func savedFlavor() string {
+	settings, err := core.LoadSettings()
+	if err != nil {
+		return ""
+	}
+	return settings.Flavor
+}
+
+// fetchUpstream 从上游拉取源码仓库的更新
+// 指定镜像时将 origin 切换到该镜像；否则使用当前 origin，只有获取失败且 origin 属于上游列表时才依次探测其他上游
+func fetchUpstream(ctx context.Context, dir string, settings *core.Settings, mirror *core.Upstream, currentRemote string) error {
+	if mirror != nil {
+		return core.SetOriginURL(ctx, dir, mirror.URL)
+	}
+
+	err := core.FetchOrigin(ctx, dir)
+	if err == nil || ctx.Err() != nil {
+		return err
+	}
+	upstreams := settings.Upstreams()
+	if _, known := core.FindUpstream(upstreams, currentRemote); !known && currentRemote != "" {
+		return fmt.Errorf("从 origin 获取失败 (origin 不在上游列表中，未自动切换): %w", err)
+	}
+
+	fmt.Println("从当前 origin 获取失败，尝试其他上游...")
+	upstream, err := core.SelectUpstream(core.ExcludeUpstream(upstreams, currentRemote), nil)
+	if err != nil {
+		return err
+	}
+	if err := core.SetOriginURL(ctx, dir, upstream.URL); err != nil {
+		return fmt.Errorf("切换上游失败: %w", err)
+	}
+	return nil
+}
+
+// rememberMirror 获取成功后保存 --mirror 指定的镜像
+func rememberMirror(settings *core.Settings, mirror *core.Upstream) {
+	if mirror == nil || settings.Mirror == mirror.Name {
+		return
+	}
+	settings.Mirror = mirror.Name
+	if err := core.SaveSettings(settings); err != nil {
+		fmt.Printf("警告: 保存镜像选择失败: %v\n", err)
+	}
+}
+
+// upstreamNames 返回上游名称列表
+func upstreamNames(upstreams []core.Upstream) string {
+	names := make([]string, len(upstreams))
+	for n, upstream := range upstreams {
+		names[n] = upstream.Name
+	}
+	return strings.Join(names, ", ")
+}
+
+// printUpstreams 列出上游并探测连通性
+func printUpstreams(settings *core.Settings) {
+	if path, err := core.GetSettingsPath(); err == nil {
+		fmt.Printf("上游列表 (按尝试顺序，可在 %s 的 upstreams 中配置):\n", path)
+	}
+	for _, upstream := range settings.Upstreams() {
+		mark := " "
+		if upstream.Name == settings.Mirror {
+			mark = "*"
+		}
+		status := "✓ 可访问"
+		if err := core.ProbeUpstream(upstream.URL); err != nil {
+			status = "✗ " + core.Truncate(err.Error(), 40)
+		}
+		fmt.Printf("  %s %-10s %-50s %s\n", mark, upstream.Name, upstream.URL, status)
+	}
+}
+
+// loadSupportedCommit 读取汉化包 config.json 的 supportedCommit，logger 接收汉化包来源提示
+// 命令已加载过汉化包（提示已输出）时传入 io.Discard
+func loadSupportedCommit(logger io.Writer) (string, error) {
+	engine, err := i18n.Default(logger)
+	if err != nil {
+		return "", fmt.Errorf("初始化失败: %w", err)
+	}
+	manifest, err := engine.Manifest()
+	if err != nil {
+		return "", fmt.Errorf("读取 %s 失败: %w", core.ManifestFileName, err)
+	}
+	if manifest.SupportedCommit == "" {
+		return "", fmt.Errorf("%s 未声明 supportedCommit", core.ManifestFileName)
+	}
+	return manifest.SupportedCommit, nil
+}
+
+// printSupportedStatus 输出源码相对汉化包支持版本的位置
+func printSupportedStatus(opencodeDir string) {
+	commit, err := loadSupportedCommit(io.Discard)
+	if err != nil {
+		return
+	}
+	status := core.GetSupportedStatus(opencodeDir, commit)
+	if status.OnSupported() {
+		fmt.Printf("✓ %s\n", status.Summary())
+	} else {
+		fmt.Printf("⚠️ %s (可运行 update --supported 固定到该版本)\n", status.Summary())
+	}
+}
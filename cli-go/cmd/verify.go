@@ -1,12 +1,14 @@
 package cmd
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
 	"strings"
 
 	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
 
 	"github.com/spf13/cobra"
 )
@@ -18,28 +20,41 @@ var verifyCmd = &cobra.Command{
 	Run: func(cmd *cobra.Command, args []string) {
 		detailed, _ := cmd.Flags().GetBool("detailed")
 		dryRun, _ := cmd.Flags().GetBool("dry-run")
-		runVerify(detailed, dryRun)
+		widthRatio, _ := cmd.Flags().GetFloat64("width-ratio")
+		runVerifyWithRatio(detailed, dryRun, widthRatio)
 	},
 }
 
 func init() {
 	verifyCmd.Flags().BoolP("detailed", "d", false, "Show detailed information")
 	verifyCmd.Flags().Bool("dry-run", false, "Simulate the apply process")
+	verifyCmd.Flags().Float64("width-ratio", core.DefaultWidthRatio, "Max display width ratio of translation to source (0 to disable)")
 	rootCmd.AddCommand(verifyCmd)
 }
 
 func runVerify(detailed, dryRun bool) {
+	runVerifyWithRatio(detailed, dryRun, core.DefaultWidthRatio)
+}
+
+// runVerifyWithRatio 执行验证，widthRatio 为显示宽度比例上限（0 表示跳过）
+func runVerifyWithRatio(detailed, dryRun bool, widthRatio float64) {
 	fmt.Println("\n▶ 验证汉化配置")
 
-	// 1. 初始化 I18n
-	i18n, err := core.NewI18n()
+	release, ok := lockTargets(core.LockShared, lockSource)
+	if !ok {
+		return
+	}
+	defer release()
+
+	// 1. 初始化汉化引擎
+	engine, err := i18n.Default(os.Stdout)
 	if err != nil {
 		fmt.Printf("✗ 初始化失败: %v\n", err)
 		return
 	}
 
 	// 2. 加载配置（自动处理内嵌资源）
-	configs, err := i18n.LoadConfig()
+	configs, err := engine.Rules(context.Background())
 	if err != nil {
 		fmt.Printf("✗ 加载配置失败: %v\n", err)
 		return
@@ -52,7 +67,7 @@ func runVerify(detailed, dryRun bool) {
 	}
 
 	// 3. 验证配置完整性
-	fmt.Println("\n[1/4] 验证配置完整性...")
+	fmt.Println("\n[1/6] 验证配置完整性...")
 
 	totalConfigs := len(configs)
 	totalReplacements := 0
@@ -67,6 +82,48 @@ func runVerify(detailed, dryRun bool) {
 	fmt.Printf("  ✓ 配置文件: %d 个\n", totalConfigs)
 	fmt.Printf("  ✓ 翻译条目: %d 条\n", totalReplacements)
 
+	// 清单与磁盘一致性（config.json modules）
+	manifestIssues, err := engine.CheckManifest()
+	if err != nil {
+		fmt.Printf("  ⚠️ 无法检查清单: %v\n", err)
+	} else if len(manifestIssues) > 0 {
+		fmt.Printf("  ✗ 清单 (config.json) 发现 %d 处问题:\n", len(manifestIssues))
+		for _, issue := range manifestIssues {
+			fmt.Printf("     - %s\n", issue)
+		}
+		fmt.Println("     提示: 运行 opencode-cli pack fmt 可按磁盘文件重写 modules")
+	} else {
+		fmt.Println("  ✓ 清单 (config.json) 与磁盘文件一致")
+	}
+
+	// 规则目标文件必须位于 config.json targets 声明的范围内
+	var outside []string
+	for _, config := range configs {
+		if config.File != "" && !engine.InTargets(config) {
+			outside = append(outside, config.FileName)
+		}
+	}
+	if len(outside) > 0 {
+		fmt.Printf("  ✗ %d 个配置的目标文件不在 targets 范围内 (apply 将跳过):\n", len(outside))
+		for _, name := range outside {
+			fmt.Printf("     - %s\n", name)
+		}
+	}
+
+	// glob 配置按源码展开，后续的模拟运行和覆盖率按具体文件统计
+	sourceConfigs, globs, err := engine.ExpandGlobs(configs)
+	if err != nil {
+		fmt.Printf("  ⚠️ 展开 glob 配置失败: %v\n", err)
+		sourceConfigs = configs
+	}
+	for _, glob := range globs {
+		if glob.Warning != "" {
+			fmt.Printf("  ⚠️ %s: %s\n", glob.Config.FileName, glob.Warning)
+		} else if detailed {
+			fmt.Printf("  ✓ %s: %s 匹配 %d 个文件\n", glob.Config.FileName, glob.Config.File, len(glob.Files))
+		}
+	}
+
 	if detailed {
 		fmt.Println("\n  分类统计:")
 		for category, count := range categoryStats {
@@ -74,44 +131,94 @@ func runVerify(detailed, dryRun bool) {
 		}
 	}
 
-	// 4. 变量保护检查
-	fmt.Println("\n[2/4] 检查变量保护...")
+	// 4. 占位符保护检查
+	fmt.Println("\n[2/6] 检查占位符保护...")
 
-	variableIssues := 0
+	placeholderIssues := 0
 	for _, config := range configs {
 		for from, to := range config.Replacements {
-			// 检查 {xxx} 格式的变量
-			origVars := extractVariables(from)
-			transVars := extractVariables(to)
-
-			if !sameVariables(origVars, transVars) {
-				variableIssues++
-				if detailed {
-					fmt.Printf("  ⚠️ %s/%s\n", config.Category, config.FileName)
-					fmt.Printf("     原文: %s\n", core.Truncate(from, 50))
-					fmt.Printf("     译文: %s\n", core.Truncate(to, 50))
-					fmt.Printf("     缺失变量: %v\n", diffVariables(origVars, transVars))
+			// 模板插值 ${...}、JSX 表达式 {...} 与 printf 占位符必须一一对应
+			diff := core.ComparePlaceholders(from, to)
+			if diff.OK() {
+				continue
+			}
+			placeholderIssues++
+			if detailed {
+				fmt.Printf("  ✗ %s/%s\n", config.Category, config.FileName)
+				fmt.Printf("     原文: %s\n", core.Truncate(from, 50))
+				fmt.Printf("     译文: %s\n", core.Truncate(to, 50))
+				for _, problem := range diff.Problems() {
+					fmt.Printf("     %s\n", problem)
 				}
 			}
 		}
 	}
 
-	if variableIssues > 0 {
-		fmt.Printf("  ⚠️ 发现 %d 处变量问题\n", variableIssues)
+	if placeholderIssues > 0 {
+		fmt.Printf("  ✗ 发现 %d 处占位符错误\n", placeholderIssues)
 	} else {
-		fmt.Println("  ✓ 变量保护验证通过")
+		fmt.Println("  ✓ 占位符保护验证通过")
 	}
 
-	// 5. 模拟运行检查（如果启用）
+	// 5. 显示宽度检查
+	fmt.Println("\n[3/6] 检查显示宽度...")
+
+	if widthRatio > 0 {
+		widthIssues := core.CheckDisplayWidth(configs, widthRatio)
+		if len(widthIssues) > 0 {
+			fmt.Printf("  ⚠️ 发现 %d 条译文显示宽度超限\n", len(widthIssues))
+			if detailed {
+				for _, issue := range widthIssues {
+					fmt.Printf("  ⚠️ %s/%s (%d → %d 格, %.2f > %.2f)\n",
+						issue.Config.Category, issue.Config.FileName,
+						issue.FromWidth, issue.ToWidth, issue.Ratio(), issue.Limit)
+					fmt.Printf("     原文: %s\n", core.Truncate(issue.From, 50))
+					fmt.Printf("     译文: %s\n", core.Truncate(issue.To, 50))
+				}
+			}
+		} else {
+			fmt.Printf("  ✓ 显示宽度验证通过 (上限 %.2f)\n", widthRatio)
+		}
+	} else {
+		fmt.Println("  跳过显示宽度检查（--width-ratio 为 0）")
+	}
+
+	// 6. 规则冲突检查
+	fmt.Println("\n[4/6] 检查规则冲突...")
+
+	conflicts := engine.Conflicts(configs)
+	if len(conflicts) > 0 {
+		conflictStats := make(map[core.ConflictKind]int)
+		for _, c := range conflicts {
+			conflictStats[c.Kind]++
+		}
+		fmt.Printf("  ⚠️ 发现 %d 处规则冲突 (重复 %d, 遮蔽 %d, 链式 %d)\n", len(conflicts),
+			conflictStats[core.ConflictDuplicate], conflictStats[core.ConflictShadow], conflictStats[core.ConflictChain])
+		if detailed {
+			for _, c := range conflicts {
+				relTarget, err := filepath.Rel(opencodeDir, c.Target)
+				if err != nil {
+					relTarget = c.Target
+				}
+				fmt.Printf("  ⚠️ [%s] %s\n", relTarget, c.Describe())
+				fmt.Printf("     A (%s): %s → %s\n", c.A.Source(), core.Truncate(c.A.From, 40), core.Truncate(c.A.To, 40))
+				fmt.Printf("     B (%s): %s → %s\n", c.B.Source(), core.Truncate(c.B.From, 40), core.Truncate(c.B.To, 40))
+			}
+		}
+	} else {
+		fmt.Println("  ✓ 未发现规则冲突")
+	}
+
+	// 7. 模拟运行检查（如果启用）
 	if dryRun {
-		fmt.Println("\n[3/4] 模拟运行检查...")
+		fmt.Println("\n[5/6] 模拟运行检查...")
 
 		matchCount := 0
 		missCount := 0
 
-		for _, config := range configs {
+		for _, config := range sourceConfigs {
 			// 使用与 apply 相同的路径处理逻辑
-			targetFile := i18n.GetTargetFilePath(config)
+			targetFile := engine.TargetPath(config)
 			if targetFile == "" || !core.Exists(targetFile) {
 				missCount += len(config.Replacements)
 				continue
@@ -139,37 +246,34 @@ func runVerify(detailed, dryRun bool) {
 			fmt.Printf("  ⚠️ %d 条翻译在源码中找不到匹配\n", missCount)
 		}
 	} else {
-		fmt.Println("\n[3/4] 跳过模拟运行（使用 --dry-run 启用）")
+		fmt.Println("\n[5/6] 跳过模拟运行（使用 --dry-run 启用）")
 	}
 
-	// 6. 检查覆盖率
-	fmt.Println("\n[4/4] 检查汉化覆盖率...")
+	// 8. 检查覆盖率
+	fmt.Println("\n[6/6] 检查汉化覆盖率...")
 
-	sourceDir := filepath.Join(opencodeDir, "packages", "opencode", "src")
-	if core.Exists(sourceDir) {
-		var uiFiles []string  // 包含 UI 字符串的文件
+	// 扫描 config.json targets 声明的全部范围（未声明时为 packages/opencode/src 下的 .tsx/.jsx）
+	targetFiles, err := engine.TargetFiles()
+	if err != nil {
+		fmt.Printf("  ⚠️ 扫描源码失败: %v\n", err)
+	} else if len(targetFiles) > 0 {
+		var uiFiles []string       // 包含 UI 字符串的文件
 		var codeOnlyFiles []string // 纯代码文件
 
-		filepath.Walk(sourceDir, func(path string, info os.FileInfo, err error) error {
-			if err == nil && !info.IsDir() {
-				ext := filepath.Ext(path)
-				if ext == ".tsx" || ext == ".jsx" {
-					// 检查文件是否包含 UI 字符串
-					if hasUIStrings(path) {
-						uiFiles = append(uiFiles, path)
-					} else {
-						codeOnlyFiles = append(codeOnlyFiles, path)
-					}
-				}
+		for _, path := range targetFiles {
+			// 检查文件是否包含 UI 字符串
+			if hasUIStrings(path) {
+				uiFiles = append(uiFiles, path)
+			} else {
+				codeOnlyFiles = append(codeOnlyFiles, path)
 			}
-			return nil
-		})
+		}
 
 		// 统计已配置的文件
 		configuredFiles := make(map[string]bool)
-		for _, config := range configs {
-			if config.File != "" {
-				configuredFiles[config.File] = true
+		for _, config := range sourceConfigs {
+			if target := engine.TargetPath(config); target != "" {
+				configuredFiles[target] = true
 			}
 		}
 
@@ -183,10 +287,15 @@ func runVerify(detailed, dryRun bool) {
 			coverage = 100 // 可能有些配置对应的文件已被删除，限制最大 100%
 		}
 
+		var targetNames []string
+		for _, target := range engine.Targets() {
+			targetNames = append(targetNames, target.Name)
+		}
+		fmt.Printf("  扫描范围: %s\n", strings.Join(targetNames, ", "))
 		fmt.Printf("  源码文件: %d 个 (UI: %d, 纯代码: %d)\n", len(uiFiles)+len(codeOnlyFiles), len(uiFiles), len(codeOnlyFiles))
 		fmt.Printf("  已配置: %d 个\n", len(configuredFiles))
 		fmt.Printf("  覆盖率: %.1f%% (基于包含 UI 字符串的文件)\n", coverage)
-		
+
 		if detailed && len(codeOnlyFiles) > 0 {
 			fmt.Printf("\n  📁 纯代码文件 (%d 个，无需翻译):\n", len(codeOnlyFiles))
 			for i, f := range codeOnlyFiles {
@@ -194,8 +303,8 @@ func runVerify(detailed, dryRun bool) {
 					fmt.Printf("    ... 还有 %d 个\n", len(codeOnlyFiles)-5)
 					break
 				}
-				relPath, _ := filepath.Rel(sourceDir, f)
-				fmt.Printf("    - %s\n", relPath)
+				relPath, _ := filepath.Rel(opencodeDir, f)
+				fmt.Printf("    - %s\n", filepath.ToSlash(relPath))
 			}
 		}
 	} else {
@@ -205,64 +314,6 @@ func runVerify(detailed, dryRun bool) {
 	fmt.Println("\n✓ 验证完成")
 }
 
-// extractVariables 提取文本中的简单变量 {xxx}
-// 只提取由字母、数字、下划线组成的变量，忽略复杂表达式
-func extractVariables(s string) []string {
-	var vars []string
-	inVar := false
-	var current strings.Builder
-
-	for _, c := range s {
-		if c == '{' {
-			inVar = true
-			current.Reset()
-		} else if c == '}' && inVar {
-			val := current.String()
-			// 过滤复杂表达式：如果包含空格、点号、引号等，视为代码逻辑而非简单变量
-			if !strings.ContainsAny(val, " .\"'()[]?") {
-				vars = append(vars, val)
-			}
-			inVar = false
-		} else if inVar {
-			current.WriteRune(c)
-		}
-	}
-	return vars
-}
-
-// sameVariables 检查两个变量列表是否相同
-func sameVariables(a, b []string) bool {
-	if len(a) != len(b) {
-		return false
-	}
-	aMap := make(map[string]int)
-	for _, v := range a {
-		aMap[v]++
-	}
-	for _, v := range b {
-		if aMap[v] <= 0 {
-			return false
-		}
-		aMap[v]--
-	}
-	return true
-}
-
-// diffVariables 返回 a 中有但 b 中没有的变量
-func diffVariables(a, b []string) []string {
-	bMap := make(map[string]bool)
-	for _, v := range b {
-		bMap[v] = true
-	}
-	var diff []string
-	for _, v := range a {
-		if !bMap[v] {
-			diff = append(diff, v)
-		}
-	}
-	return diff
-}
-
 // hasUIStrings 检查文件是否包含需要翻译的硬编码 UI 字符串
 // 更精确的判断：检查硬编码的英文字符串属性，而非代码结构
 func hasUIStrings(filePath string) bool {
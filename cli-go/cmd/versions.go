@@ -0,0 +1,261 @@
+package cmd
+
+import (
+	"bufio"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+
+	"opencode-cli/internal/core"
+	"opencode-cli/pkg/i18n"
+
+	"github.com/mattn/go-runewidth"
+	"github.com/spf13/cobra"
+)
+
+var versionsCmd = &cobra.Command{
+	Use:   "versions [tag]",
+	Short: "浏览上游发布版本并检出指定版本",
+	Long: `List upstream release tags with their date, the packages/opencode version and the pack
+versions that claim support for them. With --match each listed version is checked in a
+scratch worktree and the share of rules that still match is reported.
+Pass a tag (or its packages/opencode version) to check it out and report how well the
+translations match it.`,
+	Args: cobra.MaximumNArgs(1),
+	Run: func(cmd *cobra.Command, args []string) {
+		limit, _ := cmd.Flags().GetInt("limit")
+		match, _ := cmd.Flags().GetBool("match")
+		if len(args) == 1 {
+			if !checkoutVersion(cmd.Context(), args[0]) {
+				os.Exit(1)
+			}
+			return
+		}
+		if _, ok := listVersions(cmd.Context(), limit, match); !ok {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	versionsCmd.Flags().IntP("limit", "n", 15, "Number of most recent tags to list (0 for all)")
+	versionsCmd.Flags().Bool("match", false, "Report the rule match percentage for every listed version")
+	rootCmd.AddCommand(versionsCmd)
+}
+
+// loadReleases 读取上游标签并标记汉化包声明支持的版本
+func loadReleases(limit int) (string, []core.UpstreamRelease, bool) {
+	opencodeDir, err := core.GetOpencodeDir()
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return "", nil, false
+	}
+	releases, err := core.ListUpstreamReleases(opencodeDir, limit)
+	if err != nil {
+		fmt.Printf("✗ 读取标签失败: %v (请先运行 update)\n", err)
+		return "", nil, false
+	}
+
+	if engine, err := i18n.Default(io.Discard); err == nil {
+		if claims, err := engine.PackClaims(); err == nil {
+			core.MarkSupportedReleases(releases, claims)
+		}
+	}
+	return opencodeDir, releases, true
+}
+
+// listVersions 列出上游版本，match 为 true 时在临时工作区中逐个统计匹配率
+func listVersions(ctx context.Context, limit int, match bool) ([]core.UpstreamRelease, bool) {
+	release, ok := lockTargets(core.LockShared, lockSource)
+	if !ok {
+		return nil, false
+	}
+	defer release()
+
+	opencodeDir, releases, ok := loadReleases(limit)
+	if !ok {
+		return nil, false
+	}
+	if len(releases) == 0 {
+		fmt.Println("源码仓库中没有标签 (运行 update 获取上游标签)")
+		return nil, true
+	}
+
+	matches := make([]*core.VersionMatch, len(releases))
+	if match {
+		if ctx == nil {
+			ctx = context.Background()
+		}
+		for n, release := range releases {
+			matches[n] = scanVersionMatch(ctx, opencodeDir, release.Tag)
+		}
+	}
+
+	head, _ := core.GitResolveCommit(opencodeDir, "HEAD")
+	fmt.Println("")
+	fmt.Printf("  %-3s %s %s %s %s %s\n", "#", runewidth.FillRight("标签", 16), runewidth.FillRight("日期", 10),
+		runewidth.FillRight("版本", 10), runewidth.FillRight("提交", 8), "汉化包")
+	for n, release := range releases {
+		mark := " "
+		if release.Commit == head {
+			mark = "*"
+		}
+		packs := "-"
+		if len(release.Packs) > 0 {
+			packs = "v" + strings.Join(release.Packs, ", v")
+		}
+		line := fmt.Sprintf("%s %-3d %-16s %-10s %-10s %-8s %s", mark, n+1,
+			core.Truncate(release.Tag, 16), release.Date, orDash(release.Version), core.ShortHash(release.Commit), packs)
+		if matches[n] != nil {
+			line += fmt.Sprintf("  匹配 %.1f%%", matches[n].Percent())
+		}
+		fmt.Println(line)
+	}
+	fmt.Println("\n  * 当前检出的版本")
+	return releases, true
+}
+
+// scanVersionMatch 在临时工作区中检出 ref 并统计匹配率，失败时返回 nil
+func scanVersionMatch(ctx context.Context, opencodeDir, ref string) *core.VersionMatch {
+	worktreesDir, err := core.GetWorktreesDir()
+	if err != nil {
+		fmt.Printf("  ⚠ %s: %v\n", ref, err)
+		return nil
+	}
+	release, ok := lockDirs(core.LockExclusive, filepath.Join(worktreesDir, core.ScanWorktreeName))
+	if !ok {
+		return nil
+	}
+	defer release()
+
+	wt, err := core.ScanWorktree(opencodeDir, ref)
+	if err != nil {
+		fmt.Printf("  ⚠ %s: %v\n", ref, err)
+		return nil
+	}
+	match, err := versionMatchAt(ctx, wt.Path)
+	if err != nil {
+		fmt.Printf("  ⚠ %s: %v\n", ref, err)
+		return nil
+	}
+	return match
+}
+
+// versionMatchAt 统计汉化规则在 dir 中的匹配率（使用已保存的风味，不修改文件）
+func versionMatchAt(ctx context.Context, dir string) (*core.VersionMatch, error) {
+	engine, err := i18n.Default(io.Discard)
+	if err != nil {
+		return nil, err
+	}
+	engine = engine.WithSourceDir(dir)
+	configs, err := engine.Rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if settings, err := core.LoadSettings(); err == nil {
+		if configs, err = engine.ApplyFlavor(configs, settings.Flavor); err != nil {
+			return nil, err
+		}
+	}
+	return engine.MatchRate(ctx, configs)
+}
+
+// checkoutVersion 检出指定标签（或 packages/opencode 版本号）并报告汉化匹配情况
+func checkoutVersion(ctx context.Context, name string) bool {
+	release, ok := lockTargets(core.LockExclusive, lockSource)
+	if !ok {
+		return false
+	}
+	defer release()
+
+	opencodeDir, releases, ok := loadReleases(0)
+	if !ok {
+		return false
+	}
+
+	tag := name
+	for _, release := range releases {
+		if release.Tag == name || (release.Version != "" && release.Version == strings.TrimPrefix(name, "v")) {
+			tag = release.Tag
+			break
+		}
+	}
+
+	// 未提交的修改（通常是已应用的汉化）与目标版本无关，不替用户暂存
+	if out, err := core.ExecInDirQuiet(opencodeDir, "git", "status", "--porcelain"); err != nil {
+		fmt.Printf("✗ 无法读取工作区状态: %v\n", err)
+		return false
+	} else if out != "" {
+		fmt.Println("✗ 源码工作区有未提交的修改 (可能是已应用的汉化)，未切换版本")
+		fmt.Println("  请先在菜单中 [恢复源码]，或用 apply --commit 将汉化提交到分支 " + core.TranslationBranch)
+		return false
+	}
+
+	branch := core.GitCurrentBranch(opencodeDir)
+	if err := core.GitCheckoutRef(opencodeDir, tag); err != nil {
+		fmt.Printf("✗ 切换到 %s 失败: %v\n", tag, err)
+		return false
+	}
+	fmt.Printf("✓ 已检出 %s\n", tag)
+	if branch == core.TranslationBranch {
+		fmt.Printf("  已离开汉化分支 %s (分支保持不变)：运行 apply --commit 在此版本上重建，或 git checkout %s 返回\n",
+			core.TranslationBranch, core.TranslationBranch)
+	}
+	printSupportedStatus(opencodeDir)
+
+	if ctx == nil {
+		ctx = context.Background()
+	}
+	match, err := versionMatchAt(ctx, opencodeDir)
+	if err != nil {
+		fmt.Printf("✗ 统计匹配率失败: %v\n", err)
+		return false
+	}
+	printVersionMatch(match)
+	return true
+}
+
+// printVersionMatch 输出匹配率并标记是否完全匹配
+func printVersionMatch(match *core.VersionMatch) {
+	missed := match.Rules - match.Matched
+	if missed == 0 {
+		fmt.Printf("✓ 汉化完全匹配: %d 条规则 (100%%)\n", match.Rules)
+		return
+	}
+	fmt.Printf("⚠ 汉化部分匹配: %d/%d 条规则 (%.1f%%)，%d 条失效", match.Matched, match.Rules, match.Percent(), missed)
+	if match.Files > 0 {
+		fmt.Printf("，%d 个配置文件被跳过", match.Files)
+	}
+	fmt.Println("\n  运行 verify --detailed 查看详情")
+}
+
+// selectVersion 交互式选择并检出版本（菜单使用）
+func selectVersion(ctx context.Context) {
+	releases, ok := listVersions(ctx, 15, false)
+	if !ok || len(releases) == 0 {
+		return
+	}
+
+	fmt.Print("\n输入序号或标签检出 (回车取消): ")
+	input, _ := bufio.NewReader(os.Stdin).ReadString('\n')
+	input = strings.TrimSpace(input)
+	if input == "" {
+		return
+	}
+	if n, err := strconv.Atoi(input); err == nil && n >= 1 && n <= len(releases) {
+		input = releases[n-1].Tag
+	}
+	checkoutVersion(ctx, input)
+}
+
+// orDash 空字符串显示为 -
+func orDash(s string) string {
+	if s == "" {
+		return "-"
+	}
+	return s
+}
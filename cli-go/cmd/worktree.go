@@ -0,0 +1,149 @@
+package cmd
+
+import (
+	"fmt"
+	"os"
+	"time"
+
+	"opencode-cli/internal/core"
+
+	"github.com/spf13/cobra"
+)
+
+var worktreeCmd = &cobra.Command{
+	Use:   "worktree",
+	Short: "管理独立构建工作区",
+	Long: `Manage the isolated build worktrees created by "build --worktree".
+Each worktree lives in ~/.opencode-i18n/worktrees/<ref>-<locale> and shares the
+object store of the main checkout.`,
+}
+
+var worktreeListCmd = &cobra.Command{
+	Use:   "list",
+	Short: "列出构建工作区",
+	Run: func(cmd *cobra.Command, args []string) {
+		if !listWorktrees() {
+			os.Exit(1)
+		}
+	},
+}
+
+var worktreeCleanCmd = &cobra.Command{
+	Use:   "clean [name...]",
+	Short: "删除构建工作区或其 node_modules",
+	Long: `Remove the named build worktrees, or all of them with --all.
+With --node-modules-only the sources are kept and only the installed dependencies are deleted.`,
+	Run: func(cmd *cobra.Command, args []string) {
+		all, _ := cmd.Flags().GetBool("all")
+		nodeModulesOnly, _ := cmd.Flags().GetBool("node-modules-only")
+		if len(args) == 0 && !all {
+			fmt.Println("错误: 请指定工作区名称，或使用 --all")
+			os.Exit(1)
+		}
+		if !cleanWorktrees(args, nodeModulesOnly) {
+			os.Exit(1)
+		}
+	},
+}
+
+func init() {
+	worktreeCleanCmd.Flags().Bool("all", false, "Clean every build worktree")
+	worktreeCleanCmd.Flags().Bool("node-modules-only", false, "Only delete node_modules, keep the sources")
+	worktreeCmd.AddCommand(worktreeListCmd, worktreeCleanCmd)
+	rootCmd.AddCommand(worktreeCmd)
+}
+
+// loadWorktrees 读取主源码仓库的构建工作区
+func loadWorktrees() (string, []core.Worktree, bool) {
+	opencodeDir, err := core.GetOpencodeDir()
+	if err != nil {
+		fmt.Printf("✗ %v\n", err)
+		return "", nil, false
+	}
+	list, err := core.ListWorktrees(opencodeDir)
+	if err != nil {
+		fmt.Printf("✗ 读取工作区失败: %v\n", err)
+		return "", nil, false
+	}
+	return opencodeDir, list, true
+}
+
+// listWorktrees 列出构建工作区
+func listWorktrees() bool {
+	_, list, ok := loadWorktrees()
+	if !ok {
+		return false
+	}
+	if len(list) == 0 {
+		fmt.Println("没有构建工作区 (使用 build --worktree 创建)")
+		return true
+	}
+
+	fmt.Println("")
+	for _, wt := range list {
+		deps := ""
+		if len(wt.NodeModules) > 0 {
+			deps = "  [node_modules]"
+		}
+		fmt.Printf("  %-28s %s  %s%s\n", wt.Name, core.ShortHash(wt.Commit), wt.ModTime.Format(time.DateTime), deps)
+	}
+	if root, err := core.GetWorktreesDir(); err == nil {
+		fmt.Printf("\n  目录: %s\n", root)
+	}
+	return true
+}
+
+// cleanWorktrees 删除指定（names 为空时为全部）工作区或其 node_modules
+func cleanWorktrees(names []string, nodeModulesOnly bool) bool {
+	release, ok := lockTargets(core.LockShared, lockSource)
+	if !ok {
+		return false
+	}
+	defer release()
+
+	opencodeDir, list, ok := loadWorktrees()
+	if !ok {
+		return false
+	}
+
+	selected := list
+	if len(names) > 0 {
+		byName := make(map[string]core.Worktree, len(list))
+		for _, wt := range list {
+			byName[wt.Name] = wt
+		}
+		selected = nil
+		for _, name := range names {
+			wt, found := byName[name]
+			if !found {
+				fmt.Printf("✗ 未知工作区: %s\n", name)
+				return false
+			}
+			selected = append(selected, wt)
+		}
+	}
+
+	success := true
+	for _, wt := range selected {
+		// 正在构建的工作区跳过
+		unlock, ok := lockDirs(core.LockExclusive, wt.Path)
+		if !ok {
+			success = false
+			continue
+		}
+		var err error
+		if nodeModulesOnly {
+			err = core.RemoveNodeModules(wt)
+		} else {
+			err = core.RemoveWorktree(opencodeDir, wt)
+		}
+		unlock()
+		if err != nil {
+			fmt.Printf("✗ %s: %v\n", wt.Name, err)
+			success = false
+			continue
+		}
+		fmt.Printf("✓ 已清理 %s\n", wt.Name)
+	}
+	return success
+}
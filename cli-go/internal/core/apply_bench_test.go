@@ -0,0 +1,102 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// setupApplyBench 构造 files 个目标文件，每个文件对应一个包含 rules 条规则的配置
+func setupApplyBench(b *testing.B, files, rules int) (*I18n, []TranslationConfig, map[string][]byte) {
+	b.Helper()
+	tmpDir := b.TempDir()
+	originals := make(map[string][]byte)
+
+	var configs []TranslationConfig
+	for f := 0; f < files; f++ {
+		var sb strings.Builder
+		replacements := make(map[string]string, rules)
+		for r := 0; r < rules; r++ {
+			word := fmt.Sprintf("Word%d", r)
+			sb.WriteString(fmt.Sprintf("const s%d = %q // %s\n", r, fmt.Sprintf("Label %d", r), word))
+			replacements[fmt.Sprintf("%q", fmt.Sprintf("Label %d", r))] = fmt.Sprintf("%q", fmt.Sprintf("标签 %d", r))
+			replacements[word] = fmt.Sprintf("单词%d", r)
+		}
+
+		rel := fmt.Sprintf("packages/opencode/src/file%03d.tsx", f)
+		path := filepath.Join(tmpDir, filepath.FromSlash(rel))
+		if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+			b.Fatal(err)
+		}
+		content := []byte(strings.Repeat(sb.String(), 20))
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			b.Fatal(err)
+		}
+		originals[path] = content
+		configs = append(configs, TranslationConfig{File: rel, Replacements: replacements})
+	}
+
+	return &I18n{opencodeDir: tmpDir, matchers: newMatcherCache()}, configs, originals
+}
+
+// restoreBenchFiles 恢复目标文件，保证每次迭代的输入相同
+func restoreBenchFiles(b *testing.B, originals map[string][]byte) {
+	b.Helper()
+	for path, content := range originals {
+		if err := os.WriteFile(path, content, 0644); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func benchmarkApplyAll(b *testing.B, workers int) {
+	i18n, configs, originals := setupApplyBench(b, 64, 30)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		b.StopTimer()
+		restoreBenchFiles(b, originals)
+		b.StartTimer()
+		if _, err := i18n.ApplyAll(context.Background(), configs, ApplyOptions{Workers: workers}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkApplyAll_Sequential(b *testing.B) { benchmarkApplyAll(b, 1) }
+
+func BenchmarkApplyAll_Parallel(b *testing.B) { benchmarkApplyAll(b, 0) }
+
+func BenchmarkApplyAll_DryRun(b *testing.B) {
+	i18n, configs, _ := setupApplyBench(b, 64, 30)
+	b.ResetTimer()
+	for n := 0; n < b.N; n++ {
+		if _, err := i18n.ApplyAll(context.Background(), configs, ApplyOptions{DryRun: true}); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+func BenchmarkRuleMatcher_Uncached(b *testing.B) {
+	for n := 0; n < b.N; n++ {
+		newRuleMatcher("Word42").Match("const s = 1 // Word42\n")
+	}
+}
+
+func BenchmarkRuleMatcher_Cached(b *testing.B) {
+	cache := newMatcherCache()
+	for n := 0; n < b.N; n++ {
+		cache.get("Word42").Match("const s = 1 // Word42\n")
+	}
+}
+
+func BenchmarkLoadConfig_Embedded(b *testing.B) {
+	i18n := &I18n{i18nDir: "assets/opencode-i18n", useEmbedded: true}
+	for n := 0; n < b.N; n++ {
+		if _, err := i18n.LoadConfig(); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
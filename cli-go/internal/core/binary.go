@@ -0,0 +1,367 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"fmt"
+	"path"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// BunTrailer Bun 独立可执行文件 (bun build --compile) 中模块图末尾的标记
+const BunTrailer = "\n---- Bun! ----\n"
+
+// bunOffsetsLayout 一段 Bun 版本范围内位于标记之前的 Offsets 结构体大小
+// 结构体以 byte_count(u64) 和 modules_ptr(u32 offset, u32 length) 开头
+type bunOffsetsLayout struct {
+	min, max string
+	size     int
+}
+
+// bunOffsetsLayouts 已验证过的 Bun 版本及其 Offsets 布局，不在表中的版本拒绝修改
+var bunOffsetsLayouts = []bunOffsetsLayout{
+	// byte_count, modules_ptr, entry_point_id
+	{"1.1.0", "1.2.15", 24},
+	// 增加 compile_exec_argv_ptr
+	{"1.2.16", "1.3.99", 32},
+}
+
+// bunVersionPattern Bun 运行时内置的默认 User-Agent，如 Bun/1.2.20
+var bunVersionPattern = regexp.MustCompile(`Bun/(\d+\.\d+\.\d+)`)
+
+// bunModuleMarker 未压缩的打包产物在每个源文件之前写入的路径注释，如 // src/cli/cmd/tui/app.tsx
+var bunModuleMarker = regexp.MustCompile(`(?m)^// ([\w@$.+\-/]+\.(?:[cm]?[jt]sx?|json))$`)
+
+// BinaryFormat 可执行文件格式
+type BinaryFormat string
+
+const (
+	FormatELF     BinaryFormat = "ELF"
+	FormatMachO   BinaryFormat = "Mach-O"
+	FormatPE      BinaryFormat = "PE"
+	FormatUnknown BinaryFormat = "unknown"
+)
+
+// DetectBinaryFormat 根据文件头判断可执行文件格式
+func DetectBinaryFormat(data []byte) BinaryFormat {
+	switch {
+	case bytes.HasPrefix(data, []byte("\x7fELF")):
+		return FormatELF
+	case bytes.HasPrefix(data, []byte{0xcf, 0xfa, 0xed, 0xfe}),
+		bytes.HasPrefix(data, []byte{0xfe, 0xed, 0xfa, 0xcf}),
+		bytes.HasPrefix(data, []byte{0xca, 0xfe, 0xba, 0xbe}):
+		return FormatMachO
+	case bytes.HasPrefix(data, []byte("MZ")):
+		return FormatPE
+	}
+	return FormatUnknown
+}
+
+// BunBundle 可执行文件中内嵌 JavaScript 模块图的位置
+type BunBundle struct {
+	Start, End int
+	// Version 构建该可执行文件的 Bun 版本
+	Version string
+}
+
+// DetectBunVersion 从可执行文件内置的 User-Agent 中读取 Bun 版本
+func DetectBunVersion(data []byte) (string, error) {
+	match := bunVersionPattern.FindSubmatch(data)
+	if match == nil {
+		return "", fmt.Errorf("无法识别 Bun 版本，请用 --bun-version 指定")
+	}
+	return string(match[1]), nil
+}
+
+// bunOffsetsSize 返回 Bun 版本对应的 Offsets 结构体大小，未知版本返回错误
+func bunOffsetsSize(version string) (int, error) {
+	v, ok := parseBunVersion(version)
+	if !ok {
+		return 0, fmt.Errorf("无效的 Bun 版本: %s", version)
+	}
+	var known []string
+	for _, layout := range bunOffsetsLayouts {
+		lo, _ := parseBunVersion(layout.min)
+		hi, _ := parseBunVersion(layout.max)
+		if compareBunVersion(v, lo) >= 0 && compareBunVersion(v, hi) <= 0 {
+			return layout.size, nil
+		}
+		known = append(known, layout.min+" ~ "+layout.max)
+	}
+	return 0, fmt.Errorf("不支持的 Bun 版本 %s (已知: %s)，模块图布局未验证，拒绝修改", version, strings.Join(known, ", "))
+}
+
+func parseBunVersion(version string) ([3]int, bool) {
+	var v [3]int
+	parts := strings.Split(strings.TrimPrefix(version, "v"), ".")
+	if len(parts) != 3 {
+		return v, false
+	}
+	for i, part := range parts {
+		n, err := strconv.Atoi(part)
+		if err != nil {
+			return v, false
+		}
+		v[i] = n
+	}
+	return v, true
+}
+
+func compareBunVersion(a, b [3]int) int {
+	for i := range a {
+		if a[i] != b[i] {
+			return a[i] - b[i]
+		}
+	}
+	return 0
+}
+
+// LocateBunBundle 在 Bun 独立可执行文件中定位内嵌的模块图
+// version 为空时从文件中检测；版本未知或 Offsets 结构与该版本的布局不符时返回错误
+func LocateBunBundle(data []byte, version string) (*BunBundle, error) {
+	trailer := bytes.LastIndex(data, []byte(BunTrailer))
+	if trailer < 0 {
+		return nil, fmt.Errorf("未找到 Bun 模块图标记，不是 bun build --compile 生成的可执行文件")
+	}
+
+	if version == "" {
+		detected, err := DetectBunVersion(data[:trailer])
+		if err != nil {
+			return nil, err
+		}
+		version = detected
+	}
+	size, err := bunOffsetsSize(version)
+	if err != nil {
+		return nil, err
+	}
+
+	pos := trailer - size
+	if pos < 0 {
+		return nil, fmt.Errorf("文件过小，无法读取 Bun %s 的 Offsets 结构", version)
+	}
+	byteCount := binary.LittleEndian.Uint64(data[pos:])
+	modulesOffset := binary.LittleEndian.Uint32(data[pos+8:])
+	modulesLength := binary.LittleEndian.Uint32(data[pos+12:])
+	if byteCount == 0 || byteCount > uint64(pos) ||
+		modulesLength == 0 || uint64(modulesOffset)+uint64(modulesLength) > byteCount {
+		return nil, fmt.Errorf("Offsets 结构与 Bun %s 的布局不符，拒绝修改", version)
+	}
+	return &BunBundle{Start: pos - int(byteCount), End: pos, Version: version}, nil
+}
+
+// bunModule 模块图中一个源文件的打包代码范围（相对模块图起点）
+type bunModule struct {
+	path       string
+	start, end int
+}
+
+// bunModules 按源码路径注释切分模块图；压缩过的产物没有这些注释，返回空
+func bunModules(region []byte) []bunModule {
+	matches := bunModuleMarker.FindAllSubmatchIndex(region, -1)
+	modules := make([]bunModule, 0, len(matches))
+	for n, m := range matches {
+		end := len(region)
+		if n+1 < len(matches) {
+			end = matches[n+1][0]
+		}
+		modules = append(modules, bunModule{path: string(region[m[2]:m[3]]), start: m[1], end: end})
+	}
+	return modules
+}
+
+// findBunModule 返回覆盖规则目标文件的模块
+// 注释中的路径相对于打包根目录，取与 file 后缀匹配最长的一个
+func findBunModule(modules []bunModule, file string) (bunModule, bool) {
+	file = "/" + path.Clean(strings.ReplaceAll(file, "\\", "/"))
+	var best bunModule
+	found := false
+	for _, module := range modules {
+		rel := strings.TrimLeft(path.Clean(module.path), "./")
+		if rel == "" || !strings.HasSuffix(file, "/"+rel) {
+			continue
+		}
+		if !found || len(module.path) > len(best.path) {
+			best, found = module, true
+		}
+	}
+	return best, found
+}
+
+// BinaryPatchIssue 无法安全应用到二进制的规则
+type BinaryPatchIssue struct {
+	Config *TranslationConfig
+	From   string
+	Reason string
+}
+
+// BinaryPatchResult 二进制汉化结果
+type BinaryPatchResult struct {
+	Format BinaryFormat
+	Bundle BunBundle
+	// Modules 模块图中识别出的源文件数
+	Modules int
+	// Rules 成功应用的规则数，Occurrences 替换的位置总数
+	Rules       int
+	Occurrences int
+	Issues      []BinaryPatchIssue
+}
+
+// binaryPair 规则中一处需要在模块图中替换的文本
+type binaryPair struct {
+	from, to string
+	quote    rune // 0 表示 JSX 文本
+}
+
+// binaryEdit 模块图中的一次等长替换
+type binaryEdit struct {
+	offset int
+	data   []byte
+}
+
+// PatchBunBinary 将汉化包应用到 Bun 独立可执行文件内嵌的 JavaScript 模块图
+// 每条规则只在其目标文件 (config.File) 对应的模块代码中替换，找不到该模块时跳过；
+// 模块图中的偏移量不能改变，因此只做等长替换：译文较短时在字面量之后补空格，
+// 译文更长、在模块中找不到或结构不安全的规则记录在 Issues 中
+// bunVersion 为空时自动检测，data 会被原地修改
+func PatchBunBinary(data []byte, configs []TranslationConfig, bunVersion string) (*BinaryPatchResult, error) {
+	bundle, err := LocateBunBundle(data, bunVersion)
+	if err != nil {
+		return nil, err
+	}
+
+	region := data[bundle.Start:bundle.End]
+	modules := bunModules(region)
+	result := &BinaryPatchResult{
+		Format:  DetectBinaryFormat(data),
+		Bundle:  *bundle,
+		Modules: len(modules),
+	}
+
+	for idx := range configs {
+		config := &configs[idx]
+		module, ok := findBunModule(modules, config.File)
+		for _, r := range sortedReplacements(config.Replacements) {
+			if !ok {
+				result.Issues = append(result.Issues, BinaryPatchIssue{Config: config, From: r.From, Reason: "模块图中未找到 " + config.File})
+				continue
+			}
+			segment := region[module.start:module.end]
+			edits, reason := planBinaryEdits(segment, r.From, r.To)
+			if reason != "" {
+				result.Issues = append(result.Issues, BinaryPatchIssue{Config: config, From: r.From, Reason: reason})
+				continue
+			}
+			for _, edit := range edits {
+				copy(segment[edit.offset:], edit.data)
+			}
+			result.Rules++
+			result.Occurrences += len(edits)
+		}
+	}
+
+	return result, nil
+}
+
+// planBinaryEdits 计算单条规则在模块图中的所有替换，任一文本无法替换时整条规则放弃
+func planBinaryEdits(region []byte, from, to string) ([]binaryEdit, string) {
+	pairs, reason := binaryPairs(from, to)
+	if reason != "" {
+		return nil, reason
+	}
+
+	var edits []binaryEdit
+	for _, pair := range pairs {
+		found := false
+		for _, candidate := range binaryCandidates(pair) {
+			offsets := findAll(region, []byte(candidate.search))
+			if len(offsets) == 0 {
+				continue
+			}
+			found = true
+
+			diff := len(candidate.search) - len(candidate.replace)
+			if diff < 0 {
+				return nil, fmt.Sprintf("译文比原文长 %d 字节", -diff)
+			}
+			if diff > 0 && !candidate.padded {
+				return nil, "译文长度不同且无法在此处补齐"
+			}
+			replace := candidate.replace + strings.Repeat(" ", diff)
+			for _, offset := range offsets {
+				edits = append(edits, binaryEdit{offset: offset, data: []byte(replace)})
+			}
+			break
+		}
+		if !found {
+			return nil, fmt.Sprintf("模块中未找到 %s", Truncate(pair.from, 30))
+		}
+	}
+
+	return edits, ""
+}
+
+// binaryPairs 提取规则中需要替换的字面量与 JSX 文本
+// 没有引号和标签的纯文本规则（如 "Prev"）按 JSX 文本处理
+func binaryPairs(from, to string) ([]binaryPair, string) {
+	fromSegs, toSegs, changed, reason := pairSegments(from, to)
+	if reason == reasonNoContext && !strings.ContainsAny(from+to, "{}()<>=;") {
+		return []binaryPair{{from: strings.TrimSpace(from), to: strings.TrimSpace(to)}}, ""
+	}
+	if reason != "" {
+		return nil, reason
+	}
+
+	var pairs []binaryPair
+	for _, n := range changed {
+		f, t := fromSegs[n], toSegs[n]
+		if f.quote != 0 && !f.closed {
+			return nil, "字符串字面量被截断"
+		}
+		if f.quote == 0 {
+			pairs = append(pairs, binaryPair{from: strings.TrimSpace(f.text), to: strings.TrimSpace(t.text)})
+			continue
+		}
+		pairs = append(pairs, binaryPair{from: f.text, to: t.text, quote: f.quote})
+	}
+	return pairs, ""
+}
+
+// binaryCandidate 文本在打包产物中的一种可能形式
+type binaryCandidate struct {
+	search, replace string
+	// padded 为 true 表示位于字符串字面量之外，可以用空格补齐长度
+	padded bool
+}
+
+// binaryCandidates 返回文本在打包产物中可能的形式
+// 打包器可能统一引号，JSX 文本会被编译为字符串字面量或模板中的 >文本<
+func binaryCandidates(pair binaryPair) []binaryCandidate {
+	if pair.quote != 0 {
+		q := string(pair.quote)
+		candidates := []binaryCandidate{{q + pair.from + q, q + pair.to + q, true}}
+		if pair.quote != '"' && !strings.Contains(pair.from+pair.to, `"`) {
+			candidates = append(candidates, binaryCandidate{`"` + pair.from + `"`, `"` + pair.to + `"`, true})
+		}
+		return candidates
+	}
+	return []binaryCandidate{
+		{jsString(pair.from), jsString(pair.to), true},
+		{">" + pair.from + "<", ">" + pair.to + "<", false},
+	}
+}
+
+// findAll 返回 sep 在 data 中所有不重叠出现的位置
+func findAll(data, sep []byte) []int {
+	var offsets []int
+	for start := 0; ; {
+		n := bytes.Index(data[start:], sep)
+		if n < 0 {
+			return offsets
+		}
+		offsets = append(offsets, start+n)
+		start += n + len(sep)
+	}
+}
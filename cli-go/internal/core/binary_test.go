@@ -0,0 +1,171 @@
+package core
+
+import (
+	"bytes"
+	"encoding/binary"
+	"os"
+	"strings"
+	"testing"
+)
+
+// buildBunFixture 构造一个最小的 Bun 独立可执行文件：原生代码 + 模块图 + Offsets + 标记
+// 原生代码中包含 Bun 1.2.20 的 User-Agent，对应 32 字节的 Offsets 结构
+func buildBunFixture(native, bundle string) []byte {
+	var buf bytes.Buffer
+	buf.WriteString("\x7fELF")
+	buf.WriteString(native)
+	buf.WriteString(" Bun/1.2.20 ")
+	buf.WriteString(bundle)
+
+	offsets := make([]byte, 32)
+	binary.LittleEndian.PutUint64(offsets[0:], uint64(len(bundle)))
+	binary.LittleEndian.PutUint32(offsets[8:], 0)
+	binary.LittleEndian.PutUint32(offsets[12:], uint32(len(bundle)))
+	buf.Write(offsets)
+	buf.WriteString(BunTrailer)
+	return buf.Bytes()
+}
+
+// ========== LocateBunBundle 测试 ==========
+
+func TestLocateBunBundle(t *testing.T) {
+	data := buildBunFixture("native", "bundle")
+	bundle, err := LocateBunBundle(data, "")
+	if err != nil {
+		t.Fatalf("定位模块图失败: %v", err)
+	}
+	if bundle.Version != "1.2.20" || string(data[bundle.Start:bundle.End]) != "bundle" {
+		t.Errorf("模块图范围错误: %+v", bundle)
+	}
+
+	if _, err := LocateBunBundle([]byte("\x7fELF plain binary"), ""); err == nil {
+		t.Error("非 Bun 可执行文件应返回错误")
+	}
+}
+
+func TestLocateBunBundle_UnknownVersion(t *testing.T) {
+	data := buildBunFixture("native", "bundle")
+
+	// 未知版本不猜测布局
+	if _, err := LocateBunBundle(data, "0.8.1"); err == nil || !strings.Contains(err.Error(), "不支持的 Bun 版本") {
+		t.Errorf("未知版本应返回错误: %v", err)
+	}
+	// 检测不到版本时要求显式指定
+	noVersion := bytes.Replace(data, []byte("Bun/1.2.20"), []byte("xxx/1.2.20"), 1)
+	if _, err := LocateBunBundle(noVersion, ""); err == nil || !strings.Contains(err.Error(), "--bun-version") {
+		t.Errorf("无法识别版本时应返回错误: %v", err)
+	}
+	// 版本与实际布局不符时拒绝修改
+	if _, err := LocateBunBundle(data, "1.2.0"); err == nil || !strings.Contains(err.Error(), "布局不符") {
+		t.Errorf("布局不符时应返回错误: %v", err)
+	}
+}
+
+// ========== PatchBunBinary 测试 ==========
+
+func TestPatchBunBinary(t *testing.T) {
+	native := `"Warning" builtin`
+	other := "// src/util/log.ts\nvar e=\"Warning\";\n"
+	bundle := "// src/cli/cmd/tui/app.tsx\n" +
+		`var a={title:"Select model"};var b="Warning";insert(el,"Exit app");var c='Delete session';var d="Go"` + "\n" + other
+	data := buildBunFixture(native, bundle)
+	size := len(data)
+
+	configs := []TranslationConfig{{
+		File:     "packages/opencode/src/cli/cmd/tui/app.tsx",
+		FileName: "app.json",
+		Replacements: map[string]string{
+			`title: "Select model"`:                 `title: "选择模型"`,
+			`"Warning"`:                             `"警告"`,
+			`<text fg={theme.text}>Exit app</text>`: `<text fg={theme.text}>退出</text>`,
+			`title: 'Delete session'`:               `title: '删除会话'`,
+			`label: "Go"`:                           `label: "前往"`,
+			`label: "Missing"`:                      `label: "缺失"`,
+		},
+	}, {
+		File:         "packages/opencode/src/cli/cmd/run.ts",
+		FileName:     "run.json",
+		Replacements: map[string]string{`"Warning"`: `"警告"`},
+	}}
+
+	result, err := PatchBunBinary(data, configs, "")
+	if err != nil {
+		t.Fatalf("PatchBunBinary 失败: %v", err)
+	}
+
+	if len(data) != size {
+		t.Fatalf("文件大小不应改变: %d -> %d", size, len(data))
+	}
+	if result.Format != FormatELF || result.Rules != 4 || result.Modules != 2 {
+		t.Errorf("结果错误: %+v", result)
+	}
+
+	content := string(data)
+	for _, want := range []string{
+		`title:"选择模型"`,
+		`var b="警告" `,
+		`insert(el,"退出"  )`,
+		`var c='删除会话'`,
+		`var d="Go"`,
+	} {
+		if !strings.Contains(content, want) {
+			t.Errorf("结果中缺少 %q:\n%s", want, content)
+		}
+	}
+	// 模块图之外的原生代码和其他源文件的代码不应被修改
+	if !strings.Contains(content, native) {
+		t.Error("模块图之外的内容被修改")
+	}
+	if !strings.Contains(content, other) {
+		t.Error("规则目标文件之外的模块被修改")
+	}
+
+	reasons := make(map[string]string)
+	for _, issue := range result.Issues {
+		reasons[issue.Config.FileName+" "+issue.From] = issue.Reason
+	}
+	if !strings.Contains(reasons[`run.json "Warning"`], "src/cli/cmd/run.ts") {
+		t.Errorf("模块不存在时应跳过, got %q", reasons[`run.json "Warning"`])
+	}
+	if !strings.Contains(reasons[`app.json label: "Go"`], "长") {
+		t.Errorf("应报告译文过长, got %q", reasons[`app.json label: "Go"`])
+	}
+	if !strings.Contains(reasons[`app.json label: "Missing"`], "未找到") {
+		t.Errorf("应报告未找到, got %q", reasons[`app.json label: "Missing"`])
+	}
+}
+
+// TestPatchBunBinary_Release 用真实的官方可执行文件验证版本检测与模块定位
+// 设置 OPENCODE_TEST_BINARY 为 opencode 可执行文件路径后运行
+func TestPatchBunBinary_Release(t *testing.T) {
+	binaryPath := os.Getenv("OPENCODE_TEST_BINARY")
+	if binaryPath == "" {
+		t.Skip("未设置 OPENCODE_TEST_BINARY")
+	}
+	data, err := os.ReadFile(binaryPath)
+	if err != nil {
+		t.Fatal(err)
+	}
+	size := len(data)
+
+	i18n, err := NewI18nWithOptions(Options{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	configs, err := i18n.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	result, err := PatchBunBinary(data, configs, "")
+	if err != nil {
+		t.Fatalf("PatchBunBinary 失败: %v", err)
+	}
+	if len(data) != size {
+		t.Fatalf("文件大小不应改变: %d -> %d", size, len(data))
+	}
+	t.Logf("Bun %s, %d 个源文件, %d 条规则已应用, %d 条跳过",
+		result.Bundle.Version, result.Modules, result.Rules, len(result.Issues))
+	if result.Modules == 0 || result.Rules == 0 {
+		t.Errorf("应在真实模块图中应用规则: %+v", result)
+	}
+}
@@ -0,0 +1,201 @@
+package core
+
+import (
+	"fmt"
+	"os/exec"
+	"strings"
+)
+
+// RuleBisect 一条规则在上游提交范围内失效的位置
+type RuleBisect struct {
+	Config TranslationConfig
+	From   string
+	ID     string
+	// Path 目标文件（相对源码根目录）
+	Path string
+	// Good 最后一个仍能匹配的提交，Bad 第一个不再匹配的提交
+	Good string
+	Bad  string
+	// Subject、Author、Date 为 Bad 提交的信息
+	Subject string
+	Author  string
+	Date    string
+	// Hunks Bad 提交中该文件与规则相关的 diff 片段，找不到相关片段时为完整 diff
+	Hunks string
+	// Commits 范围内修改过该文件的提交数，Steps 实际检查的提交数（含 good 和 bad）
+	Commits int
+	Steps   int
+}
+
+// FindRules 按规则 ID 或配置文件名查找规则
+// spec 为 RuleID（如 dialogs/dialog-model#1a2b3c4d）时返回该规则；
+// 为配置名（dialog-model、dialogs/dialog-model 或 dialog-model.json）时返回该配置的全部规则，from 非空时只返回原文等于 from 的规则
+func FindRules(configs []TranslationConfig, spec, from string) ([]RuleBisect, error) {
+	name, hash, hasHash := strings.Cut(spec, "#")
+	name = strings.TrimSuffix(name, ".json")
+
+	var rules []RuleBisect
+	for _, config := range configs {
+		if name != strings.TrimSuffix(config.FileName, ".json") && name != ruleConfigName(config) {
+			continue
+		}
+		for _, find := range orderedRules(config.Replacements) {
+			ruleID := RuleID(config, find)
+			if hasHash && !strings.HasSuffix(ruleID, "#"+hash) {
+				continue
+			}
+			if from != "" && find != from {
+				continue
+			}
+			rules = append(rules, RuleBisect{Config: config, From: find, ID: ruleID})
+		}
+	}
+	if len(rules) == 0 {
+		return nil, fmt.Errorf("找不到规则 %s", spec)
+	}
+	return rules, nil
+}
+
+// BisectRule 在 good..bad 范围内查找规则 from 第一次无法匹配的提交
+// 只沿第一父提交检查修改过目标文件的提交（合并进来的分支按合并提交整体判断，保证序列线性），
+// 按规则是否匹配二分查找，不构建、不检出
+func (i *I18n) BisectRule(rule RuleBisect, good, bad string) (*RuleBisect, error) {
+	if i.isGlobConfig(rule.Config) {
+		return nil, fmt.Errorf("%s 的 file 为 glob，请先用 impact 确认具体文件", rule.Config.FileName)
+	}
+	rel, err := i.targetRelPath(rule.Config)
+	if err != nil {
+		return nil, err
+	}
+	rule.Path = rel
+
+	dir := i.opencodeDir
+	goodCommit, err := GitResolveCommit(dir, good)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 %s: %w", good, err)
+	}
+	badCommit, err := GitResolveCommit(dir, bad)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 %s: %w", bad, err)
+	}
+
+	if !i.ruleMatchesAt(rule, goodCommit) {
+		return nil, fmt.Errorf("规则在 %s 上也无法匹配，不是这段提交引入的", ShortHash(goodCommit))
+	}
+	if i.ruleMatchesAt(rule, badCommit) {
+		return nil, fmt.Errorf("规则在 %s 上仍然匹配", ShortHash(badCommit))
+	}
+
+	out, err := ExecInDirQuiet(dir, "git", "rev-list", "--reverse", "--first-parent", goodCommit+".."+badCommit, "--", rel)
+	if err != nil {
+		return nil, err
+	}
+	commits := strings.Fields(out)
+	rule.Commits = len(commits)
+	rule.Steps = 2
+	if len(commits) == 0 {
+		return nil, fmt.Errorf("%s..%s 之间没有修改 %s 的提交（文件可能已被重命名）", ShortHash(goodCommit), ShortHash(badCommit), rel)
+	}
+
+	// commits[lo-1] 仍匹配（lo 为 0 时即 good），commits[hi] 不再匹配
+	lo, hi := 0, len(commits)-1
+	for lo < hi {
+		mid := (lo + hi) / 2
+		rule.Steps++
+		if i.ruleMatchesAt(rule, commits[mid]) {
+			lo = mid + 1
+		} else {
+			hi = mid
+		}
+	}
+
+	rule.Bad = commits[hi]
+	rule.Good = goodCommit
+	if hi > 0 {
+		rule.Good = commits[hi-1]
+	}
+	if info, err := ExecInDirQuiet(dir, "git", "log", "-1", "--format=%s%x09%an%x09%cs", rule.Bad); err == nil {
+		fields := strings.SplitN(info, "\t", 3)
+		if len(fields) == 3 {
+			rule.Subject, rule.Author, rule.Date = fields[0], fields[1], fields[2]
+		}
+	}
+	if diff, err := ExecInDirQuiet(dir, "git", "diff", rule.Bad+"^", rule.Bad, "--", rel); err == nil {
+		rule.Hunks = relevantHunks(diff, rule.From)
+	}
+	return &rule, nil
+}
+
+// RuleMatchesAt 判断规则在 ref 上是否仍能匹配
+func (i *I18n) RuleMatchesAt(rule RuleBisect, ref string) bool {
+	rel, err := i.targetRelPath(rule.Config)
+	if err != nil {
+		return false
+	}
+	rule.Path = rel
+	return i.ruleMatchesAt(rule, ref)
+}
+
+// ruleMatchesAt 判断规则原文是否出现在指定提交的目标文件中，文件不存在视为不匹配
+func (i *I18n) ruleMatchesAt(rule RuleBisect, commit string) bool {
+	file, ok := gitShowTextFile(i.opencodeDir, commit, rule.Path)
+	return ok && i.ruleMatchesIn(file, rule.From, rule.Config.Replacements[rule.From])
+}
+
+// ruleMatchesIn 判断规则原文是否出现在文件内容中（与应用时的匹配规则一致）
+func (i *I18n) ruleMatchesIn(file *TextFile, from, to string) bool {
+	matcher, _ := i.ruleFor(file, file.Content, from, to)
+	return matcher.Match(file.Content)
+}
+
+// gitShowTextFile 读取指定提交中的文件，文件不存在时返回 false
+func gitShowTextFile(dir, commit, rel string) (*TextFile, bool) {
+	cmd := exec.Command("git", "show", commit+":"+rel)
+	cmd.Dir = dir
+	data, err := cmd.Output()
+	if err != nil {
+		return nil, false
+	}
+	return DecodeTextFile(data), true
+}
+
+// relevantHunks 从统一 diff 中挑出删除行包含规则原文（首个非空行）的片段
+// 没有这样的片段时返回完整 diff
+func relevantHunks(diff, from string) string {
+	needle := ""
+	for _, line := range strings.Split(from, "\n") {
+		if line = strings.TrimSpace(line); line != "" {
+			needle = line
+			break
+		}
+	}
+
+	var hunks []string
+	var current []string
+	flush := func() {
+		if len(current) == 0 {
+			return
+		}
+		for _, line := range current[1:] {
+			if strings.HasPrefix(line, "-") && strings.Contains(line, needle) {
+				hunks = append(hunks, strings.Join(current, "\n"))
+				break
+			}
+		}
+		current = nil
+	}
+	for _, line := range strings.Split(diff, "\n") {
+		if strings.HasPrefix(line, "@@") {
+			flush()
+			current = []string{line}
+		} else if current != nil {
+			current = append(current, line)
+		}
+	}
+	flush()
+
+	if needle == "" || len(hunks) == 0 {
+		return diff
+	}
+	return strings.Join(hunks, "\n")
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// ========== 规则二分查找测试 ==========
+
+func TestFindRules(t *testing.T) {
+	configs := []TranslationConfig{
+		{FileName: "dialog-model.json", Category: "dialogs", Replacements: map[string]string{"Select model": "选择模型", "Search": "搜索"}},
+		{FileName: "app.json", Category: "root", Replacements: map[string]string{"Hello": "你好"}},
+	}
+
+	rules, err := FindRules(configs, "dialogs/dialog-model", "")
+	if err != nil || len(rules) != 2 {
+		t.Fatalf("应返回配置的全部规则: %+v, %v", rules, err)
+	}
+	id := RuleID(configs[0], "Search")
+	if rules, _ := FindRules(configs, id, ""); len(rules) != 1 || rules[0].From != "Search" || rules[0].ID != id {
+		t.Errorf("按规则 ID 查找失败: %+v", rules)
+	}
+	if rules, _ := FindRules(configs, "dialog-model.json", "Select model"); len(rules) != 1 || rules[0].From != "Select model" {
+		t.Errorf("按 --from 筛选失败: %+v", rules)
+	}
+	if rules, _ := FindRules(configs, "app", ""); len(rules) != 1 {
+		t.Errorf("根目录配置应按文件名查找: %+v", rules)
+	}
+	if _, err := FindRules(configs, "dialogs/missing", ""); err == nil {
+		t.Error("未知配置应报错")
+	}
+}
+
+func TestBisectRule(t *testing.T) {
+	dir, _ := newGitRepo(t, 1)
+	target := "packages/opencode/src/app.tsx"
+	commit := func(content, message string) string {
+		writePackFile(t, dir, target, content)
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-q", "-m", message)
+		return runGit(t, dir, "rev-parse", "HEAD")
+	}
+
+	good := commit("<text>Select model</text>\n<text>Search</text>\n", "add app")
+	commit("<text>Select model</text>\n<text>Search</text>\n<text>New</text>\n", "add New")
+	// 不修改目标文件的提交不参与查找
+	writePackFile(t, dir, "README.md", "readme")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "docs")
+	breaking := commit("<text>Choose a model</text>\n<text>Search</text>\n<text>New</text>\n", "reword model picker")
+	commit("<text>Choose a model</text>\n<text>Search...</text>\n<text>New</text>\n", "tweak search")
+
+	i18n := &I18n{opencodeDir: dir}
+	config := TranslationConfig{FileName: "app.json", File: target, Replacements: map[string]string{"Select model": "选择模型"}}
+	rule := RuleBisect{Config: config, From: "Select model", ID: RuleID(config, "Select model")}
+
+	if i18n.RuleMatchesAt(rule, "HEAD") || !i18n.RuleMatchesAt(rule, good) {
+		t.Fatal("规则应在 good 上匹配、在 HEAD 上失效")
+	}
+
+	result, err := i18n.BisectRule(rule, good, "HEAD")
+	if err != nil {
+		t.Fatalf("二分查找失败: %v", err)
+	}
+	if result.Bad != breaking || result.Subject != "reword model picker" {
+		t.Errorf("应定位到 reword model picker: %+v", result)
+	}
+	if result.Commits != 3 || result.Path != target {
+		t.Errorf("应只检查修改目标文件的 3 个提交: %+v", result)
+	}
+	if !strings.Contains(result.Hunks, "-<text>Select model</text>") || !strings.Contains(result.Hunks, "+<text>Choose a model</text>") {
+		t.Errorf("应包含相关 diff 片段: %q", result.Hunks)
+	}
+
+	if _, err := i18n.BisectRule(rule, "HEAD", "HEAD"); err == nil {
+		t.Error("good 上不匹配时应报错")
+	}
+	search := RuleBisect{Config: config, From: "<text>Search</text>"}
+	if _, err := i18n.BisectRule(search, good, breaking); err == nil {
+		t.Error("bad 上仍匹配时应报错")
+	}
+}
+
+func TestBisectRule_FirstParent(t *testing.T) {
+	dir, _ := newGitRepo(t, 1)
+	target := "packages/opencode/src/app.tsx"
+	commit := func(content, message string) string {
+		writePackFile(t, dir, target, content)
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-q", "-m", message)
+		return runGit(t, dir, "rev-parse", "HEAD")
+	}
+
+	good := commit("<text>Select model</text>\n", "add app")
+	// 功能分支上暂时改掉文案又改回，合并后规则仍然匹配
+	runGit(t, dir, "checkout", "-q", "-b", "feature")
+	commit("<text>Pick model</text>\n", "feature: reword")
+	commit("<text>Pick model</text>\n<text>A</text>\n", "feature: add A")
+	commit("<text>Pick model</text>\n<text>B</text>\n", "feature: add B")
+	commit("<text>Select model</text>\n<text>B</text>\n", "feature: revert wording")
+	runGit(t, dir, "checkout", "-q", "dev")
+	runGit(t, dir, "merge", "-q", "--no-ff", "-m", "merge feature", "feature")
+	breaking := commit("<text>Choose a model</text>\n<text>B</text>\n", "reword model picker")
+
+	i18n := &I18n{opencodeDir: dir}
+	config := TranslationConfig{FileName: "app.json", File: target, Replacements: map[string]string{"Select model": "选择模型"}}
+	rule := RuleBisect{Config: config, From: "Select model"}
+
+	result, err := i18n.BisectRule(rule, good, "HEAD")
+	if err != nil {
+		t.Fatalf("二分查找失败: %v", err)
+	}
+	if result.Bad != breaking || result.Good != runGit(t, dir, "rev-parse", "HEAD^") {
+		t.Errorf("应沿主线定位到 reword model picker: %+v", result)
+	}
+	if result.Commits != 2 {
+		t.Errorf("应只检查主线上的合并提交和 reword 提交: %d", result.Commits)
+	}
+}
+
+func TestRelevantHunks(t *testing.T) {
+	diff := strings.Join([]string{
+		"diff --git a/app.tsx b/app.tsx",
+		"@@ -1,2 +1,2 @@",
+		"-<text>One</text>",
+		"+<text>Uno</text>",
+		"@@ -10,2 +10,2 @@",
+		"-<text>Select model</text>",
+		"+<text>Choose a model</text>",
+	}, "\n")
+
+	got := relevantHunks(diff, "Select model")
+	if strings.Contains(got, "Uno") || !strings.HasPrefix(got, "@@ -10,2") {
+		t.Errorf("应只保留相关片段: %q", got)
+	}
+	if got := relevantHunks(diff, "Missing"); got != diff {
+		t.Errorf("找不到相关片段时应返回完整 diff: %q", got)
+	}
+}
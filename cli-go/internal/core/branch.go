@@ -0,0 +1,193 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"strings"
+)
+
+// TranslationBranch 上游源码中保存汉化结果的本地分支
+const TranslationBranch = "i18n/" + RuntimeLocale
+
+// packTrailer 汉化提交的 trailer 键，用于在分支历史中识别工具生成的提交
+const packTrailer = "I18n-Pack"
+
+// translationCommitter 汉化提交使用的身份，与用户自己的提交区分
+var translationCommitter = []string{"-c", "user.name=opencode-i18n", "-c", "user.email=opencode-i18n@localhost"}
+
+// BranchReport 汉化分支的重建结果
+type BranchReport struct {
+	// Base 汉化提交所基于的上游提交
+	Base string
+	// Commit 新的汉化提交
+	Commit string
+	Apply  *ApplyReport
+	// Carried 从旧分支迁移到新汉化提交之上的手工提交数
+	Carried int
+}
+
+// BranchConflictError 迁移旧分支上的手工提交时发生冲突，汉化分支保持不变
+type BranchConflictError struct {
+	Commit  string
+	Subject string
+}
+
+func (e *BranchConflictError) Error() string {
+	return fmt.Sprintf("手工提交 %s (%s) 无法迁移到新的汉化提交之上，汉化分支 %s 未修改；请在该分支上解决冲突或删除该提交后重试",
+		ShortHash(e.Commit), e.Subject, TranslationBranch)
+}
+
+// MissedRule 重新应用时找不到匹配的规则
+type MissedRule struct {
+	Config TranslationConfig
+	From   string
+}
+
+// MissedRules 返回未能匹配的规则，按配置顺序排列
+func (r *BranchReport) MissedRules() []MissedRule {
+	var missed []MissedRule
+	if r.Apply == nil {
+		return nil
+	}
+	for n, result := range r.Apply.Files {
+		for _, from := range result.Missed {
+			missed = append(missed, MissedRule{Config: r.Apply.Configs[n], From: from})
+		}
+	}
+	return missed
+}
+
+// GitCurrentBranch 返回当前分支名，分离 HEAD 时返回空字符串
+func GitCurrentBranch(dir string) string {
+	out, err := ExecInDirQuiet(dir, "git", "symbolic-ref", "--quiet", "--short", "HEAD")
+	if err != nil {
+		return ""
+	}
+	return out
+}
+
+// GitBranchExists 判断本地分支是否存在
+func GitBranchExists(dir, branch string) bool {
+	_, err := GitResolveCommit(dir, "refs/heads/"+branch)
+	return err == nil
+}
+
+// translationCommit 返回分支上最近一次工具生成的汉化提交
+func translationCommit(dir, branch string) (string, error) {
+	out, err := ExecInDirQuiet(dir, "git", "log", "-1", "--format=%H", "--grep=^"+packTrailer+":", "refs/heads/"+branch)
+	if err != nil {
+		return "", err
+	}
+	if out == "" {
+		return "", fmt.Errorf("分支 %s 上没有汉化提交", branch)
+	}
+	return out, nil
+}
+
+// TranslationBase 返回汉化分支所基于的上游提交（汉化提交的父提交）
+func TranslationBase(dir string) (string, error) {
+	commit, err := translationCommit(dir, TranslationBranch)
+	if err != nil {
+		return "", err
+	}
+	return GitResolveCommit(dir, commit+"^")
+}
+
+// BuildTranslationBranch 在 base 之上重建汉化分支
+// 在 base 的分离 HEAD 上应用 configs 并提交为一个汉化提交，再将旧分支上汉化提交之后的手工提交依次迁移过来；
+// 全部成功后才移动分支并检出。任何一步失败（包括迁移冲突，返回 *BranchConflictError）都恢复原来的检出位置，分支不变
+// 要求工作区没有未提交的修改
+func (i *I18n) BuildTranslationBranch(ctx context.Context, base string, configs []TranslationConfig, opts ApplyOptions) (report *BranchReport, err error) {
+	dir := i.opencodeDir
+	if opts.DryRun {
+		return nil, fmt.Errorf("模拟运行不能提交到分支 %s", TranslationBranch)
+	}
+	if out, err := ExecInDirQuiet(dir, "git", "status", "--porcelain"); err != nil {
+		return nil, err
+	} else if out != "" {
+		return nil, fmt.Errorf("工作区有未提交的修改，请先提交或清理")
+	}
+
+	baseCommit, err := GitResolveCommit(dir, base)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 %s: %w", base, err)
+	}
+	report = &BranchReport{Base: baseCommit}
+
+	// 记录旧分支上汉化提交之后的手工提交
+	var carry []string
+	if GitBranchExists(dir, TranslationBranch) {
+		if old, err := translationCommit(dir, TranslationBranch); err == nil {
+			out, err := ExecInDirQuiet(dir, "git", "rev-list", "--reverse", old+"..refs/heads/"+TranslationBranch)
+			if err != nil {
+				return nil, err
+			}
+			carry = strings.Fields(out)
+		}
+	}
+
+	snapshot, err := SnapshotRepo(dir)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := ExecInDirQuiet(dir, "git", "checkout", "-q", "--detach", baseCommit); err != nil {
+		return nil, err
+	}
+	defer func() {
+		if err != nil {
+			if restoreErr := snapshot.Restore(); restoreErr != nil {
+				err = fmt.Errorf("%w (恢复到 %s 失败: %v)", err, snapshot, restoreErr)
+			}
+		}
+	}()
+
+	report.Apply, err = i.ApplyAll(ctx, configs, opts)
+	if err != nil {
+		return report, err
+	}
+
+	if _, err = ExecInDirQuiet(dir, "git", "add", "-A"); err != nil {
+		return report, err
+	}
+	args := append(append([]string{}, translationCommitter...), "commit", "-q", "--allow-empty",
+		"-m", fmt.Sprintf("i18n: apply %s translation", RuntimeLocale),
+		"-m", fmt.Sprintf("%s: %s", packTrailer, i.packLabel()))
+	if _, err = ExecInDirQuiet(dir, "git", args...); err != nil {
+		return report, err
+	}
+	if report.Commit, err = GitResolveCommit(dir, "HEAD"); err != nil {
+		return report, err
+	}
+
+	for _, commit := range carry {
+		if err = ctx.Err(); err != nil {
+			return report, err
+		}
+		if _, pickErr := ExecInDirQuiet(dir, "git", append(append([]string{}, translationCommitter...), "cherry-pick", "--allow-empty", commit)...); pickErr != nil {
+			subject, _ := ExecInDirQuiet(dir, "git", "log", "-1", "--format=%s", commit)
+			return report, &BranchConflictError{Commit: commit, Subject: subject}
+		}
+		report.Carried++
+	}
+
+	// 全部完成后才移动分支
+	if _, err = ExecInDirQuiet(dir, "git", "update-ref", "refs/heads/"+TranslationBranch, "HEAD"); err != nil {
+		return report, err
+	}
+	if _, err = ExecInDirQuiet(dir, "git", "checkout", "-q", TranslationBranch); err != nil {
+		return report, err
+	}
+	return report, nil
+}
+
+// packLabel 返回汉化包名称与版本，用于汉化提交的 trailer
+func (i *I18n) packLabel() string {
+	manifest, err := i.LoadManifest()
+	if err != nil || manifest.Name == "" {
+		return "unknown"
+	}
+	if manifest.Version == "" {
+		return manifest.Name
+	}
+	return manifest.Name + "@" + manifest.Version
+}
@@ -0,0 +1,136 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// ========== 汉化分支测试 ==========
+
+func TestBuildTranslationBranch(t *testing.T) {
+	dir, _ := newGitRepo(t, 1)
+	target := "packages/opencode/src/app.tsx"
+	writePackFile(t, dir, target, `<text>Hello</text><text>Bye</text>`)
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "upstream app")
+	upstream := runGit(t, dir, "rev-parse", "HEAD")
+
+	i18n := &I18n{opencodeDir: dir}
+	configs := []TranslationConfig{{
+		FileName:     "app.json",
+		File:         target,
+		Replacements: map[string]string{"Hello": "你好", "Bye": "再见"},
+	}}
+
+	report, err := i18n.BuildTranslationBranch(context.Background(), "HEAD", configs, ApplyOptions{})
+	if err != nil {
+		t.Fatalf("创建汉化分支失败: %v", err)
+	}
+	if report.Base != upstream || GitCurrentBranch(dir) != TranslationBranch {
+		t.Fatalf("应在 %s 之上创建分支, got base=%s branch=%s", upstream, report.Base, GitCurrentBranch(dir))
+	}
+	if msg := runGit(t, dir, "log", "-1", "--format=%B"); !strings.Contains(msg, packTrailer+": ") {
+		t.Errorf("汉化提交缺少 trailer: %q", msg)
+	}
+	if base, err := TranslationBase(dir); err != nil || base != upstream {
+		t.Errorf("TranslationBase = %s, %v", base, err)
+	}
+
+	// 分支上的手工提交
+	writePackFile(t, dir, "NOTES.md", "manual")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "manual tweak")
+
+	// 上游更新：删除 Bye
+	runGit(t, dir, "checkout", "-q", "dev")
+	writePackFile(t, dir, target, `<text>Hello</text>`)
+	runGit(t, dir, "commit", "-q", "-am", "upstream drops Bye")
+
+	report, err = i18n.BuildTranslationBranch(context.Background(), "dev", configs, ApplyOptions{})
+	if err != nil {
+		t.Fatalf("重建汉化分支失败: %v", err)
+	}
+	if report.Carried != 1 {
+		t.Errorf("应迁移 1 个手工提交: %+v", report)
+	}
+	missed := report.MissedRules()
+	if len(missed) != 1 || missed[0].From != "Bye" {
+		t.Errorf("应报告 Bye 未能重新应用: %+v", missed)
+	}
+	if got := readFileString(t, dir, target); got != `<text>你好</text>` {
+		t.Errorf("文件内容错误: %q", got)
+	}
+	if got := readFileString(t, dir, "NOTES.md"); got != "manual" {
+		t.Errorf("手工提交未迁移: %q", got)
+	}
+}
+
+func TestBuildTranslationBranch_DirtyTree(t *testing.T) {
+	dir, _ := newGitRepo(t, 1)
+	writePackFile(t, dir, "file.txt", "dirty")
+
+	i18n := &I18n{opencodeDir: dir}
+	if _, err := i18n.BuildTranslationBranch(context.Background(), "HEAD", nil, ApplyOptions{}); err == nil {
+		t.Error("工作区有修改时应拒绝")
+	}
+}
+
+func TestBuildTranslationBranch_ConflictKeepsBranch(t *testing.T) {
+	dir, _ := newGitRepo(t, 1)
+	target := "packages/opencode/src/app.tsx"
+	writePackFile(t, dir, target, "<text>Hello</text>\n")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "upstream app")
+
+	i18n := &I18n{opencodeDir: dir}
+	configs := []TranslationConfig{{FileName: "app.json", File: target, Replacements: map[string]string{"Hello": "你好"}}}
+	if _, err := i18n.BuildTranslationBranch(context.Background(), "HEAD", configs, ApplyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// 手工提交修改了汉化后的同一行
+	writePackFile(t, dir, target, "<text>你好呀</text>\n")
+	runGit(t, dir, "commit", "-q", "-am", "manual wording")
+	tip := runGit(t, dir, "rev-parse", TranslationBranch)
+
+	// 上游修改同一行，手工提交无法迁移
+	runGit(t, dir, "checkout", "-q", "dev")
+	writePackFile(t, dir, target, "<text>Hello there</text>\n")
+	runGit(t, dir, "commit", "-q", "-am", "upstream wording")
+
+	_, err := i18n.BuildTranslationBranch(context.Background(), "dev", configs, ApplyOptions{})
+	var conflict *BranchConflictError
+	if !errors.As(err, &conflict) || conflict.Subject != "manual wording" {
+		t.Fatalf("应报告迁移冲突: %v", err)
+	}
+	if got := runGit(t, dir, "rev-parse", TranslationBranch); got != tip {
+		t.Errorf("冲突时汉化分支不应移动: %s -> %s", tip, got)
+	}
+	if branch := GitCurrentBranch(dir); branch != "dev" {
+		t.Errorf("应恢复到原来的分支: %q", branch)
+	}
+	if out := runGit(t, dir, "status", "--porcelain"); out != "" {
+		t.Errorf("工作区应恢复干净: %q", out)
+	}
+}
+
+func TestBuildTranslationBranch_CanceledKeepsBranch(t *testing.T) {
+	dir, hashes := newGitRepo(t, 2)
+	runGit(t, dir, "branch", TranslationBranch, hashes[0])
+	runGit(t, dir, "checkout", "-q", TranslationBranch)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	i18n := &I18n{opencodeDir: dir}
+	if _, err := i18n.BuildTranslationBranch(ctx, "dev", nil, ApplyOptions{}); !errors.Is(err, context.Canceled) {
+		t.Fatalf("应返回 context.Canceled: %v", err)
+	}
+	if got := runGit(t, dir, "rev-parse", TranslationBranch); got != hashes[0] {
+		t.Errorf("失败时汉化分支不应移动: %s", got)
+	}
+	if branch := GitCurrentBranch(dir); branch != TranslationBranch {
+		t.Errorf("应恢复到原来的分支: %q", branch)
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"fmt"
 	"os"
 	"path/filepath"
@@ -21,6 +22,11 @@ func NewBuilder() (*Builder, error) {
 	if err != nil {
 		return nil, err
 	}
+	return NewBuilderAt(opencodeDir)
+}
+
+// NewBuilderAt 创建在指定源码目录（如构建 worktree）中构建的构建器
+func NewBuilderAt(opencodeDir string) (*Builder, error) {
 	buildDir := filepath.Join(opencodeDir, "packages", "opencode")
 	bunPath := "bun" // 假设 bun 在 PATH 中
 
@@ -144,7 +150,8 @@ if (!isCompatible) {
 
 // InstallDependencies 安装依赖
 // 上游是 monorepo 结构，需要从仓库根目录安装以解析 workspace 依赖
-func (b *Builder) InstallDependencies(silent bool) error {
+// 安装失败或被中断时删除本次新建的 node_modules，避免下次误判为依赖已存在
+func (b *Builder) InstallDependencies(ctx context.Context, silent bool) error {
 	if !silent {
 		fmt.Println("正在安装依赖...")
 	}
@@ -160,10 +167,7 @@ func (b *Builder) InstallDependencies(silent bool) error {
 			if !silent {
 				fmt.Printf("在 monorepo 根目录安装依赖: %s\n", repoRoot)
 			}
-			if err := os.Chdir(repoRoot); err != nil {
-				return fmt.Errorf("切换到 monorepo 根目录失败: %w", err)
-			}
-			if err := ExecLive(b.bunPath, "install"); err != nil {
+			if err := b.bunInstall(ctx, repoRoot); err != nil {
 				return fmt.Errorf("monorepo 根目录 bun install 失败: %w", err)
 			}
 		} else if !silent {
@@ -180,15 +184,26 @@ func (b *Builder) InstallDependencies(silent bool) error {
 		return nil
 	}
 
-	if err := os.Chdir(b.buildDir); err != nil {
+	return b.bunInstall(ctx, b.buildDir)
+}
+
+// bunInstall 在 dir 中执行 bun install，失败时删除未完成的 node_modules
+func (b *Builder) bunInstall(ctx context.Context, dir string) error {
+	nodeModules := filepath.Join(dir, "node_modules")
+	if err := ExecLiveContext(ctx, dir, nil, b.bunPath, "install"); err != nil {
+		if Exists(nodeModules) {
+			if rmErr := os.RemoveAll(nodeModules); rmErr == nil {
+				fmt.Printf("已删除未完成的依赖目录: %s\n", nodeModules)
+			}
+		}
 		return err
 	}
-
-	return ExecLive(b.bunPath, "install")
+	return nil
 }
 
 // Build 执行构建
-func (b *Builder) Build(platform string, silent bool) error {
+// ctx 取消时终止 bun 进程并返回 ctx 的错误
+func (b *Builder) Build(ctx context.Context, platform string, silent bool) error {
 	if !silent {
 		fmt.Println("开始编译构建...")
 	}
@@ -203,7 +218,7 @@ func (b *Builder) Build(platform string, silent bool) error {
 		fmt.Println("  已应用 Bun 版本兼容性修复")
 	}
 
-	if err := b.InstallDependencies(silent); err != nil {
+	if err := b.InstallDependencies(ctx, silent); err != nil {
 		return err
 	}
 
@@ -243,17 +258,16 @@ func (b *Builder) Build(platform string, silent bool) error {
 		fmt.Printf("执行: %s %s\n", b.bunPath, strings.Join(args, " "))
 	}
 
-	if err := os.Chdir(b.buildDir); err != nil {
-		return err
-	}
-
 	// 尝试绕过 SSL 验证错误
 	// 这是一个临时修复，因为 models.dev 的证书在某些环境中可能验证失败
 	env := os.Environ()
 	env = append(env, "BUN_TLS_REJECT_UNAUTHORIZED=0")
 	env = append(env, "NODE_TLS_REJECT_UNAUTHORIZED=0")
 
-	if err := ExecLiveEnv(b.bunPath, args, env); err != nil {
+	if err := ExecLiveContext(ctx, b.buildDir, env, b.bunPath, args...); err != nil {
+		if ctx.Err() != nil {
+			return err
+		}
 		return fmt.Errorf("bun 构建脚本执行失败: %w", err)
 	}
 
@@ -0,0 +1,329 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// cloneStateFile 未完成克隆的状态文件（位于 .git 目录中），克隆完成后删除
+const cloneStateFile = "opencode-i18n-clone.json"
+
+// DefaultSparsePaths 稀疏检出时保留的目录（cone 模式，仓库根目录的文件总是检出）
+// 即 packages/opencode 及其 workspace 依赖，以及根 package.json 引用的补丁
+var DefaultSparsePaths = []string{
+	"packages/opencode",
+	"packages/plugin",
+	"packages/script",
+	"packages/sdk",
+	"packages/util",
+	"patches",
+}
+
+// CloneOptions 克隆选项
+type CloneOptions struct {
+	URL string `json:"url"`
+	// Branch 要检出的分支，为空时依次使用 dev、main 和远程默认分支
+	Branch string `json:"branch"`
+	// Depth 浅克隆深度，0 表示完整历史
+	Depth int `json:"depth,omitempty"`
+	// Sparse 只检出 SparsePaths（并按需下载文件内容）
+	Sparse bool `json:"sparse,omitempty"`
+	// SparsePaths 稀疏检出的目录，为空时使用 DefaultSparsePaths
+	SparsePaths []string `json:"sparsePaths,omitempty"`
+}
+
+// cloneBranches update 依赖的分支，按优先顺序检出（镜像可能只有其中之一）
+var cloneBranches = []string{"dev", "main"}
+
+// CloneIncomplete 判断目录中是否有被中断的克隆
+func CloneIncomplete(dir string) bool {
+	return FileExists(filepath.Join(dir, ".git", cloneStateFile))
+}
+
+// CloneRepo 以 init + fetch 的方式克隆上游仓库，支持浅克隆与稀疏检出
+// 完整克隆获取全部分支；浅克隆或稀疏检出只获取远程默认分支以及 dev、main（存在时）
+// 克隆被中断（ctx 取消）时保留目录和已下载的对象，再次调用会沿用上次的选项继续（opts.URL 用于更新 origin）
+func CloneRepo(ctx context.Context, dir string, opts CloneOptions) error {
+	statePath := filepath.Join(dir, ".git", cloneStateFile)
+
+	resume := CloneIncomplete(dir)
+	if resume {
+		var saved CloneOptions
+		if err := ReadJSON(statePath, &saved); err == nil {
+			if opts.URL != "" {
+				saved.URL = opts.URL
+			}
+			opts = saved
+		}
+		fmt.Println("检测到未完成的克隆，继续下载...")
+	} else if Exists(dir) {
+		return fmt.Errorf("目录已存在: %s", dir)
+	}
+	if opts.Sparse && len(opts.SparsePaths) == 0 {
+		opts.SparsePaths = DefaultSparsePaths
+	}
+
+	// 1. 初始化仓库并记录选项
+	fmt.Println("[1/3] 初始化仓库...")
+	if err := EnsureDir(dir); err != nil {
+		return err
+	}
+	if !DirExists(filepath.Join(dir, ".git")) {
+		if _, err := ExecInDirQuiet(dir, "git", "init", "-q"); err != nil {
+			return err
+		}
+	}
+	if err := WriteJSON(statePath, opts); err != nil {
+		return err
+	}
+	if _, err := ExecInDirQuiet(dir, "git", "remote", "get-url", "origin"); err != nil {
+		if _, err := ExecInDirQuiet(dir, "git", "remote", "add", "origin", opts.URL); err != nil {
+			return err
+		}
+	} else if _, err := ExecInDirQuiet(dir, "git", "remote", "set-url", "origin", opts.URL); err != nil {
+		return err
+	}
+	if opts.Sparse {
+		// 只下载检出需要的文件内容，其余按需获取
+		ExecInDirQuiet(dir, "git", "config", "remote.origin.promisor", "true")
+		ExecInDirQuiet(dir, "git", "config", "remote.origin.partialclonefilter", "blob:none")
+		args := append([]string{"sparse-checkout", "set", "--cone"}, opts.SparsePaths...)
+		if _, err := ExecInDirQuiet(dir, "git", args...); err != nil {
+			return fmt.Errorf("设置稀疏检出失败: %w", err)
+		}
+	}
+
+	// 2. 获取分支（git 自身输出进度）
+	wanted := cloneBranches
+	if opts.Branch != "" {
+		wanted = []string{opts.Branch}
+	}
+	head, branches, err := remoteBranches(ctx, dir, wanted)
+	if err != nil {
+		return fmt.Errorf("读取远程分支失败（再次运行可继续）: %w", err)
+	}
+	branch := head
+	for _, name := range wanted {
+		if branches[name] {
+			branch = name
+			break
+		}
+	}
+	if opts.Branch != "" && branch != opts.Branch {
+		return fmt.Errorf("远程仓库中找不到 %s 分支", opts.Branch)
+	}
+	if branch == "" {
+		return errors.New("远程仓库中找不到 dev、main 或默认分支")
+	}
+
+	refspecs := []string{"+refs/heads/*:refs/remotes/origin/*"}
+	if opts.Depth > 0 || opts.Sparse {
+		// 与 git clone --single-branch 相同，之后的 fetch 也只更新这些分支
+		refspecs = nil
+		for _, name := range sortedKeys(branches) {
+			refspecs = append(refspecs, fmt.Sprintf("+refs/heads/%s:refs/remotes/origin/%s", name, name))
+		}
+		ExecInDirQuiet(dir, "git", "config", "--unset-all", "remote.origin.fetch")
+		for _, refspec := range refspecs {
+			if _, err := ExecInDirQuiet(dir, "git", "config", "--add", "remote.origin.fetch", refspec); err != nil {
+				return err
+			}
+		}
+	}
+
+	fmt.Printf("[2/3] 获取 %s 分支", strings.Join(sortedKeys(branches), "、"))
+	if opts.Depth > 0 {
+		fmt.Printf(" (深度 %d)", opts.Depth)
+	}
+	fmt.Println("...")
+	args := append([]string{"fetch", "--progress", "origin"}, refspecs...)
+	if opts.Depth > 0 {
+		// 浅克隆时跟随全部标签会拉回完整历史
+		args = append(args, "--depth", strconv.Itoa(opts.Depth), "--no-tags")
+	} else {
+		args = append(args, "--tags")
+	}
+	if opts.Sparse {
+		args = append(args, "--filter=blob:none")
+	}
+	if err := ExecLiveContext(ctx, dir, nil, "git", args...); err != nil {
+		return fmt.Errorf("获取失败（再次运行可继续）: %w", err)
+	}
+	if head != "" {
+		ExecInDirQuiet(dir, "git", "remote", "set-head", "origin", head)
+	}
+
+	// 3. 检出
+	fmt.Println("[3/3] 检出源码...")
+	if _, err := GitResolveCommit(dir, "HEAD"); err == nil && resume {
+		// 上次已完成检出，只是未能删除状态文件
+		return os.Remove(statePath)
+	}
+	args = []string{"checkout", "-q", "-B", branch, "--track", "origin/" + branch}
+	if resume {
+		// 上次检出被中断时工作区中残留的文件不在索引中，会被视为未跟踪文件而阻止检出
+		// 仓库尚无任何提交，这些文件都来自被中断的检出，可以覆盖
+		args = append(args, "--force")
+	}
+	if err := ExecLiveContext(ctx, dir, nil, "git", args...); err != nil {
+		return fmt.Errorf("检出失败（再次运行可继续）: %w", err)
+	}
+
+	return os.Remove(statePath)
+}
+
+// remoteBranches 通过 git ls-remote 读取 origin 的默认分支，返回默认分支和 names 中实际存在的分支
+func remoteBranches(ctx context.Context, dir string, names []string) (string, map[string]bool, error) {
+	args := []string{"ls-remote", "--symref", "origin", "HEAD"}
+	for _, name := range names {
+		args = append(args, "refs/heads/"+name)
+	}
+	cmd := exec.CommandContext(ctx, "git", args...)
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", nil, ctx.Err()
+		}
+		return "", nil, err
+	}
+
+	head := ""
+	branches := make(map[string]bool)
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		switch {
+		case len(fields) == 3 && fields[0] == "ref:" && fields[2] == "HEAD":
+			// ref: refs/heads/<默认分支>	HEAD
+			head = strings.TrimPrefix(fields[1], "refs/heads/")
+			branches[head] = true
+		case len(fields) == 2 && strings.HasPrefix(fields[1], "refs/heads/"):
+			branches[strings.TrimPrefix(fields[1], "refs/heads/")] = true
+		}
+	}
+	return head, branches, nil
+}
+
+// GitIsShallow 判断仓库是否为浅克隆
+func GitIsShallow(dir string) bool {
+	out, err := ExecInDirQuiet(dir, "git", "rev-parse", "--is-shallow-repository")
+	return err == nil && out == "true"
+}
+
+// GitIsAncestor 判断 commit 是否位于 ref 的（已获取的）历史中
+func GitIsAncestor(dir, commit, ref string) bool {
+	_, err := ExecInDirQuiet(dir, "git", "merge-base", "--is-ancestor", commit, ref)
+	return err == nil
+}
+
+// commitHashPattern 提交哈希（至少 7 位）
+var commitHashPattern = regexp.MustCompile(`^[0-9a-f]{7,40}$`)
+
+// DeepenForCommit 浅克隆中找不到 ref 时逐步加深 branch 的历史，直到包含该提交
+// ref 可以是提交哈希或标签（标签按其指向的提交加深，并单独获取标签本身）；其他 ref 或仓库不是浅克隆时不做处理
+// branch 为空时使用已获取的 dev、main 或远程默认分支
+// 加深深度每次翻倍，超过 maxDeepen 后获取完整历史；完整历史中仍找不到时不报错，由 GitCheckoutRef 单独获取
+func DeepenForCommit(ctx context.Context, dir, branch, ref string) error {
+	const firstDeepen, maxDeepen = 50, 3200
+
+	if !GitIsShallow(dir) {
+		return nil
+	}
+	commit, tag := ref, ""
+	if !commitHashPattern.MatchString(ref) {
+		hash, err := remoteTagCommit(ctx, dir, ref)
+		if err != nil || hash == "" {
+			// 不是标签（如分支名），交给 GitCheckoutRef
+			return err
+		}
+		commit, tag = hash, ref
+	}
+	if branch == "" {
+		branch = fetchedBranch(dir)
+	}
+
+	for deepen := firstDeepen; ; deepen *= 2 {
+		// 稀疏克隆会按需获取单个提交对象，因此按是否位于分支历史中判断，而不是对象是否存在
+		// 不在 branch 的历史中（如已被强推覆盖）时交给 GitCheckoutRef 单独获取
+		if GitIsAncestor(dir, commit, "origin/"+branch) || !GitIsShallow(dir) {
+			break
+		}
+
+		args := []string{"fetch", "--progress", "--no-tags", "origin", branch}
+		if deepen > maxDeepen {
+			fmt.Println("正在获取完整历史...")
+			args = append(args, "--unshallow")
+		} else {
+			fmt.Printf("正在加深历史 %d 个提交以查找 %s...\n", deepen, ShortHash(ref))
+			args = append(args, "--deepen", strconv.Itoa(deepen))
+		}
+		if err := ExecLiveContext(ctx, dir, nil, "git", args...); err != nil {
+			return err
+		}
+	}
+
+	if tag == "" {
+		return nil
+	}
+	// 浅克隆不跟随标签，提交已在本地后获取标签本身只需下载标签对象
+	args := []string{"fetch", "--no-tags", "origin", fmt.Sprintf("+refs/tags/%s:refs/tags/%s", tag, tag)}
+	if !GitIsAncestor(dir, commit, "origin/"+branch) {
+		// 标签不在分支历史中，只取标签指向的提交，不拉回其完整历史
+		args = append(args, "--depth", "1")
+	}
+	return ExecLiveContext(ctx, dir, nil, "git", args...)
+}
+
+// remoteTagCommit 返回标签 tag 指向的提交哈希；本地已有该标签时不访问网络，origin 中没有该标签时返回空字符串
+func remoteTagCommit(ctx context.Context, dir, tag string) (string, error) {
+	if hash, err := GitResolveCommit(dir, "refs/tags/"+tag); err == nil {
+		return hash, nil
+	}
+
+	ref := "refs/tags/" + tag
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--tags", "origin", ref, ref+"^{}")
+	cmd.Dir = dir
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", ctx.Err()
+		}
+		return "", err
+	}
+
+	// 附注标签的 ^{} 行才是其指向的提交
+	hash := ""
+	for _, line := range strings.Split(string(out), "\n") {
+		fields := strings.Fields(line)
+		if len(fields) != 2 {
+			continue
+		}
+		switch fields[1] {
+		case ref + "^{}":
+			return fields[0], nil
+		case ref:
+			hash = fields[0]
+		}
+	}
+	return hash, nil
+}
+
+// fetchedBranch 返回已获取的远程分支中 update 使用的那个：dev、main，否则为远程默认分支
+func fetchedBranch(dir string) string {
+	for _, name := range cloneBranches {
+		if _, err := GitResolveCommit(dir, "refs/remotes/origin/"+name); err == nil {
+			return name
+		}
+	}
+	if out, err := ExecInDirQuiet(dir, "git", "symbolic-ref", "--short", "refs/remotes/origin/HEAD"); err == nil {
+		return strings.TrimPrefix(out, "origin/")
+	}
+	return cloneBranches[0]
+}
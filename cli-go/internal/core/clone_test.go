@@ -0,0 +1,185 @@
+package core
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// ========== 浅克隆与稀疏检出测试 ==========
+
+// newUpstreamRepo 创建包含 packages/opencode 和其他目录的上游仓库，返回 file:// 地址和各提交哈希
+func newUpstreamRepo(t *testing.T, commits int) (string, []string) {
+	t.Helper()
+	dir, _ := newGitRepo(t, 1)
+	runGit(t, dir, "config", "uploadpack.allowFilter", "true")
+	runGit(t, dir, "config", "uploadpack.allowAnySHA1InWant", "true")
+
+	var hashes []string
+	for n := 0; n < commits; n++ {
+		writePackFile(t, dir, "packages/opencode/src/app.tsx", string(rune('a'+n)))
+		writePackFile(t, dir, "packages/console/app.tsx", string(rune('a'+n)))
+		runGit(t, dir, "add", "-A")
+		runGit(t, dir, "commit", "-q", "-m", "upstream")
+		hashes = append(hashes, runGit(t, dir, "rev-parse", "HEAD"))
+	}
+	return "file://" + filepath.ToSlash(dir), hashes
+}
+
+func TestCloneRepo_ShallowSparse(t *testing.T) {
+	url, hashes := newUpstreamRepo(t, 5)
+	dir := filepath.Join(t.TempDir(), "opencode")
+
+	err := CloneRepo(context.Background(), dir, CloneOptions{URL: url, Depth: 1, Sparse: true, SparsePaths: []string{"packages/opencode"}})
+	if err != nil {
+		t.Fatalf("克隆失败: %v", err)
+	}
+	if CloneIncomplete(dir) {
+		t.Error("完成后应删除克隆状态")
+	}
+	if !GitIsShallow(dir) {
+		t.Error("应为浅克隆")
+	}
+	if got := GitCurrentBranch(dir); got != "dev" {
+		t.Errorf("应检出 dev 分支: %q", got)
+	}
+	if !FileExists(filepath.Join(dir, "packages/opencode/src/app.tsx")) || !FileExists(filepath.Join(dir, "file.txt")) {
+		t.Error("应检出 packages/opencode 和根目录文件")
+	}
+	if DirExists(filepath.Join(dir, "packages/console")) {
+		t.Error("稀疏检出不应包含 packages/console")
+	}
+
+	// 需要更早的提交时按需加深
+	if GitIsAncestor(dir, hashes[0], "origin/dev") {
+		t.Fatal("浅克隆中不应已有最早的提交")
+	}
+	if err := DeepenForCommit(context.Background(), dir, "dev", hashes[0]); err != nil {
+		t.Fatalf("加深失败: %v", err)
+	}
+	if !GitIsAncestor(dir, hashes[0], "origin/dev") {
+		t.Errorf("加深后应包含 %s", hashes[0])
+	}
+}
+
+func TestCloneRepo_MirrorWithoutDev(t *testing.T) {
+	url, hashes := newUpstreamRepo(t, 3)
+	upstream := filepath.FromSlash(url[len("file://"):])
+	runGit(t, upstream, "branch", "-m", "dev", "main")
+	runGit(t, upstream, "branch", "feature", hashes[0])
+
+	// 浅克隆只获取默认分支，并检出 main
+	dir := filepath.Join(t.TempDir(), "opencode")
+	if err := CloneRepo(context.Background(), dir, CloneOptions{URL: url, Depth: 1}); err != nil {
+		t.Fatalf("克隆没有 dev 分支的镜像失败: %v", err)
+	}
+	if got := GitCurrentBranch(dir); got != "main" {
+		t.Errorf("应检出 main 分支: %q", got)
+	}
+	if _, err := GitResolveCommit(dir, "refs/remotes/origin/feature"); err == nil {
+		t.Error("浅克隆不应获取其他分支")
+	}
+
+	// 按标签加深：v1 位于最早的提交上
+	if err := DeepenForCommit(context.Background(), dir, "", "v1"); err != nil {
+		t.Fatalf("按标签加深失败: %v", err)
+	}
+	tag, err := GitResolveCommit(dir, "v1")
+	if err != nil || !GitIsAncestor(dir, tag, "origin/main") {
+		t.Errorf("加深后应包含标签 v1: %q (%v)", tag, err)
+	}
+
+	// 完整克隆获取全部分支
+	full := filepath.Join(t.TempDir(), "opencode")
+	if err := CloneRepo(context.Background(), full, CloneOptions{URL: url}); err != nil {
+		t.Fatalf("完整克隆失败: %v", err)
+	}
+	if _, err := GitResolveCommit(full, "refs/remotes/origin/feature"); err != nil {
+		t.Error("完整克隆应获取全部分支")
+	}
+}
+
+func TestCloneRepo_ResumePartialCheckout(t *testing.T) {
+	url, _ := newUpstreamRepo(t, 1)
+	dir := filepath.Join(t.TempDir(), "opencode")
+
+	// 模拟检出中断：已获取 dev，工作区中残留了未写入索引的文件
+	if err := EnsureDir(dir); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "init", "-q")
+	runGit(t, dir, "remote", "add", "origin", url)
+	runGit(t, dir, "fetch", "-q", "origin")
+	if err := WriteJSON(filepath.Join(dir, ".git", cloneStateFile), CloneOptions{URL: url}); err != nil {
+		t.Fatal(err)
+	}
+	writePackFile(t, dir, "file.txt", "partial")
+
+	if err := CloneRepo(context.Background(), dir, CloneOptions{}); err != nil {
+		t.Fatalf("继续中断的检出失败: %v", err)
+	}
+	if CloneIncomplete(dir) || GitCurrentBranch(dir) != "dev" {
+		t.Error("应完成检出")
+	}
+	if out := runGit(t, dir, "status", "--porcelain"); out != "" {
+		t.Errorf("残留文件应被覆盖: %q", out)
+	}
+}
+
+func TestCloneRepo_Resume(t *testing.T) {
+	url, _ := newUpstreamRepo(t, 2)
+	dir := filepath.Join(t.TempDir(), "opencode")
+
+	// 模拟中断：状态文件存在但尚未获取
+	if err := EnsureDir(filepath.Join(dir, ".git")); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, dir, "init", "-q")
+	if err := WriteJSON(filepath.Join(dir, ".git", cloneStateFile), CloneOptions{URL: "file:///nonexistent", Depth: 1}); err != nil {
+		t.Fatal(err)
+	}
+	if !CloneIncomplete(dir) {
+		t.Fatal("应识别未完成的克隆")
+	}
+
+	// 沿用保存的深度，使用新的地址
+	if err := CloneRepo(context.Background(), dir, CloneOptions{URL: url}); err != nil {
+		t.Fatalf("继续克隆失败: %v", err)
+	}
+	if CloneIncomplete(dir) || !GitIsShallow(dir) {
+		t.Error("应完成克隆并沿用浅克隆选项")
+	}
+	if got, _ := GetGitRemoteURL(dir); got != url {
+		t.Errorf("origin 应更新为 %s: %q", url, got)
+	}
+
+	// 已完成的目录不能再次克隆
+	if err := CloneRepo(context.Background(), dir, CloneOptions{URL: url}); err == nil {
+		t.Error("目录已存在时应报错")
+	}
+	if _, err := os.Stat(filepath.Join(dir, "file.txt")); err != nil {
+		t.Error("已有内容不应被删除")
+	}
+}
+
+func TestCloneRepo_Cancelled(t *testing.T) {
+	url, _ := newUpstreamRepo(t, 1)
+	dir := filepath.Join(t.TempDir(), "opencode")
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := CloneRepo(ctx, dir, CloneOptions{URL: url}); err == nil {
+		t.Fatal("取消后应返回错误")
+	}
+	if !CloneIncomplete(dir) {
+		t.Fatal("中断的克隆应保留状态以便继续")
+	}
+
+	if err := CloneRepo(context.Background(), dir, CloneOptions{}); err != nil {
+		t.Fatalf("继续克隆失败: %v", err)
+	}
+	if CloneIncomplete(dir) {
+		t.Error("继续后应完成克隆")
+	}
+}
@@ -0,0 +1,146 @@
+package core
+
+import "sort"
+
+// ConflictKind 规则冲突类型
+type ConflictKind string
+
+const (
+	// ConflictDuplicate 同一目标文件中相同的 from 对应不同的译文
+	ConflictDuplicate ConflictKind = "duplicate"
+	// ConflictShadow 一条规则的 from 包含另一条规则的 from，应用顺序会影响结果
+	ConflictShadow ConflictKind = "shadow"
+	// ConflictChain 一条规则的译文产生了另一条规则的匹配
+	ConflictChain ConflictKind = "chain"
+)
+
+// RuleRef 指向某个配置文件中的一条替换规则
+type RuleRef struct {
+	Config *TranslationConfig
+	From   string
+	To     string
+}
+
+// Source 返回规则所在的配置文件（分类/文件名）
+func (r RuleRef) Source() string {
+	return r.Config.Category + "/" + r.Config.FileName
+}
+
+// RuleConflict 同一目标文件中两条规则之间的冲突
+type RuleConflict struct {
+	Kind   ConflictKind
+	Target string
+	A      RuleRef
+	B      RuleRef
+}
+
+// Describe 返回冲突的可读描述
+func (c RuleConflict) Describe() string {
+	switch c.Kind {
+	case ConflictDuplicate:
+		return "A 与 B 对相同原文给出不同译文"
+	case ConflictShadow:
+		return "A 的原文包含 B 的原文，先应用 B 会使 A 失配"
+	default:
+		return "A 的译文会产生 B 的新匹配"
+	}
+}
+
+// FindRuleConflicts 按目标文件分组分析全部规则，报告重复、包含遮蔽与链式匹配
+func (i *I18n) FindRuleConflicts(configs []TranslationConfig) []RuleConflict {
+	groups := make(map[string][]RuleRef)
+	relPaths := make(map[string]string)
+	var globConfigs []*TranslationConfig
+	for idx := range configs {
+		config := &configs[idx]
+		if i.isGlobConfig(*config) {
+			globConfigs = append(globConfigs, config)
+			continue
+		}
+		target := i.GetTargetFilePath(*config)
+		if target == "" {
+			continue
+		}
+		relPaths[target], _ = i.targetRelPath(*config)
+		for _, r := range config.GetReplacementsList() {
+			groups[target] = append(groups[target], RuleRef{Config: config, From: r.From, To: r.To})
+		}
+	}
+
+	// glob 配置不读取源码：与其模式匹配的单文件配置同组，相同模式的 glob 配置同组
+	for _, config := range globConfigs {
+		pattern, err := i.targetRelPath(*config)
+		if err != nil {
+			continue
+		}
+		targets := []string{i.GetTargetFilePath(*config)}
+		for target, rel := range relPaths {
+			if MatchGlob(pattern, rel) {
+				targets = append(targets, target)
+			}
+		}
+		for _, target := range targets {
+			for _, r := range config.GetReplacementsList() {
+				groups[target] = append(groups[target], RuleRef{Config: config, From: r.From, To: r.To})
+			}
+		}
+	}
+
+	targets := make([]string, 0, len(groups))
+	for target := range groups {
+		targets = append(targets, target)
+	}
+	sort.Strings(targets)
+
+	var conflicts []RuleConflict
+	for _, target := range targets {
+		rules := groups[target]
+		sort.SliceStable(rules, func(a, b int) bool {
+			if rules[a].From != rules[b].From {
+				return rules[a].From < rules[b].From
+			}
+			return rules[a].Source() < rules[b].Source()
+		})
+		conflicts = append(conflicts, findGroupConflicts(target, rules)...)
+	}
+
+	return conflicts
+}
+
+// findGroupConflicts 分析同一目标文件中的规则
+func findGroupConflicts(target string, rules []RuleRef) []RuleConflict {
+	var conflicts []RuleConflict
+
+	matchers := make([]*ruleMatcher, len(rules))
+	for idx, r := range rules {
+		matchers[idx] = newRuleMatcher(r.From)
+	}
+
+	for a := range rules {
+		for b := range rules {
+			if a == b {
+				continue
+			}
+			ra, rb := rules[a], rules[b]
+
+			if ra.From == rb.From {
+				// 每对只报告一次
+				if a < b && ra.To != rb.To {
+					conflicts = append(conflicts, RuleConflict{Kind: ConflictDuplicate, Target: target, A: ra, B: rb})
+				}
+				continue
+			}
+
+			// 先应用 B 后得到的结果与 A 的译文一致时，顺序无关紧要
+			if matchers[b].Match(ra.From) && matchers[b].ReplaceAll(ra.From, rb.To) != ra.To {
+				conflicts = append(conflicts, RuleConflict{Kind: ConflictShadow, Target: target, A: ra, B: rb})
+			}
+
+			if matchers[b].Match(ra.To) {
+				conflicts = append(conflicts, RuleConflict{Kind: ConflictChain, Target: target, A: ra, B: rb})
+			}
+		}
+	}
+
+	return conflicts
+}
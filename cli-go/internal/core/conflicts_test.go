@@ -0,0 +1,81 @@
+package core
+
+import "testing"
+
+// ========== FindRuleConflicts 测试 ==========
+
+func TestFindRuleConflicts(t *testing.T) {
+	i18n := &I18n{
+		opencodeDir: "/home/user/opencode",
+	}
+
+	configs := []TranslationConfig{
+		{
+			Category: "components",
+			FileName: "component-prompt.json",
+			File:     "src/prompt.tsx",
+			Replacements: map[string]string{
+				"Cancel":       "取消",
+				"No Servers":   "暂无服务器",
+				"Servers":      "服务器",
+				"Open Project": "打开 Project",
+			},
+		},
+		{
+			Category: "components",
+			FileName: "autocomplete.json",
+			File:     "packages/opencode/src/prompt.tsx",
+			Replacements: map[string]string{
+				"Cancel":  "撤销",
+				"Project": "项目",
+			},
+		},
+		{
+			// 不同目标文件，不应参与比较
+			Category: "dialogs",
+			FileName: "dialog-alert.json",
+			File:     "src/alert.tsx",
+			Replacements: map[string]string{
+				"Cancel": "关闭",
+			},
+		},
+	}
+
+	conflicts := i18n.FindRuleConflicts(configs)
+
+	stats := make(map[ConflictKind]int)
+	for _, c := range conflicts {
+		stats[c.Kind]++
+	}
+
+	if stats[ConflictDuplicate] != 1 {
+		t.Errorf("应有 1 处重复, got %d", stats[ConflictDuplicate])
+	}
+	// "No Servers" 包含 "Servers"，先应用短规则得到 "No 服务器"
+	// "Open Project" 包含 "Project"，先应用短规则得到 "Open 项目"
+	if stats[ConflictShadow] != 2 {
+		t.Errorf("应有 2 处遮蔽, got %d", stats[ConflictShadow])
+	}
+	// "打开 Project" 的译文会被 "Project" 规则再次匹配
+	if stats[ConflictChain] != 1 {
+		t.Errorf("应有 1 处链式匹配, got %d", stats[ConflictChain])
+	}
+}
+
+func TestFindRuleConflicts_ConsistentShadowIgnored(t *testing.T) {
+	i18n := &I18n{
+		opencodeDir: "/home/user/opencode",
+	}
+
+	configs := []TranslationConfig{{
+		File: "src/app.tsx",
+		Replacements: map[string]string{
+			`"Free"`: `"免费"`,
+			"Free":   "免费",
+		},
+	}}
+
+	if conflicts := i18n.FindRuleConflicts(configs); len(conflicts) != 0 {
+		t.Errorf("结果一致的包含关系不应报告, got %d 处", len(conflicts))
+	}
+}
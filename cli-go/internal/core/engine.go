@@ -0,0 +1,236 @@
+package core
+
+import (
+	"context"
+	"fmt"
+	"runtime"
+	"sync"
+)
+
+// Strategy 汉化应用策略
+type Strategy string
+
+const (
+	// StrategyReplace 原地替换源码中的英文
+	StrategyReplace Strategy = "replace"
+	// StrategyRuntime 改写为字典查找，运行时通过 OPENCODE_LANG 选择语言
+	StrategyRuntime Strategy = "runtime"
+)
+
+// ParseStrategy 解析应用策略名称
+func ParseStrategy(name string) (Strategy, error) {
+	switch Strategy(name) {
+	case StrategyReplace, StrategyRuntime:
+		return Strategy(name), nil
+	}
+	return "", fmt.Errorf("未知的应用策略 %q (可选 %s / %s)", name, StrategyReplace, StrategyRuntime)
+}
+
+// ApplyOptions 批量应用选项
+type ApplyOptions struct {
+	DryRun bool
+	// Pseudo 使用原文的伪本地化形式代替译文
+	Pseudo   bool
+	Strategy Strategy
+	// Workers 并发处理的目标文件数，0 表示使用 CPU 核数
+	Workers int
+	// Review 不为 nil 时逐处审查匹配多处或位于可疑位置的规则（此时按顺序处理文件）
+	Review OccurrenceReviewer
+	// Journal 不为 nil 时在写入前记录文件原内容，中断后可还原
+	Journal *FileJournal
+}
+
+// ApplyStats 批量应用统计
+type ApplyStats struct {
+	Files struct {
+		Total   int
+		Success int
+		Skipped int
+		Failed  int
+	}
+	Replacements struct {
+		Total   int
+		Success int
+		Failed  int
+	}
+}
+
+// RuntimeReport 运行时字典策略的结果
+type RuntimeReport struct {
+	ModulePath string
+	Entries    int
+	Skipped    []RuntimeSkip
+	// Missing 缺少字典条目的调用点 ("文件: ID")
+	Missing []string
+}
+
+// ApplyReport 批量应用结果
+type ApplyReport struct {
+	// Configs 实际应用的配置（伪本地化或运行时策略下为转换后的规则），与 Files 一一对应
+	Configs []TranslationConfig
+	Files   []ApplyResult
+	Stats   ApplyStats
+	Runtime *RuntimeReport
+	// Globs file 为 glob 的配置的展开结果
+	Globs []GlobExpansion
+}
+
+// ApplyAll 按选项应用一组配置
+// 配置按目标文件分组，不同文件并发处理，同一文件的配置按原顺序依次应用；
+// 结果顺序与 configs 一致，不受并发影响
+// ctx 取消时不再开始新的文件，返回已完成部分的结果和 ctx 的错误
+func (i *I18n) ApplyAll(ctx context.Context, configs []TranslationConfig, opts ApplyOptions) (*ApplyReport, error) {
+	if opts.Strategy == "" {
+		opts.Strategy = StrategyReplace
+	}
+	if i.matchers == nil {
+		i.matchers = newMatcherCache()
+	}
+
+	// glob 配置展开为每个匹配文件一份，分别应用和报告
+	configs, globs, err := i.ExpandGlobs(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 伪本地化：译文替换为原文的带标记形式，用于找出未覆盖的硬编码字符串
+	if opts.Pseudo {
+		configs = PseudoConfigs(configs)
+	}
+
+	report := &ApplyReport{Globs: globs}
+
+	var plan *RuntimePlan
+	if opts.Strategy == StrategyRuntime {
+		plan = PlanRuntimeDictionary(configs)
+		configs = plan.Configs
+		report.Runtime = &RuntimeReport{
+			ModulePath: i.RuntimeModulePath(),
+			Entries:    len(plan.Entries),
+			Skipped:    plan.Skipped,
+		}
+	}
+
+	results, done := i.applyGrouped(ctx, configs, opts)
+
+	var rewritten []string
+	seen := make(map[string]bool)
+
+	for idx, config := range configs {
+		if !done[idx] {
+			continue
+		}
+		result := results[idx]
+		report.Configs = append(report.Configs, config)
+		report.Files = append(report.Files, result)
+
+		if target := i.GetTargetFilePath(config); plan != nil && result.Replacements.Success > 0 && !seen[target] {
+			seen[target] = true
+			rewritten = append(rewritten, target)
+		}
+
+		stats := &report.Stats
+		stats.Files.Total++
+		if result.Skipped {
+			stats.Files.Skipped++
+		} else if result.Success {
+			stats.Files.Success++
+		} else {
+			stats.Files.Failed++
+		}
+		stats.Replacements.Total += result.Replacements.Total
+		stats.Replacements.Success += result.Replacements.Success
+		stats.Replacements.Failed += result.Replacements.Failed
+	}
+
+	if err := ctx.Err(); err != nil {
+		return report, err
+	}
+	if plan == nil || opts.DryRun {
+		return report, nil
+	}
+	return report, i.finishRuntime(plan, rewritten, report.Runtime, opts.Journal)
+}
+
+// applyGrouped 使用工作池按目标文件分组应用配置
+// 返回与 configs 下标对应的结果，done 标记实际处理过的配置
+func (i *I18n) applyGrouped(ctx context.Context, configs []TranslationConfig, opts ApplyOptions) ([]ApplyResult, []bool) {
+	// 按目标文件分组，保持首次出现的顺序
+	var groups [][]int
+	groupOf := make(map[string]int)
+	for idx, config := range configs {
+		target := i.GetTargetFilePath(config)
+		n, ok := groupOf[target]
+		if !ok || target == "" {
+			n = len(groups)
+			groupOf[target] = n
+			groups = append(groups, nil)
+		}
+		groups[n] = append(groups[n], idx)
+	}
+
+	workers := opts.Workers
+	if workers <= 0 {
+		workers = runtime.NumCPU()
+	}
+	if opts.Review != nil {
+		workers = 1
+	}
+	if workers > len(groups) {
+		workers = len(groups)
+	}
+
+	results := make([]ApplyResult, len(configs))
+	done := make([]bool, len(configs))
+
+	jobs := make(chan []int)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for group := range jobs {
+				for _, idx := range group {
+					results[idx] = i.applyConfig(configs[idx], opts.DryRun, opts.Review, opts.Journal)
+					done[idx] = true
+				}
+			}
+		}()
+	}
+
+	for _, group := range groups {
+		if ctx.Err() != nil {
+			break
+		}
+		jobs <- group
+	}
+	close(jobs)
+	wg.Wait()
+
+	return results, done
+}
+
+// finishRuntime 写入字典模块、添加导入并校验所有调用点都有字典条目
+func (i *I18n) finishRuntime(plan *RuntimePlan, files []string, report *RuntimeReport, journal *FileJournal) error {
+	if journal != nil {
+		for _, file := range files {
+			journal.Record(file)
+		}
+		journal.Record(i.RuntimeModulePath())
+	}
+	for _, file := range files {
+		if err := i.EnsureRuntimeImport(file); err != nil {
+			return fmt.Errorf("添加字典导入失败: %w", err)
+		}
+	}
+	if err := i.WriteRuntimeModule(plan.Entries); err != nil {
+		return fmt.Errorf("写入字典模块失败: %w", err)
+	}
+
+	missing, err := VerifyRuntimeCallSites(files, plan.Entries)
+	if err != nil {
+		return fmt.Errorf("校验调用点失败: %w", err)
+	}
+	report.Missing = missing
+	return nil
+}
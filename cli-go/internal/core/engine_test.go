@@ -0,0 +1,141 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"testing/fstest"
+)
+
+// ========== NewI18nWithOptions 测试 ==========
+
+func TestNewI18nWithOptions_PackFS(t *testing.T) {
+	pack := fstest.MapFS{
+		"config.json":           {Data: []byte(`{"modules": {"dialogs": ["dialogs/dialog-a.json"]}}`)},
+		"dialogs/dialog-a.json": {Data: []byte(`{"file": "src/a.tsx", "replacements": {"\"Hello\"": "\"你好\""}}`)},
+	}
+
+	sourceDir := t.TempDir()
+	writePackFile(t, sourceDir, "packages/opencode/src/a.tsx", `const s = "Hello"`)
+
+	i18n, err := NewI18nWithOptions(Options{Pack: pack, SourceDir: sourceDir})
+	if err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+
+	configs, err := i18n.LoadConfig()
+	if err != nil || len(configs) != 1 {
+		t.Fatalf("应从 fs.FS 读取 1 个配置, got %d (%v)", len(configs), err)
+	}
+	if configs[0].Category != "dialogs" || configs[0].ConfigPath != "dialogs/dialog-a.json" {
+		t.Errorf("配置元信息错误: %+v", configs[0])
+	}
+	if _, ok := i18n.PackDir(); ok {
+		t.Error("显式 fs.FS 不应视为外部汉化包目录")
+	}
+
+	report, err := i18n.ApplyAll(context.Background(), configs, ApplyOptions{})
+	if err != nil {
+		t.Fatalf("ApplyAll 失败: %v", err)
+	}
+	if report.Stats.Files.Success != 1 || report.Stats.Replacements.Success != 1 {
+		t.Errorf("统计错误: %+v", report.Stats)
+	}
+	if content := readFileString(t, sourceDir, "packages/opencode/src/a.tsx"); content != `const s = "你好"` {
+		t.Errorf("文件内容不正确, got %q", content)
+	}
+}
+
+func TestNewI18nWithOptions_MissingManifest(t *testing.T) {
+	if _, err := NewI18nWithOptions(Options{Pack: fstest.MapFS{}}); err == nil {
+		t.Error("缺少 config.json 时应返回错误")
+	}
+}
+
+func TestNewI18nWithOptions_EmbeddedDefault(t *testing.T) {
+	i18n, err := NewI18nWithOptions(Options{})
+	if err != nil {
+		t.Fatalf("创建失败: %v", err)
+	}
+	configs, err := i18n.LoadConfig()
+	if err != nil || len(configs) == 0 {
+		t.Errorf("应读取内置汉化包, got %d (%v)", len(configs), err)
+	}
+}
+
+// ========== ApplyAll 测试 ==========
+
+func TestApplyAll_Canceled(t *testing.T) {
+	i18n := &I18n{opencodeDir: t.TempDir()}
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	report, err := i18n.ApplyAll(ctx, []TranslationConfig{{File: "a.tsx"}}, ApplyOptions{})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("应返回 context.Canceled, got %v", err)
+	}
+	if report == nil || len(report.Files) != 0 {
+		t.Errorf("取消后不应处理文件: %+v", report)
+	}
+}
+
+func TestApplyAll_ParallelOrderAndSharedTarget(t *testing.T) {
+	tmpDir := t.TempDir()
+	var configs []TranslationConfig
+	for n := 0; n < 20; n++ {
+		file := fmt.Sprintf("packages/opencode/src/f%02d.tsx", n)
+		writePackFile(t, tmpDir, file, `"Open" "Close"`)
+		configs = append(configs, TranslationConfig{
+			File:         file,
+			Replacements: map[string]string{`"Open"`: `"打开"`},
+		})
+	}
+	// 同一目标文件的第二个配置必须在第一个之后应用
+	configs = append(configs, TranslationConfig{
+		File:         "packages/opencode/src/f00.tsx",
+		Replacements: map[string]string{`"Close"`: `"关闭"`, `"打开"`: `"开启"`},
+	})
+
+	i18n := &I18n{opencodeDir: tmpDir}
+	report, err := i18n.ApplyAll(context.Background(), configs, ApplyOptions{Workers: 4})
+	if err != nil {
+		t.Fatalf("ApplyAll 失败: %v", err)
+	}
+
+	for n, config := range report.Configs {
+		if config.File != configs[n].File {
+			t.Fatalf("结果顺序应与输入一致: %d %s != %s", n, config.File, configs[n].File)
+		}
+	}
+	if report.Stats.Replacements.Success != 22 {
+		t.Errorf("替换统计错误: %+v", report.Stats)
+	}
+	if content := readFileString(t, tmpDir, "packages/opencode/src/f00.tsx"); content != `"开启" "关闭"` {
+		t.Errorf("同一文件的配置应依次应用, got %q", content)
+	}
+}
+
+func TestApplyConfig_LongestRuleFirst(t *testing.T) {
+	// 较长的规则优先，结果不随 map 遍历顺序变化
+	for n := 0; n < 5; n++ {
+		tmpDir := t.TempDir()
+		writePackFile(t, tmpDir, "packages/opencode/src/a.tsx", `"Model cycle"`)
+
+		i18n := &I18n{opencodeDir: tmpDir}
+		result := i18n.ApplyConfig(TranslationConfig{
+			File: "src/a.tsx",
+			Replacements: map[string]string{
+				"Model":       "模型",
+				"Model cycle": "模型循环",
+			},
+		}, false)
+
+		if content := readFileString(t, tmpDir, "packages/opencode/src/a.tsx"); content != `"模型循环"` {
+			t.Fatalf("较长的规则应优先生效, got %q", content)
+		}
+		if len(result.Missed) != 1 || result.Missed[0] != "Model" {
+			t.Fatalf("已被较长规则替换的短规则应计为未匹配: %v", result.Missed)
+		}
+	}
+}
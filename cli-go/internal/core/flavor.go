@@ -0,0 +1,159 @@
+package core
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// FlavorNone 表示不使用任何风味
+const FlavorNone = "none"
+
+// Flavor 汉化风味：叠加在汉化包译文上的术语替换（如保留 Provider、Model 等英文术语）
+type Flavor struct {
+	Description string `json:"description"`
+	// Terms 译文中的术语 -> 替换文本
+	Terms map[string]string `json:"terms"`
+}
+
+// SelectModules 按模块筛选配置，only 为空表示全部模块
+// 模块名为 config.json modules 的键，配置属于哪些模块以 modules 中列出的文件为准；
+// 未列入任何模块的配置不会被 only 选中，未知模块名返回错误
+func (i *I18n) SelectModules(configs []TranslationConfig, only, exclude []string) ([]TranslationConfig, error) {
+	if len(only) == 0 && len(exclude) == 0 {
+		return configs, nil
+	}
+
+	manifest, err := i.LoadManifest()
+	if err != nil {
+		return nil, fmt.Errorf("读取 %s 失败: %w", ManifestFileName, err)
+	}
+	// 配置文件（相对汉化包根目录）-> 所属模块
+	membership := make(map[string][]string)
+	for _, module := range sortedKeys(manifest.Modules) {
+		for _, file := range manifest.Modules[module] {
+			membership[file] = append(membership[file], module)
+		}
+	}
+
+	toSet := func(names []string) (map[string]bool, error) {
+		set := make(map[string]bool, len(names))
+		for _, name := range names {
+			name = strings.TrimSpace(name)
+			if name == "" {
+				continue
+			}
+			if _, ok := manifest.Modules[name]; !ok {
+				return nil, fmt.Errorf("未知模块 %q (可用: %s)", name, strings.Join(sortedKeys(manifest.Modules), ", "))
+			}
+			set[name] = true
+		}
+		return set, nil
+	}
+	onlySet, err := toSet(only)
+	if err != nil {
+		return nil, err
+	}
+	excludeSet, err := toSet(exclude)
+	if err != nil {
+		return nil, err
+	}
+
+	inAny := func(modules []string, set map[string]bool) bool {
+		for _, module := range modules {
+			if set[module] {
+				return true
+			}
+		}
+		return false
+	}
+
+	var selected []TranslationConfig
+	for _, config := range configs {
+		modules := membership[ruleConfigName(config)+".json"]
+		if len(onlySet) > 0 && !inAny(modules, onlySet) {
+			continue
+		}
+		if inAny(modules, excludeSet) {
+			continue
+		}
+		selected = append(selected, config)
+	}
+	return selected, nil
+}
+
+// ApplyFlavor 返回叠加指定风味后的配置副本，name 为空或 none 时原样返回
+func (i *I18n) ApplyFlavor(configs []TranslationConfig, name string) ([]TranslationConfig, error) {
+	if name == "" || name == FlavorNone {
+		return configs, nil
+	}
+
+	manifest, err := i.LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+	flavor, ok := manifest.Flavors[name]
+	if !ok {
+		available := sortedKeys(manifest.Flavors)
+		if len(available) == 0 {
+			return nil, fmt.Errorf("汉化包未定义风味 %q", name)
+		}
+		return nil, fmt.Errorf("未知风味 %q (可用: %s)", name, strings.Join(available, ", "))
+	}
+
+	// 长术语优先，避免被其中的短术语截断
+	terms := sortedKeys(flavor.Terms)
+	sort.SliceStable(terms, func(a, b int) bool { return len(terms[a]) > len(terms[b]) })
+
+	flavored := make([]TranslationConfig, len(configs))
+	for n, config := range configs {
+		replacements := make(map[string]string, len(config.Replacements))
+		for from, to := range config.Replacements {
+			for _, term := range terms {
+				to = replaceTerm(to, term, flavor.Terms[term])
+			}
+			replacements[from] = to
+		}
+		config.Replacements = replacements
+		flavored[n] = config
+	}
+	return flavored, nil
+}
+
+// replaceTerm 替换译文中的术语，拉丁文字与汉字相邻时补一个空格（如 "选择模型" -> "选择 Model"）
+func replaceTerm(text, term, replacement string) string {
+	if term == "" || !strings.Contains(text, term) {
+		return text
+	}
+
+	var b strings.Builder
+	for {
+		idx := strings.Index(text, term)
+		if idx < 0 {
+			b.WriteString(text)
+			return b.String()
+		}
+		before, rest := text[:idx], text[idx+len(term):]
+		b.WriteString(before)
+
+		prev, _ := utf8.DecodeLastRuneInString(before)
+		first, _ := utf8.DecodeRuneInString(replacement)
+		if unicode.Is(unicode.Han, prev) && isLatinRune(first) {
+			b.WriteByte(' ')
+		}
+		b.WriteString(replacement)
+		last, _ := utf8.DecodeLastRuneInString(replacement)
+		next, _ := utf8.DecodeRuneInString(rest)
+		if isLatinRune(last) && unicode.Is(unicode.Han, next) {
+			b.WriteByte(' ')
+		}
+		text = rest
+	}
+}
+
+// isLatinRune 判断是否为 ASCII 字母或数字
+func isLatinRune(r rune) bool {
+	return r < utf8.RuneSelf && (unicode.IsLetter(r) || unicode.IsDigit(r))
+}
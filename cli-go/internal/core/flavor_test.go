@@ -0,0 +1,184 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+	"testing"
+)
+
+// newFlavorPack 创建带模块和风味定义的临时汉化包
+func newFlavorPack(t *testing.T) *I18n {
+	t.Helper()
+	packDir := t.TempDir()
+	writePackFile(t, packDir, "config.json", `{
+  "modules": {
+    "dialogs": ["dialogs/dialog-model.json"],
+    "routes": ["routes/route-home.json"],
+    "root": ["app.json"]
+  },
+  "flavors": {
+    "keep-terms": {
+      "description": "保留术语",
+      "terms": {"模型": "Model", "提供商": "Provider", "MCP 服务器": "MCP Server", "服务器": "服务端"}
+    }
+  }
+}`)
+	writePackFile(t, packDir, "dialogs/dialog-model.json", `{"file": "src/model.tsx", "replacements": {"\"Select model\"": "\"选择模型\"", "Connect provider": "连接提供商以继续"}}`)
+	writePackFile(t, packDir, "routes/route-home.json", `{"file": "src/home.tsx", "replacements": {"MCP servers": "MCP 服务器", "Models": "模型"}}`)
+	writePackFile(t, packDir, "app.json", `{"file": "src/app.tsx", "replacements": {"Server": "服务器"}}`)
+	return &I18n{i18nDir: packDir}
+}
+
+// ========== SelectModules 测试 ==========
+
+func TestSelectModules(t *testing.T) {
+	i18n := newFlavorPack(t)
+	configs, err := i18n.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig 失败: %v", err)
+	}
+
+	categories := func(list []TranslationConfig) map[string]bool {
+		set := make(map[string]bool)
+		for _, c := range list {
+			set[c.Category] = true
+		}
+		return set
+	}
+
+	only, err := i18n.SelectModules(configs, []string{"dialogs", "root"}, nil)
+	if err != nil {
+		t.Fatalf("SelectModules 失败: %v", err)
+	}
+	if got := categories(only); len(got) != 2 || !got["dialogs"] || !got["root"] {
+		t.Errorf("--only 结果错误: %v", got)
+	}
+
+	excluded, err := i18n.SelectModules(configs, nil, []string{"routes"})
+	if err != nil {
+		t.Fatalf("SelectModules 失败: %v", err)
+	}
+	if got := categories(excluded); len(got) != 2 || got["routes"] {
+		t.Errorf("--exclude 结果错误: %v", got)
+	}
+
+	if _, err := i18n.SelectModules(configs, []string{"dialog"}, nil); err == nil {
+		t.Error("未知模块应返回错误")
+	}
+}
+
+func TestSelectModules_ManifestMembership(t *testing.T) {
+	// 模块名与目录无关，按 modules 中列出的文件归属
+	packDir := t.TempDir()
+	writePackFile(t, packDir, "config.json", `{
+  "modules": {
+    "core": ["dialogs/dialog-model.json", "app.json"],
+    "extras": ["dialogs/dialog-theme.json"]
+  }
+}`)
+	writePackFile(t, packDir, "dialogs/dialog-model.json", `{"file": "src/model.tsx", "replacements": {"Model": "模型"}}`)
+	writePackFile(t, packDir, "dialogs/dialog-theme.json", `{"file": "src/theme.tsx", "replacements": {"Theme": "主题"}}`)
+	writePackFile(t, packDir, "app.json", `{"file": "src/app.tsx", "replacements": {"Server": "服务器"}}`)
+	writePackFile(t, packDir, "unlisted.json", `{"file": "src/x.tsx", "replacements": {"X": "叉"}}`)
+	i18n := &I18n{i18nDir: packDir}
+	configs, err := i18n.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig 失败: %v", err)
+	}
+
+	names := func(list []TranslationConfig) string {
+		var out []string
+		for _, c := range list {
+			out = append(out, ruleConfigName(c))
+		}
+		sort.Strings(out)
+		return strings.Join(out, ",")
+	}
+
+	only, err := i18n.SelectModules(configs, []string{"core"}, nil)
+	if err != nil {
+		t.Fatalf("SelectModules 失败: %v", err)
+	}
+	if got := names(only); got != "app,dialogs/dialog-model" {
+		t.Errorf("--only 结果错误: %s", got)
+	}
+
+	excluded, err := i18n.SelectModules(configs, nil, []string{"extras"})
+	if err != nil {
+		t.Fatalf("SelectModules 失败: %v", err)
+	}
+	if got := names(excluded); got != "app,dialogs/dialog-model,unlisted" {
+		t.Errorf("--exclude 结果错误: %s", got)
+	}
+
+	// 目录名不是模块名
+	if _, err := i18n.SelectModules(configs, []string{"dialogs"}, nil); err == nil {
+		t.Error("未在 modules 中声明的名称应返回错误")
+	}
+}
+
+// ========== ApplyFlavor 测试 ==========
+
+func TestApplyFlavor(t *testing.T) {
+	i18n := newFlavorPack(t)
+	configs, err := i18n.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig 失败: %v", err)
+	}
+
+	flavored, err := i18n.ApplyFlavor(configs, "keep-terms")
+	if err != nil {
+		t.Fatalf("ApplyFlavor 失败: %v", err)
+	}
+
+	want := map[string]string{
+		`"Select model"`:   `"选择 Model"`,
+		"Connect provider": "连接 Provider 以继续",
+		"MCP servers":      "MCP Server",
+		"Models":           "Model",
+		"Server":           "服务端",
+	}
+	for _, config := range flavored {
+		for from, to := range config.Replacements {
+			if to != want[from] {
+				t.Errorf("%q: got %q, want %q", from, to, want[from])
+			}
+		}
+	}
+
+	// 原配置不应被修改
+	for _, config := range configs {
+		if config.Category == "root" && config.Replacements["Server"] != "服务器" {
+			t.Error("ApplyFlavor 修改了原配置")
+		}
+	}
+
+	if same, err := i18n.ApplyFlavor(configs, FlavorNone); err != nil || len(same) != len(configs) {
+		t.Errorf("none 应原样返回: %v", err)
+	}
+	if _, err := i18n.ApplyFlavor(configs, "missing"); err == nil {
+		t.Error("未知风味应返回错误")
+	}
+}
+
+// ========== Settings 测试 ==========
+
+func TestSettings_RoundTrip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", SettingsFileName)
+	t.Setenv("OPENCODE_I18N_SETTINGS", path)
+
+	settings, err := LoadSettings()
+	if err != nil || settings.Flavor != "" {
+		t.Fatalf("不存在的设置文件应返回空设置: %+v, %v", settings, err)
+	}
+
+	settings.Flavor = "keep-terms"
+	if err := SaveSettings(settings); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+	loaded, err := LoadSettings()
+	if err != nil || loaded.Flavor != "keep-terms" {
+		t.Errorf("重新加载错误: %+v, %v", loaded, err)
+	}
+}
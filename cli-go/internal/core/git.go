@@ -36,14 +36,6 @@ func GitPull(dir string) error {
 	return ExecInDir(dir, "git", "pull")
 }
 
-// GitClone 克隆仓库到指定目录
-// url: 仓库地址
-// dir: 目标目录
-func GitClone(url, dir string) error {
-	fmt.Printf("正在克隆仓库 %s...\n", url)
-	return ExecLive("git", "clone", url, dir)
-}
-
 // GitCheckout 切换到指定分支
 // dir: Git 仓库目录
 // branch: 目标分支名
@@ -52,20 +44,41 @@ func GitCheckout(dir, branch string) error {
 	return ExecInDir(dir, "git", "checkout", branch)
 }
 
+// ShortHash 返回提交哈希的前 8 位，用于输出
+func ShortHash(hash string) string {
+	if len(hash) > 8 {
+		return hash[:8]
+	}
+	return hash
+}
+
 // GitStash 暂存当前工作区更改
 // dir: Git 仓库目录
 // 如果没有更改则静默跳过
 func GitStash(dir string) error {
+	_, err := GitStashChanges(dir)
+	return err
+}
+
+// GitStashChanges 暂存当前工作区更改，返回是否确实创建了暂存（用于中断后恢复）
+// dir: Git 仓库目录
+func GitStashChanges(dir string) (bool, error) {
 	fmt.Println("正在暂存更改...")
 	// 检查是否有更改
 	out, err := ExecInDirQuiet(dir, "git", "status", "--porcelain")
 	if err != nil {
-		return err
+		return false, err
 	}
 	if strings.TrimSpace(out) == "" {
-		return nil // 无更改，跳过
+		return false, nil // 无更改，跳过
+	}
+	// 只有未跟踪文件时 git stash 不会创建暂存，按 refs/stash 是否变化判断
+	before, _ := GitResolveCommit(dir, "refs/stash")
+	if err := ExecInDir(dir, "git", "stash"); err != nil {
+		return false, err
 	}
-	return ExecInDir(dir, "git", "stash")
+	after, _ := GitResolveCommit(dir, "refs/stash")
+	return after != "" && after != before, nil
 }
 
 // GitStashPop 恢复暂存的更改
@@ -94,3 +107,57 @@ func CleanRepo(dir string) error {
 	// git clean -fd
 	return ExecInDir(dir, "git", "clean", "-fd")
 }
+
+// GitResolveCommit 将分支、标签或提交哈希解析为完整提交哈希
+// dir: Git 仓库目录
+func GitResolveCommit(dir, ref string) (string, error) {
+	return ExecInDirQuiet(dir, "git", "rev-parse", "--verify", "--quiet", ref+"^{commit}")
+}
+
+// GitAheadBehind 统计 head 相对 base 领先和落后的提交数
+// dir: Git 仓库目录
+func GitAheadBehind(dir, base, head string) (ahead, behind int, err error) {
+	out, err := ExecInDirQuiet(dir, "git", "rev-list", "--left-right", "--count", base+"..."+head)
+	if err != nil {
+		return 0, 0, err
+	}
+	if _, err := fmt.Sscanf(out, "%d %d", &behind, &ahead); err != nil {
+		return 0, 0, fmt.Errorf("无法解析 rev-list 输出 %q: %w", out, err)
+	}
+	return ahead, behind, nil
+}
+
+// GitCheckoutRef 切换到指定的分支、标签或提交
+// dir: Git 仓库目录
+// 远程分支切换到本地同名分支并拉取最新；标签和提交以分离 HEAD 方式检出，本地不存在时按需从 origin 获取
+func GitCheckoutRef(dir, ref string) error {
+	if _, err := GitResolveCommit(dir, "refs/remotes/origin/"+ref); err == nil {
+		if err := GitCheckout(dir, ref); err != nil {
+			fmt.Printf("正在创建本地 %s 分支...\n", ref)
+			if err := ExecInDir(dir, "git", "checkout", "-b", ref, "origin/"+ref); err != nil {
+				return err
+			}
+		}
+		return GitPull(dir)
+	}
+
+	if _, err := GitResolveCommit(dir, ref); err != nil {
+		// 提交哈希可能不在已获取的分支上（如已被强推覆盖），单独获取
+		fmt.Printf("正在获取 %s...\n", ref)
+		args := []string{"fetch", "origin", ref}
+		if GitIsShallow(dir) {
+			// 浅克隆中只取该提交本身，不拉回其完整历史
+			args = append(args, "--depth", "1")
+		}
+		if err := ExecInDir(dir, "git", args...); err != nil {
+			return fmt.Errorf("远程仓库中找不到 %s: %w", ref, err)
+		}
+		if _, err := GitResolveCommit(dir, ref); err != nil {
+			// 通过哈希获取时只有 FETCH_HEAD 指向该提交
+			ref = "FETCH_HEAD"
+		}
+	}
+
+	fmt.Printf("正在检出 %s...\n", ref)
+	return ExecInDir(dir, "git", "checkout", "--detach", ref)
+}
@@ -0,0 +1,87 @@
+package core
+
+import (
+	"os/exec"
+	"strings"
+	"testing"
+)
+
+// runGit 在测试仓库中执行 git 命令，失败时终止测试
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	cmd.Env = append(cmd.Environ(),
+		"GIT_AUTHOR_NAME=test", "GIT_AUTHOR_EMAIL=test@example.com",
+		"GIT_COMMITTER_NAME=test", "GIT_COMMITTER_EMAIL=test@example.com",
+	)
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v 失败: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+// newGitRepo 创建带若干提交的临时仓库，返回目录和各提交哈希
+func newGitRepo(t *testing.T, commits int) (string, []string) {
+	t.Helper()
+	if _, err := exec.LookPath("git"); err != nil {
+		t.Skip("未安装 git")
+	}
+
+	dir := t.TempDir()
+	runGit(t, dir, "init", "-q", "-b", "dev")
+	var hashes []string
+	for n := 0; n < commits; n++ {
+		writePackFile(t, dir, "file.txt", strings.Repeat("x", n+1))
+		runGit(t, dir, "add", "file.txt")
+		runGit(t, dir, "commit", "-q", "-m", "commit")
+		hashes = append(hashes, runGit(t, dir, "rev-parse", "HEAD"))
+	}
+	runGit(t, dir, "tag", "v1", hashes[0])
+	return dir, hashes
+}
+
+// ========== 支持版本测试 ==========
+
+func TestGetSupportedStatus(t *testing.T) {
+	dir, hashes := newGitRepo(t, 3)
+
+	status := GetSupportedStatus(dir, hashes[0])
+	if !status.Known || status.Ahead != 2 || status.Behind != 0 {
+		t.Errorf("应领先 2 个提交: %+v", status)
+	}
+	if status.Commit != hashes[0][:8] {
+		t.Errorf("应记录短哈希: %q", status.Commit)
+	}
+
+	if status := GetSupportedStatus(dir, hashes[2]); !status.OnSupported() {
+		t.Errorf("应位于支持版本: %+v", status)
+	}
+
+	if status := GetSupportedStatus(dir, strings.Repeat("a", 40)); status.Known {
+		t.Errorf("不存在的提交应为未知: %+v", status)
+	}
+	if status := GetSupportedStatus(dir, ""); status.Summary() != "汉化包未声明支持版本" {
+		t.Errorf("未声明时描述错误: %s", status.Summary())
+	}
+}
+
+func TestGitCheckoutRef_TagAndCommit(t *testing.T) {
+	dir, hashes := newGitRepo(t, 3)
+
+	if err := GitCheckoutRef(dir, "v1"); err != nil {
+		t.Fatalf("检出标签失败: %v", err)
+	}
+	if head, _ := GitResolveCommit(dir, "HEAD"); head != hashes[0] {
+		t.Errorf("HEAD 应为标签提交, got %s", head)
+	}
+
+	if err := GitCheckoutRef(dir, hashes[1]); err != nil {
+		t.Fatalf("检出提交失败: %v", err)
+	}
+	status := GetSupportedStatus(dir, hashes[2])
+	if status.Ahead != 0 || status.Behind != 1 {
+		t.Errorf("应落后支持版本 1 个提交: %+v", status)
+	}
+}
@@ -0,0 +1,140 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// DefaultGlobMaxMatches glob 配置未指定 maxMatches 时预期的最大匹配文件数
+const DefaultGlobMaxMatches = 20
+
+// GlobExpansion glob 配置的展开结果
+type GlobExpansion struct {
+	Config TranslationConfig
+	// Files 匹配的文件（相对源码根目录，/ 分隔）
+	Files []string
+	// Warning 匹配数为 0 或超过预期上限时的提示
+	Warning string
+}
+
+// IsGlobPattern 判断 file 字段是否含有 glob 元字符
+// 文件名本身含 [ 等字符时（如 routes/[id].tsx）可用 \ 转义，或由 isGlobConfig 按文件是否存在判断
+func IsGlobPattern(file string) bool {
+	return strings.ContainsAny(file, "*?[")
+}
+
+// isGlobConfig 判断配置的 file 是否按 glob 展开：含 glob 元字符且不是已存在的文件
+func (i *I18n) isGlobConfig(config TranslationConfig) bool {
+	if !IsGlobPattern(config.File) {
+		return false
+	}
+	return !FileExists(i.GetTargetFilePath(config))
+}
+
+// ExpandGlobs 将 file 为 glob 的配置展开为每个匹配文件一份的配置，其余配置原样保留
+// 展开后的配置保持原有顺序，同一 glob 的文件按路径排序；未匹配任何文件的 glob 配置被移除并给出警告
+func (i *I18n) ExpandGlobs(configs []TranslationConfig) ([]TranslationConfig, []GlobExpansion, error) {
+	var expanded []TranslationConfig
+	var globs []GlobExpansion
+
+	for _, config := range configs {
+		if !i.isGlobConfig(config) {
+			expanded = append(expanded, config)
+			continue
+		}
+
+		root, err := i.targetRoot(config)
+		if err != nil {
+			globs = append(globs, GlobExpansion{Config: config, Warning: err.Error()})
+			continue
+		}
+		files, err := i.globFiles(path.Join(root, filepath.ToSlash(config.File)))
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s: %w", config.FileName, err)
+		}
+
+		expansion := GlobExpansion{Config: config, Files: files}
+		limit := config.MaxMatches
+		if limit <= 0 {
+			limit = DefaultGlobMaxMatches
+		}
+		switch {
+		case len(files) == 0:
+			expansion.Warning = fmt.Sprintf("%s 未匹配任何文件", config.File)
+		case len(files) > limit:
+			expansion.Warning = fmt.Sprintf("%s 匹配 %d 个文件，超过预期上限 %d (可用 maxMatches 调整)", config.File, len(files), limit)
+		}
+		globs = append(globs, expansion)
+
+		for _, file := range files {
+			concrete := config
+			concrete.Glob = config.File
+			concrete.File = file
+			if root != "" {
+				concrete.File = strings.TrimPrefix(file, root+"/")
+			}
+			concrete.Occurrences = fileOccurrences(config, concrete.File)
+			concrete.FileOccurrences = nil
+			expanded = append(expanded, concrete)
+		}
+	}
+
+	return expanded, globs, nil
+}
+
+// fileOccurrences 返回 glob 配置在展开出的文件 file 上生效的匹配过滤器
+func fileOccurrences(config TranslationConfig, file string) map[string][]int {
+	perFile := config.FileOccurrences[file]
+	if len(perFile) == 0 {
+		return config.Occurrences
+	}
+	merged := make(map[string][]int, len(config.Occurrences)+len(perFile))
+	for from, indexes := range config.Occurrences {
+		merged[from] = indexes
+	}
+	for from, indexes := range perFile {
+		merged[from] = indexes
+	}
+	return merged
+}
+
+// globFiles 列出源码目录中匹配 pattern（相对源码根目录）的文件，跳过 node_modules 和隐藏目录
+func (i *I18n) globFiles(pattern string) ([]string, error) {
+	// 从第一个含通配符的目录段之前开始遍历
+	parts := strings.Split(pattern, "/")
+	static := 0
+	for static < len(parts)-1 && !IsGlobPattern(parts[static]) {
+		static++
+	}
+	base := filepath.Join(i.opencodeDir, filepath.FromSlash(path.Join(parts[:static]...)))
+	if !DirExists(base) {
+		return nil, nil
+	}
+
+	var files []string
+	err := filepath.WalkDir(base, func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != base && (d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".")) {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		rel, err := filepath.Rel(i.opencodeDir, p)
+		if err != nil {
+			return err
+		}
+		if rel = filepath.ToSlash(rel); MatchGlob(pattern, rel) {
+			files = append(files, rel)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
@@ -0,0 +1,155 @@
+package core
+
+import (
+	"context"
+	"strings"
+	"testing"
+)
+
+// ========== glob 配置测试 ==========
+
+func TestExpandGlobs(t *testing.T) {
+	srcDir := t.TempDir()
+	for _, name := range []string{"dialog-a.tsx", "dialog-b.tsx", "toast.tsx"} {
+		writePackFile(t, srcDir, "packages/opencode/src/ui/"+name, `<text>Cancel</text>`)
+	}
+	writePackFile(t, srcDir, "packages/opencode/src/ui/node_modules/dialog-x.tsx", `Cancel`)
+
+	i18n := &I18n{opencodeDir: srcDir}
+	configs := []TranslationConfig{
+		{FileName: "plain.json", File: "src/ui/toast.tsx"},
+		{FileName: "shared.json", File: "src/ui/dialog-*.tsx"},
+		{FileName: "none.json", File: "src/missing/*.tsx"},
+		{FileName: "wide.json", File: "src/**/*.tsx", MaxMatches: 2},
+	}
+
+	expanded, globs, err := i18n.ExpandGlobs(configs)
+	if err != nil {
+		t.Fatalf("ExpandGlobs 失败: %v", err)
+	}
+
+	var files []string
+	for _, config := range expanded {
+		files = append(files, config.FileName+":"+config.File)
+	}
+	want := "plain.json:src/ui/toast.tsx shared.json:src/ui/dialog-a.tsx shared.json:src/ui/dialog-b.tsx " +
+		"wide.json:src/ui/dialog-a.tsx wide.json:src/ui/dialog-b.tsx wide.json:src/ui/toast.tsx"
+	if got := strings.Join(files, " "); got != want {
+		t.Errorf("展开结果错误:\ngot  %s\nwant %s", got, want)
+	}
+	if expanded[1].Glob != "src/ui/dialog-*.tsx" {
+		t.Errorf("应记录原始模式, got %q", expanded[1].Glob)
+	}
+
+	if len(globs) != 3 {
+		t.Fatalf("应有 3 个 glob 配置, got %d", len(globs))
+	}
+	if globs[0].Warning != "" || len(globs[0].Files) != 2 {
+		t.Errorf("shared.json 展开错误: %+v", globs[0])
+	}
+	if !strings.Contains(globs[1].Warning, "未匹配") {
+		t.Errorf("零匹配应警告, got %q", globs[1].Warning)
+	}
+	if !strings.Contains(globs[2].Warning, "超过预期上限 2") {
+		t.Errorf("超出上限应警告, got %q", globs[2].Warning)
+	}
+}
+
+func TestApplyAll_GlobPerFileResults(t *testing.T) {
+	srcDir := t.TempDir()
+	writePackFile(t, srcDir, "packages/opencode/src/ui/dialog-a.tsx", `<text>Cancel</text>`)
+	writePackFile(t, srcDir, "packages/opencode/src/ui/dialog-b.tsx", `<text>Confirm</text>`)
+
+	i18n := &I18n{opencodeDir: srcDir}
+	configs := []TranslationConfig{{
+		FileName:     "shared.json",
+		File:         "src/ui/dialog-*.tsx",
+		Replacements: map[string]string{"Cancel": "取消", "Confirm": "确认"},
+	}}
+
+	report, err := i18n.ApplyAll(context.Background(), configs, ApplyOptions{})
+	if err != nil {
+		t.Fatalf("ApplyAll 失败: %v", err)
+	}
+	if len(report.Files) != 2 || report.Stats.Files.Success != 2 {
+		t.Fatalf("应按文件报告 2 个结果, got %+v", report.Stats.Files)
+	}
+	for n, result := range report.Files {
+		if result.Replacements.Success != 1 {
+			t.Errorf("%s: 应成功 1 条规则, got %+v", report.Configs[n].File, result.Replacements)
+		}
+	}
+	if got := readFileString(t, srcDir, "packages/opencode/src/ui/dialog-b.tsx"); got != `<text>确认</text>` {
+		t.Errorf("dialog-b.tsx 内容错误: %q", got)
+	}
+}
+
+func TestFindRuleConflicts_GlobAgainstFile(t *testing.T) {
+	i18n := &I18n{opencodeDir: t.TempDir()}
+	configs := []TranslationConfig{
+		{FileName: "shared.json", File: "src/ui/dialog-*.tsx", Replacements: map[string]string{"Cancel": "取消"}},
+		{FileName: "dialog-a.json", File: "src/ui/dialog-a.tsx", Replacements: map[string]string{"Cancel": "撤销"}},
+		{FileName: "toast.json", File: "src/ui/toast.tsx", Replacements: map[string]string{"Cancel": "关闭"}},
+	}
+
+	conflicts := i18n.FindRuleConflicts(configs)
+	if len(conflicts) != 1 || conflicts[0].Kind != ConflictDuplicate {
+		t.Fatalf("应只报告 glob 与 dialog-a.json 的重复, got %+v", conflicts)
+	}
+}
+
+func TestExpandGlobs_LiteralBrackets(t *testing.T) {
+	srcDir := t.TempDir()
+	writePackFile(t, srcDir, "packages/opencode/src/routes/[id].tsx", `"Open"`)
+	writePackFile(t, srcDir, "packages/opencode/src/routes/i.tsx", `"Open"`)
+
+	i18n := &I18n{opencodeDir: srcDir}
+	configs := []TranslationConfig{
+		// 存在的文件按字面路径处理，不会匹配到 routes/i.tsx
+		{FileName: "literal.json", File: "src/routes/[id].tsx"},
+		// 转义后作为 glob 只匹配字面文件
+		{FileName: "escaped.json", File: `src/routes/\[id\].tsx`},
+	}
+
+	expanded, globs, err := i18n.ExpandGlobs(configs)
+	if err != nil {
+		t.Fatalf("ExpandGlobs 失败: %v", err)
+	}
+	var files []string
+	for _, config := range expanded {
+		files = append(files, config.FileName+":"+config.File)
+	}
+	want := "literal.json:src/routes/[id].tsx escaped.json:src/routes/[id].tsx"
+	if got := strings.Join(files, " "); got != want {
+		t.Errorf("展开结果错误:\ngot  %s\nwant %s", got, want)
+	}
+	if len(globs) != 1 || globs[0].Config.FileName != "escaped.json" {
+		t.Errorf("只有转义的配置应按 glob 展开: %+v", globs)
+	}
+}
+
+func TestExpandGlobs_FileOccurrences(t *testing.T) {
+	srcDir := t.TempDir()
+	writePackFile(t, srcDir, "packages/opencode/src/ui/dialog-a.tsx", `"Cancel" "Cancel"`)
+	writePackFile(t, srcDir, "packages/opencode/src/ui/dialog-b.tsx", `"Cancel" "Cancel"`)
+
+	i18n := &I18n{opencodeDir: srcDir}
+	configs := []TranslationConfig{{
+		FileName:     "shared.json",
+		File:         "src/ui/dialog-*.tsx",
+		Replacements: map[string]string{`"Cancel"`: `"取消"`},
+		FileOccurrences: map[string]map[string][]int{
+			"src/ui/dialog-b.tsx": {`"Cancel"`: {2}},
+		},
+	}}
+
+	if _, err := i18n.ApplyAll(context.Background(), configs, ApplyOptions{}); err != nil {
+		t.Fatalf("ApplyAll 失败: %v", err)
+	}
+	if got := readFileString(t, srcDir, "packages/opencode/src/ui/dialog-a.tsx"); got != `"取消" "取消"` {
+		t.Errorf("没有过滤器的文件应全部替换: %q", got)
+	}
+	if got := readFileString(t, srcDir, "packages/opencode/src/ui/dialog-b.tsx"); got != `"Cancel" "取消"` {
+		t.Errorf("应按文件的过滤器替换: %q", got)
+	}
+}
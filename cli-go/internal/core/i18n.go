@@ -2,13 +2,15 @@ package core
 
 import (
 	"embed"
-	"encoding/json"
 	"fmt"
+	"io"
 	"io/fs"
 	"os"
 	"path/filepath"
 	"regexp"
+	"sort"
 	"strings"
+	"sync"
 )
 
 //go:embed assets/opencode-i18n
@@ -21,6 +23,19 @@ type TranslationConfig struct {
 	ConfigPath   string
 	File         string            `json:"file"`
 	Replacements map[string]string `json:"replacements"`
+	// Target 目标名称（config.json targets），file 相对该目标的 root；为空时按 file 前缀推断
+	Target string `json:"target,omitempty"`
+	// MaxMatches file 为 glob 时预期匹配的最大文件数，超出时警告（0 表示 DefaultGlobMaxMatches）
+	MaxMatches int `json:"maxMatches,omitempty"`
+	// Glob 由 glob 配置展开而来时记录原始模式，File 为展开后的具体文件
+	Glob string `json:"-"`
+	// MaxWidthRatio 覆盖 verify 的显示宽度比例上限（用于已知较宽的组件）
+	MaxWidthRatio float64 `json:"maxWidthRatio,omitempty"`
+	// Occurrences 匹配过滤器：原文 -> 只替换文件中第几处匹配（从 1 开始），空列表表示全部跳过
+	// 未列出的规则替换全部匹配
+	Occurrences map[string][]int `json:"occurrences,omitempty"`
+	// FileOccurrences file 为 glob 时按展开后的文件记录的匹配过滤器：文件 -> 原文 -> 序号，优先于 Occurrences
+	FileOccurrences map[string]map[string][]int `json:"fileOccurrences,omitempty"`
 }
 
 // Replacement 单条替换规则（用于 verify 命令）
@@ -53,10 +68,33 @@ type I18n struct {
 	i18nDir     string
 	opencodeDir string
 	useEmbedded bool
+	// pack 显式指定的汉化包文件系统，优先于 i18nDir
+	pack fs.FS
+	// out 日志输出，为 nil 时丢弃
+	out io.Writer
+	// matchers 当前汉化包的规则匹配器缓存，每次 LoadConfig 重建
+	matchers *matcherCache
+	// targets config.json 声明的目标范围，每次 LoadConfig 重新读取
+	targets []PackTarget
 }
 
-// NewI18n 创建 I18n 实例
+// Options I18n 的显式配置，用于在其他程序中嵌入汉化引擎
+type Options struct {
+	// Pack 汉化包（包含 config.json 的目录视图），为 nil 时使用内置汉化包
+	Pack fs.FS
+	// SourceDir OpenCode 源码根目录
+	SourceDir string
+	// Output 日志输出，为 nil 时丢弃
+	Output io.Writer
+}
+
+// NewI18n 创建 I18n 实例，目录通过环境变量和项目目录自动解析，提示输出到标准输出
 func NewI18n() (*I18n, error) {
+	return ResolveI18n(os.Stdout)
+}
+
+// ResolveI18n 按命令行的规则解析汉化包和源码目录，提示输出到 out
+func ResolveI18n(out io.Writer) (*I18n, error) {
 	i18nDir, err := GetI18nDir()
 	useEmbedded := false
 
@@ -72,31 +110,72 @@ func NewI18n() (*I18n, error) {
 		return nil, err
 	}
 
+	i := &I18n{
+		i18nDir:     i18nDir,
+		opencodeDir: opencodeDir,
+		useEmbedded: useEmbedded,
+		out:         out,
+	}
+
 	if useEmbedded {
-		fmt.Println("提示: 使用内置汉化配置")
+		i.logf("提示: 使用内置汉化配置\n")
 	} else {
-		fmt.Printf("提示: 使用外部汉化配置: %s\n", i18nDir)
+		i.logf("提示: 使用外部汉化配置: %s\n", i18nDir)
 	}
 
-	return &I18n{
-		i18nDir:     i18nDir,
-		opencodeDir: opencodeDir,
-		useEmbedded: useEmbedded,
-	}, nil
+	return i, nil
+}
+
+// NewI18nWithOptions 使用显式配置创建 I18n 实例，不读取环境变量
+func NewI18nWithOptions(opts Options) (*I18n, error) {
+	i := &I18n{
+		opencodeDir: opts.SourceDir,
+		pack:        opts.Pack,
+		out:         opts.Output,
+	}
+	if i.pack == nil {
+		i.i18nDir = "assets/opencode-i18n"
+		i.useEmbedded = true
+	}
+	packFS, err := i.packFS()
+	if err != nil {
+		return nil, err
+	}
+	if _, err := fs.Stat(packFS, ManifestFileName); err != nil {
+		return nil, fmt.Errorf("汉化包中缺少 %s: %w", ManifestFileName, err)
+	}
+	return i, nil
+}
+
+// EmbeddedPack 返回内置汉化包的文件系统视图
+func EmbeddedPack() fs.FS {
+	pack, _ := fs.Sub(embeddedAssets, "assets/opencode-i18n")
+	return pack
+}
+
+// SourceDir 返回 OpenCode 源码根目录
+func (i *I18n) SourceDir() string {
+	return i.opencodeDir
+}
+
+// logf 输出日志
+func (i *I18n) logf(format string, args ...interface{}) {
+	if i.out != nil {
+		fmt.Fprintf(i.out, format, args...)
+	}
 }
 
 // LoadConfig 读取所有汉化配置文件
 func (i *I18n) LoadConfig() ([]TranslationConfig, error) {
 	var configs []TranslationConfig
-	var entries []fs.DirEntry
-	var err error
+	i.matchers = newMatcherCache()
+	i.loadTargets()
 
-	if i.useEmbedded {
-		entries, err = fs.ReadDir(embeddedAssets, i.i18nDir)
-	} else {
-		entries, err = os.ReadDir(i.i18nDir)
+	packFS, err := i.packFS()
+	if err != nil {
+		return nil, err
 	}
-
+	entries, err := fs.ReadDir(packFS, ".")
 	if err != nil {
 		return nil, err
 	}
@@ -106,23 +185,14 @@ func (i *I18n) LoadConfig() ([]TranslationConfig, error) {
 			// 处理子目录中的配置文件
 			categoryName := entry.Name()
 
-			var files []fs.DirEntry
-			if i.useEmbedded {
-				// Embedded FS 路径必须使用正斜杠
-				embedPath := i.i18nDir + "/" + categoryName
-				files, err = fs.ReadDir(embeddedAssets, embedPath)
-			} else {
-				categoryDir := filepath.Join(i.i18nDir, categoryName)
-				files, err = os.ReadDir(categoryDir)
-			}
-
+			files, err := fs.ReadDir(packFS, categoryName)
 			if err != nil {
 				continue
 			}
 
 			for _, file := range files {
 				if strings.HasSuffix(file.Name(), ".json") {
-					config := i.loadSingleConfig(categoryName, file.Name())
+					config := i.loadSingleConfig(packFS, categoryName, file.Name())
 					if config != nil {
 						configs = append(configs, *config)
 					}
@@ -131,10 +201,10 @@ func (i *I18n) LoadConfig() ([]TranslationConfig, error) {
 		} else if strings.HasSuffix(entry.Name(), ".json") {
 			// 处理根目录下的配置文件（如 app.json）
 			// 跳过 config.json（元信息文件，不是汉化规则）
-			if entry.Name() == "config.json" {
+			if entry.Name() == ManifestFileName {
 				continue
 			}
-			config := i.loadSingleConfig("root", entry.Name())
+			config := i.loadSingleConfig(packFS, "root", entry.Name())
 			if config != nil {
 				configs = append(configs, *config)
 			}
@@ -145,33 +215,31 @@ func (i *I18n) LoadConfig() ([]TranslationConfig, error) {
 }
 
 // loadSingleConfig 加载单个配置文件
-func (i *I18n) loadSingleConfig(category, fileName string) *TranslationConfig {
+func (i *I18n) loadSingleConfig(packFS fs.FS, category, fileName string) *TranslationConfig {
 	var config TranslationConfig
-	var configPath string
-	var readErr error
 
-	if i.useEmbedded {
-		if category == "root" {
-			configPath = i.i18nDir + "/" + fileName
-		} else {
-			configPath = i.i18nDir + "/" + category + "/" + fileName
-		}
-		var data []byte
-		data, readErr = fs.ReadFile(embeddedAssets, configPath)
-		if readErr == nil {
-			readErr = json.Unmarshal(data, &config)
-		}
-	} else {
-		if category == "root" {
-			configPath = filepath.Join(i.i18nDir, fileName)
-		} else {
-			configPath = filepath.Join(i.i18nDir, category, fileName)
-		}
-		readErr = ReadJSON(configPath, &config)
+	// fs.FS 路径必须使用正斜杠
+	rel := fileName
+	if category != "root" {
+		rel = category + "/" + fileName
 	}
 
+	// ConfigPath 保持与来源一致：外部目录为磁盘路径，内嵌资源为 embed 路径
+	configPath := rel
+	switch {
+	case i.pack != nil:
+	case i.useEmbedded:
+		configPath = i.i18nDir + "/" + rel
+	default:
+		configPath = filepath.Join(i.i18nDir, filepath.FromSlash(rel))
+	}
+
+	data, readErr := fs.ReadFile(packFS, rel)
+	if readErr == nil {
+		readErr = DecodeJSON(data, &config)
+	}
 	if readErr != nil {
-		fmt.Printf("警告: 解析配置文件失败 %s: %v\n", configPath, readErr)
+		i.logf("警告: 解析配置文件失败 %s: %v\n", configPath, readErr)
 		return nil
 	}
 
@@ -192,26 +260,33 @@ type ApplyResult struct {
 	}
 	Skipped    bool
 	SkipReason string
+	// Selections 交互审查中未全部接受的规则：原文 -> 接受的匹配序号
+	Selections map[string][]int
+	// Missed 在文件中找不到匹配的规则原文
+	Missed []string
 }
 
 // GetTargetFilePath 获取汉化配置对应的目标文件完整路径
-// 统一 verify 和 apply 的路径处理逻辑
+// 统一 verify 和 apply 的路径处理逻辑，目标名称未声明时返回空字符串
 func (i *I18n) GetTargetFilePath(config TranslationConfig) string {
 	if config.File == "" {
 		return ""
 	}
 
-	relativePath := config.File
-	// 如果路径不以 packages/ 开头，自动添加 packages/opencode/ 前缀
-	if !strings.HasPrefix(relativePath, "packages/") {
-		relativePath = filepath.Join("packages", "opencode", relativePath)
+	relativePath, err := i.targetRelPath(config)
+	if err != nil {
+		return ""
 	}
-
-	return filepath.Join(i.opencodeDir, relativePath)
+	return filepath.Join(i.opencodeDir, filepath.FromSlash(relativePath))
 }
 
 // ApplyConfig 应用单个配置文件的替换规则
 func (i *I18n) ApplyConfig(config TranslationConfig, dryRun bool) ApplyResult {
+	return i.applyConfig(config, dryRun, nil, nil)
+}
+
+// applyConfig 应用单个配置文件，review 不为 nil 时逐处审查需要确认的匹配，journal 不为 nil 时在写入前记录原内容
+func (i *I18n) applyConfig(config TranslationConfig, dryRun bool, review OccurrenceReviewer, journal *FileJournal) ApplyResult {
 	result := ApplyResult{
 		File: config.File,
 	}
@@ -222,6 +297,12 @@ func (i *I18n) ApplyConfig(config TranslationConfig, dryRun bool) ApplyResult {
 		return result
 	}
 
+	if !i.InTargets(config) {
+		result.Skipped = true
+		result.SkipReason = "目标文件不在 config.json targets 声明的范围内"
+		return result
+	}
+
 	targetPath := i.GetTargetFilePath(config)
 
 	if !Exists(targetPath) {
@@ -230,57 +311,64 @@ func (i *I18n) ApplyConfig(config TranslationConfig, dryRun bool) ApplyResult {
 		return result
 	}
 
-	contentBytes, err := os.ReadFile(targetPath)
+	file, err := ReadTextFile(targetPath)
 	if err != nil {
 		result.Skipped = true
 		result.SkipReason = fmt.Sprintf("读取文件失败: %v", err)
 		return result
 	}
-	content := string(contentBytes)
-	// 规范化换行符
-	content = strings.ReplaceAll(content, "\r\n", "\n")
+	// 纯 CRLF 文件已规范化为 LF，写回时恢复原始换行、BOM 和权限
+	content := file.Content
 	originalContent := content
 
-	result.Replacements.Total = len(config.Replacements)
+	var sc *occurrenceScanner
+	if review != nil {
+		sc = newOccurrenceScanner(targetPath, content)
+	}
 
-	for find, replace := range config.Replacements {
-		// 规范化查找字符串
-		normalizedFind := strings.ReplaceAll(find, "\r\n", "\n")
+	result.Replacements.Total = len(config.Replacements)
 
-		// 判断是否为简单单词（只包含字母和数字）
-		isSimpleWord, _ := regexp.MatchString("^[a-zA-Z0-9]+$", normalizedFind)
+	for _, find := range orderedRules(config.Replacements) {
+		matcher, replace := i.ruleFor(file, content, find, config.Replacements[find])
 
-		matched := false
-		if isSimpleWord {
-			// 简单单词使用单词边界
-			wordBoundaryPattern := regexp.MustCompile(`\b` + regexp.QuoteMeta(normalizedFind) + `\b`)
-			if wordBoundaryPattern.MatchString(content) {
-				if !dryRun {
-					content = wordBoundaryPattern.ReplaceAllString(content, replace)
-				}
-				matched = true
-			}
-		} else {
-			// 复杂模式使用普通替换
-			if strings.Contains(content, normalizedFind) {
-				if !dryRun {
-					content = strings.ReplaceAll(content, normalizedFind, replace)
+		positions := matcher.FindAll(content)
+		if len(positions) == 0 {
+			result.Replacements.Failed++
+			result.Missed = append(result.Missed, find)
+			continue
+		}
+		result.Replacements.Success++
+
+		selected := positions
+		if filter, ok := config.Occurrences[find]; ok {
+			selected = selectOccurrences(positions, filter)
+		} else if review != nil {
+			sc.reset(content)
+			var indexes []int
+			selected, indexes = reviewRule(sc, &config, find, positions, len(matcher.find), review)
+			if len(selected) != len(positions) {
+				if result.Selections == nil {
+					result.Selections = make(map[string][]int)
 				}
-				matched = true
+				result.Selections[find] = indexes
 			}
 		}
 
-		if matched {
-			result.Replacements.Success++
-		} else {
-			result.Replacements.Failed++
+		if !dryRun {
+			// 运行时字典策略的改写结果仍包含英文原文，已改写的位置不再重复包裹
+			selected = skipRuntimeCalls(content, selected)
+			content = matcher.ReplaceAt(content, selected, replace)
 		}
 	}
 
 	if !dryRun && content != originalContent {
-		if err := os.WriteFile(targetPath, []byte(content), 0644); err != nil {
+		file.Content = content
+		if journal != nil {
+			journal.Record(targetPath)
+		}
+		if err := file.Write(targetPath); err != nil {
 			result.Success = false
-			fmt.Printf("错误: 写入文件失败 %s: %v\n", targetPath, err)
+			i.logf("错误: 写入文件失败 %s: %v\n", targetPath, err)
 		} else {
 			result.Success = result.Replacements.Success > 0
 		}
@@ -290,3 +378,170 @@ func (i *I18n) ApplyConfig(config TranslationConfig, dryRun bool) ApplyResult {
 
 	return result
 }
+
+// selectOccurrences 按过滤器（从 1 开始的序号）选出匹配位置
+func selectOccurrences(positions []int, filter []int) []int {
+	var selected []int
+	for _, n := range filter {
+		if n >= 1 && n <= len(positions) {
+			selected = append(selected, positions[n-1])
+		}
+	}
+	sort.Ints(selected)
+	return selected
+}
+
+// ruleFor 返回规则在文件当前内容中使用的匹配器与译文
+// 混合换行的文件未规范化，多行规则在 LF 形式不匹配时改用 CRLF 形式
+func (i *I18n) ruleFor(file *TextFile, content, find, replace string) (*ruleMatcher, string) {
+	matcher := i.matcher(find)
+	replace = strings.ReplaceAll(replace, "\r\n", "\n")
+	if file.MixedNewlines && strings.Contains(matcher.find, "\n") && !matcher.Match(content) {
+		matcher = &ruleMatcher{find: strings.ReplaceAll(matcher.find, "\n", "\r\n")}
+		replace = strings.ReplaceAll(replace, "\n", "\r\n")
+	}
+	return matcher, replace
+}
+
+// orderedRules 返回规则的应用顺序：原文较长的优先，长度相同时按字典序
+// 保证结果确定，并让更具体的规则先于其中包含的短规则生效
+func orderedRules(replacements map[string]string) []string {
+	keys := sortedKeys(replacements)
+	sort.SliceStable(keys, func(a, b int) bool {
+		return len(keys[a]) > len(keys[b])
+	})
+	return keys
+}
+
+// matcherCache 规则匹配器缓存，可并发使用
+type matcherCache struct {
+	mu       sync.RWMutex
+	matchers map[string]*ruleMatcher
+}
+
+func newMatcherCache() *matcherCache {
+	return &matcherCache{matchers: make(map[string]*ruleMatcher)}
+}
+
+// get 返回 find 对应的匹配器，不存在时编译并缓存
+func (c *matcherCache) get(find string) *ruleMatcher {
+	c.mu.RLock()
+	m, ok := c.matchers[find]
+	c.mu.RUnlock()
+	if ok {
+		return m
+	}
+
+	m = newRuleMatcher(find)
+	c.mu.Lock()
+	c.matchers[find] = m
+	c.mu.Unlock()
+	return m
+}
+
+// matcher 返回规则匹配器，未加载汉化包（无缓存）时直接编译
+func (i *I18n) matcher(find string) *ruleMatcher {
+	if i.matchers == nil {
+		return newRuleMatcher(find)
+	}
+	return i.matchers.get(find)
+}
+
+// ruleMatcher 单条替换规则的匹配器
+// 简单单词（只包含字母和数字）使用单词边界匹配（与正则 \b 一致），其余使用普通字符串匹配
+type ruleMatcher struct {
+	find string
+	word bool
+}
+
+var simpleWordPattern = regexp.MustCompile("^[a-zA-Z0-9]+$")
+
+// newRuleMatcher 创建匹配器，查找字符串中的 CRLF 会被规范化为 LF
+func newRuleMatcher(find string) *ruleMatcher {
+	normalizedFind := strings.ReplaceAll(find, "\r\n", "\n")
+	return &ruleMatcher{
+		find: normalizedFind,
+		word: simpleWordPattern.MatchString(normalizedFind),
+	}
+}
+
+// Match 判断内容中是否存在匹配
+func (m *ruleMatcher) Match(content string) bool {
+	return m.next(content, 0) >= 0
+}
+
+// ReplaceAll 替换内容中的全部匹配
+func (m *ruleMatcher) ReplaceAll(content, replace string) string {
+	if !m.word {
+		return strings.ReplaceAll(content, m.find, replace)
+	}
+
+	var sb strings.Builder
+	last := 0
+	for pos := m.next(content, 0); pos >= 0; pos = m.next(content, pos+len(m.find)) {
+		sb.WriteString(content[last:pos])
+		sb.WriteString(replace)
+		last = pos + len(m.find)
+	}
+	if last == 0 {
+		return content
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// ReplaceAt 只替换 positions（FindAll 返回的位置，升序）处的匹配
+func (m *ruleMatcher) ReplaceAt(content string, positions []int, replace string) string {
+	if len(positions) == 0 {
+		return content
+	}
+	var sb strings.Builder
+	last := 0
+	for _, pos := range positions {
+		sb.WriteString(content[last:pos])
+		sb.WriteString(replace)
+		last = pos + len(m.find)
+	}
+	sb.WriteString(content[last:])
+	return sb.String()
+}
+
+// FindAll 返回内容中所有匹配的起始位置（与 ReplaceAll 替换的位置一致）
+func (m *ruleMatcher) FindAll(content string) []int {
+	var positions []int
+	for pos := m.next(content, 0); pos >= 0; pos = m.next(content, pos+len(m.find)) {
+		positions = append(positions, pos)
+	}
+	return positions
+}
+
+// next 返回从 start 开始的下一个匹配位置，没有时返回 -1
+func (m *ruleMatcher) next(content string, start int) int {
+	if m.find == "" {
+		return -1
+	}
+	for start <= len(content) {
+		n := strings.Index(content[start:], m.find)
+		if n < 0 {
+			return -1
+		}
+		pos := start + n
+		if !m.word || m.atWordBoundary(content, pos) {
+			return pos
+		}
+		start = pos + 1
+	}
+	return -1
+}
+
+// atWordBoundary 判断 pos 处的匹配两侧是否为单词边界
+func (m *ruleMatcher) atWordBoundary(content string, pos int) bool {
+	end := pos + len(m.find)
+	return (pos == 0 || !isASCIIWordByte(content[pos-1])) &&
+		(end == len(content) || !isASCIIWordByte(content[end]))
+}
+
+// isASCIIWordByte 与正则 \w 相同：[0-9A-Za-z_]
+func isASCIIWordByte(c byte) bool {
+	return c == '_' || ('0' <= c && c <= '9') || ('a' <= c && c <= 'z') || ('A' <= c && c <= 'Z')
+}
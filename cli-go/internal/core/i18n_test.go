@@ -3,6 +3,7 @@ package core
 import (
 	"os"
 	"path/filepath"
+	"runtime"
 	"strings"
 	"testing"
 )
@@ -391,6 +392,68 @@ func TestApplyConfig_CRLFNormalization(t *testing.T) {
 	}
 }
 
+func TestApplyConfig_PreservesCRLF(t *testing.T) {
+	tmpDir := t.TempDir()
+	writePackFile(t, tmpDir, "packages/opencode/src/app.tsx", "const a = 1\r\nHello\r\nWorld\r\n")
+
+	i18n := &I18n{opencodeDir: tmpDir}
+	i18n.ApplyConfig(TranslationConfig{
+		File:         "src/app.tsx",
+		Replacements: map[string]string{"Hello\nWorld": "你好\n世界"},
+	}, false)
+
+	got := readFileString(t, tmpDir, "packages/opencode/src/app.tsx")
+	if want := "const a = 1\r\n你好\r\n世界\r\n"; got != want {
+		t.Errorf("应保留 CRLF 换行: got %q, want %q", got, want)
+	}
+}
+
+func TestApplyConfig_MixedNewlines(t *testing.T) {
+	tmpDir := t.TempDir()
+	writePackFile(t, tmpDir, "packages/opencode/src/app.tsx", "a\nHello\r\nWorld\r\nb\n")
+
+	i18n := &I18n{opencodeDir: tmpDir}
+	result := i18n.ApplyConfig(TranslationConfig{
+		File:         "src/app.tsx",
+		Replacements: map[string]string{"Hello\nWorld": "你好\n世界"},
+	}, false)
+
+	if result.Replacements.Success != 1 {
+		t.Fatalf("混合换行文件中应匹配 CRLF 形式的规则, got success=%d", result.Replacements.Success)
+	}
+	got := readFileString(t, tmpDir, "packages/opencode/src/app.tsx")
+	if want := "a\n你好\r\n世界\r\nb\n"; got != want {
+		t.Errorf("未翻译的行应保持原样: got %q, want %q", got, want)
+	}
+}
+
+func TestApplyConfig_PreservesBOMAndMode(t *testing.T) {
+	tmpDir := t.TempDir()
+	writePackFile(t, tmpDir, "packages/opencode/bin/run.ts", "\xef\xbb\xbfconsole.log(\"Hello\")\n")
+	targetPath := filepath.Join(tmpDir, "packages", "opencode", "bin", "run.ts")
+	if err := os.Chmod(targetPath, 0755); err != nil {
+		t.Fatalf("设置权限失败: %v", err)
+	}
+
+	i18n := &I18n{opencodeDir: tmpDir}
+	i18n.ApplyConfig(TranslationConfig{
+		File:         "bin/run.ts",
+		Replacements: map[string]string{`"Hello"`: `"你好"`},
+	}, false)
+
+	got := readFileString(t, tmpDir, "packages/opencode/bin/run.ts")
+	if want := "\xef\xbb\xbfconsole.log(\"你好\")\n"; got != want {
+		t.Errorf("应保留 BOM: got %q, want %q", got, want)
+	}
+	info, err := os.Stat(targetPath)
+	if err != nil {
+		t.Fatalf("读取文件信息失败: %v", err)
+	}
+	if runtime.GOOS != "windows" && info.Mode().Perm() != 0755 {
+		t.Errorf("应保留文件权限: got %v, want 0755", info.Mode().Perm())
+	}
+}
+
 // ========== 辅助函数测试 ==========
 
 func TestDirExists(t *testing.T) {
@@ -0,0 +1,162 @@
+package core
+
+import (
+	"strings"
+	"unicode/utf8"
+)
+
+// ContextLine 匹配位置附近的一行源码
+type ContextLine struct {
+	Number int
+	Text   string
+}
+
+// Occurrence 规则在目标文件中的一处匹配
+type Occurrence struct {
+	Config *TranslationConfig
+	From   string
+	To     string
+	// Path 目标文件完整路径
+	Path string
+	// Index 该规则在文件中的第几处匹配，从 1 开始
+	Index  int
+	Offset int
+	Line   int
+	Column int
+	Kind   HitKind
+	// Context 匹配所在行及前后各 n 行
+	Context []ContextLine
+}
+
+// occurrenceScanner 在一个文件的当前内容中定位匹配
+// 内容变化后重新扫描词法信息
+type occurrenceScanner struct {
+	path    string
+	content string
+	jsx     bool
+	source  *SourceMap
+	// lineStarts 每行起始偏移
+	lineStarts []int
+}
+
+func newOccurrenceScanner(path, content string) *occurrenceScanner {
+	sc := &occurrenceScanner{path: path, jsx: IsJSXFile(path)}
+	sc.reset(content)
+	return sc
+}
+
+// reset 更新内容（应用替换后调用）
+func (sc *occurrenceScanner) reset(content string) {
+	if content == sc.content && sc.source != nil {
+		return
+	}
+	sc.content = content
+	sc.source = nil
+	sc.lineStarts = []int{0}
+	for n, c := range []byte(content) {
+		if c == '\n' {
+			sc.lineStarts = append(sc.lineStarts, n+1)
+		}
+	}
+}
+
+// locate 生成 offset 处长度为 length 的匹配信息
+func (sc *occurrenceScanner) locate(offset, length, contextLines int) Occurrence {
+	if sc.source == nil {
+		sc.source = ScanSource(sc.content, sc.jsx)
+	}
+
+	// 二分查找所在行
+	lo, hi := 0, len(sc.lineStarts)-1
+	for lo < hi {
+		mid := (lo + hi + 1) / 2
+		if sc.lineStarts[mid] <= offset {
+			lo = mid
+		} else {
+			hi = mid - 1
+		}
+	}
+
+	occ := Occurrence{
+		Path:   sc.path,
+		Offset: offset,
+		Line:   lo + 1,
+		Column: utf8.RuneCountInString(sc.content[sc.lineStarts[lo]:offset]) + 1,
+		Kind:   sc.source.Classify(offset, offset+length),
+	}
+
+	for n := lo - contextLines; n <= lo+contextLines; n++ {
+		// 文件末尾换行之后的空行不算一行
+		if n < 0 || n >= len(sc.lineStarts) || sc.lineStarts[n] == len(sc.content) {
+			continue
+		}
+		end := len(sc.content)
+		if n+1 < len(sc.lineStarts) {
+			end = sc.lineStarts[n+1] - 1
+		}
+		text := strings.TrimRight(sc.content[sc.lineStarts[n]:end], "\r")
+		occ.Context = append(occ.Context, ContextLine{Number: n + 1, Text: text})
+	}
+	return occ
+}
+
+// FindOccurrences 按 ApplyConfig 的规则与顺序模拟应用，列出每条规则的所有匹配位置
+// 同一文件的配置依次模拟，后面的规则看到的是前面规则替换后的内容，与实际应用一致
+// contextLines 为每处匹配前后附带的行数
+func (i *I18n) FindOccurrences(configs []TranslationConfig, contextLines int) ([]Occurrence, error) {
+	var occurrences []Occurrence
+
+	configs, _, err := i.ExpandGlobs(configs)
+	if err != nil {
+		return nil, err
+	}
+
+	// 按目标文件分组，保持首次出现的顺序
+	var targets []string
+	groups := make(map[string][]int)
+	for idx, config := range configs {
+		target := i.GetTargetFilePath(config)
+		if target == "" || len(config.Replacements) == 0 || !i.InTargets(config) {
+			continue
+		}
+		if _, ok := groups[target]; !ok {
+			targets = append(targets, target)
+		}
+		groups[target] = append(groups[target], idx)
+	}
+
+	for _, target := range targets {
+		if !Exists(target) {
+			continue
+		}
+		file, err := ReadTextFile(target)
+		if err != nil {
+			return nil, err
+		}
+
+		content := file.Content
+		sc := newOccurrenceScanner(target, content)
+
+		for _, idx := range groups[target] {
+			config := &configs[idx]
+			for _, find := range orderedRules(config.Replacements) {
+				matcher, replace := i.ruleFor(file, content, find, config.Replacements[find])
+				positions := matcher.FindAll(content)
+				for n, pos := range positions {
+					occ := sc.locate(pos, len(matcher.find), contextLines)
+					occ.Config = config
+					occ.From = find
+					occ.To = config.Replacements[find]
+					occ.Index = n + 1
+					occurrences = append(occurrences, occ)
+				}
+				if len(positions) > 0 {
+					content = matcher.ReplaceAll(content, replace)
+					sc.reset(content)
+				}
+			}
+		}
+	}
+
+	return occurrences, nil
+}
@@ -0,0 +1,111 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// ========== ScanSource 测试 ==========
+
+func TestScanSource_Classify(t *testing.T) {
+	src := `import { Free } from "./free"
+// Free tier comment
+const label = "Free"
+const tip = ` + "`Free ${Free}`" + `
+export function View() {
+  return <text fg={theme.text} title="Free">Free plan {count > 1 ? "Free" : "Paid"}</text>
+}
+const ratio = a / b / Free
+`
+	m := ScanSource(src, true)
+
+	tests := []struct {
+		needle string
+		nth    int
+		want   HitKind
+	}{
+		{"Free", 0, KindIdentifier}, // import { Free }
+		{"free", 0, KindImport},
+		{"Free", 1, KindComment},
+		{"Free", 2, KindString},
+		{"Free", 3, KindString},     // 模板文本
+		{"Free", 4, KindIdentifier}, // ${Free}
+		{"Free", 5, KindString},     // JSX 属性
+		{"Free", 6, KindJSXText},
+		{"Free", 7, KindString}, // JSX 表达式中的字符串
+		{"Free", 8, KindIdentifier},
+	}
+
+	for _, tt := range tests {
+		offset := nthIndex(src, tt.needle, tt.nth)
+		if offset < 0 {
+			t.Fatalf("未找到第 %d 个 %q", tt.nth, tt.needle)
+		}
+		if got := m.Classify(offset, offset+len(tt.needle)); got != tt.want {
+			t.Errorf("第 %d 个 %q: got %s, want %s", tt.nth, tt.needle, got, tt.want)
+		}
+	}
+}
+
+func TestScanSource_GenericsInTS(t *testing.T) {
+	src := "const f = <T>(x: T) => x\nconst s = \"Free\"\n"
+	m := ScanSource(src, false)
+	offset := strings.Index(src, "Free")
+	if got := m.Classify(offset, offset+4); got != KindString {
+		t.Errorf(".ts 文件中的泛型不应被识别为 JSX: got %s", got)
+	}
+}
+
+// nthIndex 返回 sub 在 s 中第 n 次（从 0 开始）出现的位置
+func nthIndex(s, sub string, n int) int {
+	offset := 0
+	for ; n >= 0; n-- {
+		idx := strings.Index(s[offset:], sub)
+		if idx < 0 {
+			return -1
+		}
+		if n == 0 {
+			return offset + idx
+		}
+		offset += idx + len(sub)
+	}
+	return -1
+}
+
+// ========== FindOccurrences 测试 ==========
+
+func TestFindOccurrences(t *testing.T) {
+	tmpDir := t.TempDir()
+	writePackFile(t, tmpDir, "packages/opencode/src/plan.tsx", "const isFree = Free;\r\n<text>Free</text>\r\n")
+
+	i18n := &I18n{opencodeDir: tmpDir}
+	configs := []TranslationConfig{{
+		File:         "src/plan.tsx",
+		FileName:     "plan.json",
+		Replacements: map[string]string{"Free": "免费"},
+	}}
+
+	occurrences, err := i18n.FindOccurrences(configs, 1)
+	if err != nil {
+		t.Fatalf("FindOccurrences 失败: %v", err)
+	}
+	if len(occurrences) != 2 {
+		t.Fatalf("应找到 2 处匹配 (isFree 不匹配), got %d", len(occurrences))
+	}
+
+	first, second := occurrences[0], occurrences[1]
+	if first.Line != 1 || first.Column != 16 || first.Kind != KindIdentifier || first.Index != 1 {
+		t.Errorf("第一处匹配错误: %+v", first)
+	}
+	if second.Line != 2 || second.Kind != KindJSXText || second.Index != 2 {
+		t.Errorf("第二处匹配错误: %+v", second)
+	}
+	if len(second.Context) != 2 || second.Context[1].Text != "<text>Free</text>" {
+		t.Errorf("上下文错误: %+v", second.Context)
+	}
+
+	// 分析不应修改文件
+	if content := readFileString(t, tmpDir, "packages/opencode/src/plan.tsx"); !strings.Contains(content, "Free") {
+		t.Error("FindOccurrences 不应修改文件")
+	}
+}
@@ -0,0 +1,337 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// HitKind 匹配位置在源码中的语法类别
+type HitKind string
+
+const (
+	KindString     HitKind = "string"
+	KindJSXText    HitKind = "jsx-text"
+	KindIdentifier HitKind = "identifier"
+	KindComment    HitKind = "comment"
+	KindImport     HitKind = "import"
+	KindCode       HitKind = "code"
+)
+
+// Suspicious 判断该类别的匹配是否可能是误伤（修改了代码而不是界面文本）
+func (k HitKind) Suspicious() bool {
+	return k == KindIdentifier || k == KindImport || k == KindCode
+}
+
+// kindPriority 匹配跨越多个区域时的判定优先级（越大越优先）
+var kindPriority = map[HitKind]int{
+	KindCode:       0,
+	KindIdentifier: 1,
+	KindJSXText:    2,
+	KindString:     3,
+	KindComment:    4,
+	KindImport:     5,
+}
+
+// sourceRegion 源码中一段非代码区域
+type sourceRegion struct {
+	start, end int
+	kind       HitKind
+}
+
+// SourceMap TS/TSX 源码的粗粒度词法划分
+// 只记录字符串、模板文本、JSX 文本、注释和导入路径，其余都是代码
+type SourceMap struct {
+	content string
+	regions []sourceRegion
+}
+
+// ScanSource 扫描 TS/TSX 源码，jsx 为 false 时（.ts 文件）不识别 JSX，避免把泛型误判为标签
+// 这是一个容错的近似词法分析器，不追求完整的语法正确性，遇到无法识别的结构时按代码处理
+func ScanSource(content string, jsx bool) *SourceMap {
+	sc := &sourceScanner{s: content, jsx: jsx}
+	for sc.pos < len(sc.s) {
+		sc.scanCode(false)
+		if sc.pos < len(sc.s) {
+			// 顶层多余的 '}'
+			sc.pos++
+		}
+	}
+	sort.Slice(sc.regions, func(a, b int) bool { return sc.regions[a].start < sc.regions[b].start })
+	return &SourceMap{content: content, regions: sc.regions}
+}
+
+// Classify 返回 [start, end) 范围的类别
+func (m *SourceMap) Classify(start, end int) HitKind {
+	kind := KindCode
+	n := sort.Search(len(m.regions), func(i int) bool { return m.regions[i].end > start })
+	for ; n < len(m.regions) && m.regions[n].start < end; n++ {
+		if kindPriority[m.regions[n].kind] > kindPriority[kind] {
+			kind = m.regions[n].kind
+		}
+	}
+
+	if kind == KindCode && start < end && isIdentifierByte(m.content[start]) && isIdentifierByte(m.content[end-1]) {
+		return KindIdentifier
+	}
+	return kind
+}
+
+// sourceScanner ScanSource 的状态
+type sourceScanner struct {
+	s       string
+	pos     int
+	jsx     bool
+	regions []sourceRegion
+	// prev 上一个有意义的代码字符，prevWord 上一个标识符或关键字
+	prev     byte
+	prevWord string
+}
+
+func (sc *sourceScanner) add(start, end int, kind HitKind) {
+	if end > start {
+		sc.regions = append(sc.regions, sourceRegion{start: start, end: end, kind: kind})
+	}
+}
+
+func (sc *sourceScanner) peek(offset int) byte {
+	if sc.pos+offset < len(sc.s) {
+		return sc.s[sc.pos+offset]
+	}
+	return 0
+}
+
+// expressionStart 判断当前位置是否处于表达式开头（用于区分 JSX/正则 与 小于号/除号）
+func (sc *sourceScanner) expressionStart() bool {
+	if sc.prev == 0 || strings.IndexByte("(,=:?[{}&|!;>+-*%~^", sc.prev) >= 0 {
+		return true
+	}
+	switch sc.prevWord {
+	case "return", "yield", "default", "case", "else", "do", "typeof", "in", "of", "await":
+		return isIdentifierByte(sc.prev)
+	}
+	return false
+}
+
+// scanCode 扫描代码直到文件结束；inBraces 为 true 时在匹配的 '}' 处停止（不消耗）
+func (sc *sourceScanner) scanCode(inBraces bool) {
+	depth := 0
+	for sc.pos < len(sc.s) {
+		c := sc.s[sc.pos]
+		switch {
+		case c == '/' && sc.peek(1) == '/':
+			start := sc.pos
+			end := strings.IndexByte(sc.s[sc.pos:], '\n')
+			if end < 0 {
+				sc.pos = len(sc.s)
+			} else {
+				sc.pos += end
+			}
+			sc.add(start, sc.pos, KindComment)
+			continue
+		case c == '/' && sc.peek(1) == '*':
+			start := sc.pos
+			end := strings.Index(sc.s[sc.pos+2:], "*/")
+			if end < 0 {
+				sc.pos = len(sc.s)
+			} else {
+				sc.pos += end + 4
+			}
+			sc.add(start, sc.pos, KindComment)
+			continue
+		case c == '/' && sc.expressionStart():
+			sc.skipRegex()
+			sc.prev, sc.prevWord = '/', ""
+			continue
+		case c == '"' || c == '\'':
+			kind := KindString
+			if sc.prevWord == "from" || sc.prevWord == "import" || strings.HasSuffix(strings.TrimRight(sc.s[:sc.pos], " \t"), "require(") || strings.HasSuffix(strings.TrimRight(sc.s[:sc.pos], " \t"), "import(") {
+				kind = KindImport
+			}
+			sc.scanQuoted(kind)
+			sc.prev, sc.prevWord = c, ""
+			continue
+		case c == '`':
+			sc.scanTemplate()
+			sc.prev, sc.prevWord = c, ""
+			continue
+		case c == '<' && sc.jsx && sc.expressionStart() && (isIdentifierByte(sc.peek(1)) || sc.peek(1) == '>'):
+			sc.scanJSXElement()
+			sc.prev, sc.prevWord = '>', ""
+			continue
+		case c == '{':
+			depth++
+		case c == '}':
+			if depth == 0 && inBraces {
+				return
+			}
+			depth--
+		case isIdentifierByte(c):
+			start := sc.pos
+			for sc.pos < len(sc.s) && isIdentifierByte(sc.s[sc.pos]) {
+				sc.pos++
+			}
+			sc.prevWord = sc.s[start:sc.pos]
+			sc.prev = sc.s[sc.pos-1]
+			continue
+		}
+
+		if c != ' ' && c != '\t' && c != '\n' && c != '\r' {
+			sc.prev = c
+			sc.prevWord = ""
+		}
+		sc.pos++
+	}
+}
+
+// closeBrace 消耗 scanCode(true) 停止处的 '}'
+func (sc *sourceScanner) closeBrace() {
+	if sc.pos < len(sc.s) {
+		sc.pos++
+	}
+}
+
+// scanQuoted 扫描 ' 或 " 字符串，记录引号内的内容
+func (sc *sourceScanner) scanQuoted(kind HitKind) {
+	quote := sc.s[sc.pos]
+	sc.pos++
+	start := sc.pos
+	for sc.pos < len(sc.s) && sc.s[sc.pos] != quote && sc.s[sc.pos] != '\n' {
+		if sc.s[sc.pos] == '\\' {
+			sc.pos++
+		}
+		sc.pos++
+	}
+	if sc.pos > len(sc.s) {
+		sc.pos = len(sc.s)
+	}
+	sc.add(start, sc.pos, kind)
+	if sc.pos < len(sc.s) {
+		sc.pos++
+	}
+}
+
+// scanTemplate 扫描模板字符串，文本部分记为字符串，${} 中按代码扫描
+func (sc *sourceScanner) scanTemplate() {
+	sc.pos++
+	start := sc.pos
+	for sc.pos < len(sc.s) {
+		switch c := sc.s[sc.pos]; {
+		case c == '\\':
+			sc.pos = min(sc.pos+2, len(sc.s))
+		case c == '`':
+			sc.add(start, sc.pos, KindString)
+			sc.pos++
+			return
+		case c == '$' && sc.peek(1) == '{':
+			sc.add(start, sc.pos, KindString)
+			sc.pos += 2
+			sc.prev, sc.prevWord = '{', ""
+			sc.scanCode(true)
+			sc.closeBrace()
+			start = sc.pos
+		default:
+			sc.pos++
+		}
+	}
+	if start < len(sc.s) {
+		sc.add(start, len(sc.s), KindString)
+	}
+}
+
+// skipRegex 跳过正则字面量
+func (sc *sourceScanner) skipRegex() {
+	sc.pos++
+	inClass := false
+	for sc.pos < len(sc.s) && sc.s[sc.pos] != '\n' {
+		switch sc.s[sc.pos] {
+		case '\\':
+			sc.pos++
+		case '[':
+			inClass = true
+		case ']':
+			inClass = false
+		case '/':
+			if !inClass {
+				sc.pos++
+				for sc.pos < len(sc.s) && isIdentifierByte(sc.s[sc.pos]) {
+					sc.pos++
+				}
+				return
+			}
+		}
+		sc.pos++
+	}
+}
+
+// scanJSXElement 扫描从 '<' 开始的 JSX 元素（含子元素）
+func (sc *sourceScanner) scanJSXElement() {
+	if selfClosing := sc.scanJSXTag(); selfClosing {
+		return
+	}
+
+	// 子元素：文本、{表达式}、嵌套元素，直到闭合标签
+	start := sc.pos
+	for sc.pos < len(sc.s) {
+		switch sc.s[sc.pos] {
+		case '{':
+			sc.add(start, sc.pos, KindJSXText)
+			sc.pos++
+			sc.prev, sc.prevWord = '{', ""
+			sc.scanCode(true)
+			sc.closeBrace()
+			start = sc.pos
+		case '<':
+			sc.add(start, sc.pos, KindJSXText)
+			if sc.peek(1) == '/' {
+				if end := strings.IndexByte(sc.s[sc.pos:], '>'); end >= 0 {
+					sc.pos += end + 1
+				} else {
+					sc.pos = len(sc.s)
+				}
+				return
+			}
+			sc.scanJSXElement()
+			start = sc.pos
+		default:
+			sc.pos++
+		}
+	}
+	sc.add(start, sc.pos, KindJSXText)
+}
+
+// scanJSXTag 扫描开始标签，返回是否为自闭合标签
+func (sc *sourceScanner) scanJSXTag() bool {
+	sc.pos++
+	for sc.pos < len(sc.s) {
+		switch c := sc.s[sc.pos]; {
+		case c == '{':
+			sc.pos++
+			sc.prev, sc.prevWord = '{', ""
+			sc.scanCode(true)
+			sc.closeBrace()
+		case c == '"' || c == '\'':
+			sc.scanQuoted(KindString)
+		case c == '/' && sc.peek(1) == '>':
+			sc.pos += 2
+			return true
+		case c == '>':
+			sc.pos++
+			return false
+		default:
+			sc.pos++
+		}
+	}
+	return true
+}
+
+// IsJSXFile 判断文件是否可能包含 JSX
+func IsJSXFile(path string) bool {
+	ext := strings.ToLower(filepath.Ext(path))
+	return ext == ".tsx" || ext == ".jsx"
+}
+
+// isIdentifierByte 判断字节是否可以出现在标识符中
+func isIdentifierByte(c byte) bool {
+	return isASCIIWordByte(c) || c == '$'
+}
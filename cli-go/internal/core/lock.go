@@ -0,0 +1,259 @@
+package core
+
+import (
+	"crypto/sha1"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// LockMode 目录锁模式
+type LockMode int
+
+const (
+	// LockShared 共享锁，只读命令（如 verify）使用，可与其他共享锁同时持有
+	LockShared LockMode = iota
+	// LockExclusive 独占锁，修改源码、构建或部署目录的命令使用
+	LockExclusive
+)
+
+func (m LockMode) String() string {
+	if m == LockExclusive {
+		return "exclusive"
+	}
+	return "shared"
+}
+
+const (
+	exclusiveLockFile = "exclusive.json"
+	sharedLockPrefix  = "shared-"
+	// lockWriteGrace 无法解析的锁文件在此时间内视为正在写入，之后视为损坏的过期锁
+	lockWriteGrace = 10 * time.Second
+)
+
+// LockHolder 锁文件内容，记录持有锁的进程
+type LockHolder struct {
+	PID     int       `json:"pid"`
+	Command string    `json:"command"`
+	Dir     string    `json:"dir"`
+	Mode    string    `json:"mode"`
+	Started time.Time `json:"started"`
+}
+
+// LockBusyError 目录已被其他存活进程锁定
+type LockBusyError struct {
+	Holder LockHolder
+	// Path 对方的锁文件
+	Path string
+}
+
+func (e *LockBusyError) Error() string {
+	return fmt.Sprintf("%s 正被 PID %d (%s) 使用，开始于 %s",
+		e.Holder.Dir, e.Holder.PID, e.Holder.Command, e.Holder.Started.Format("2006-01-02 15:04:05"))
+}
+
+// GetLocksDir 获取锁文件目录
+// 统一使用 ~/.opencode-i18n/locks，支持环境变量覆盖
+func GetLocksDir() (string, error) {
+	// 环境变量 OPENCODE_LOCKS_DIR (开发者可自定义，用于本地调试)
+	if envDir := os.Getenv("OPENCODE_LOCKS_DIR"); envDir != "" {
+		return envDir, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".opencode-i18n", "locks"), nil
+}
+
+// Lock 已获取的目录锁
+// 同一进程内不可重入：其他 goroutine（或嵌套调用）再次获取会像其他进程一样失败，嵌套步骤应沿用外层持有的锁
+type Lock struct {
+	path string
+	once sync.Once
+}
+
+var (
+	// ownMu 串行化本进程内的加锁与释放，ownLocks 记录本进程当前持有的锁文件
+	ownMu    sync.Mutex
+	ownLocks = make(map[string]bool)
+	// sharedSeq 区分本进程持有的多个共享锁文件
+	sharedSeq int
+)
+
+// AcquireLock 获取 dir 的锁，command 记录在锁文件中供其他进程提示
+// 持有者进程已退出的锁视为过期并清理；被其他存活进程或本进程的其他持有者占用时返回 *LockBusyError
+func AcquireLock(dir string, mode LockMode, command string) (*Lock, error) {
+	locksDir, err := GetLocksDir()
+	if err != nil {
+		return nil, err
+	}
+	dir, err = filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	// 目录可能尚未创建（如新工作区），解析父目录，保证创建前后得到相同的锁
+	if resolved, err := filepath.EvalSymlinks(dir); err == nil {
+		dir = resolved
+	} else if parent, err := filepath.EvalSymlinks(filepath.Dir(dir)); err == nil {
+		dir = filepath.Join(parent, filepath.Base(dir))
+	}
+	lockDir := filepath.Join(locksDir, lockKey(dir))
+
+	ownMu.Lock()
+	defer ownMu.Unlock()
+
+	if err := EnsureDir(lockDir); err != nil {
+		return nil, err
+	}
+	holder := LockHolder{PID: os.Getpid(), Command: command, Dir: dir, Mode: mode.String(), Started: time.Now()}
+	var path string
+	if mode == LockExclusive {
+		path, err = acquireExclusive(lockDir, holder)
+	} else {
+		sharedSeq++
+		path, err = acquireShared(lockDir, holder, sharedSeq)
+	}
+	if err != nil {
+		var busy *LockBusyError
+		if errors.As(err, &busy) && busy.Holder.Dir == "" {
+			busy.Holder.Dir = dir
+		}
+		return nil, err
+	}
+	ownLocks[path] = true
+	return &Lock{path: path}, nil
+}
+
+// Release 释放锁，多次调用只生效一次
+func (l *Lock) Release() {
+	l.once.Do(func() {
+		ownMu.Lock()
+		defer ownMu.Unlock()
+
+		// 保留锁目录本身，避免与其他进程正在创建锁文件冲突
+		os.Remove(l.path)
+		delete(ownLocks, l.path)
+	})
+}
+
+// lockKey 返回目录对应的锁目录名（目录名 + 路径哈希，便于辨认且不同路径互不冲突）
+func lockKey(dir string) string {
+	sum := sha1.Sum([]byte(strings.ToLower(filepath.ToSlash(dir))))
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(filepath.Base(dir), "-"), "-.")
+	return name + "-" + hex.EncodeToString(sum[:])[:10]
+}
+
+func sharedLockPath(lockDir string, pid, seq int) string {
+	return filepath.Join(lockDir, fmt.Sprintf("%s%d-%d.json", sharedLockPrefix, pid, seq))
+}
+
+// acquireExclusive 创建独占锁文件，再确认没有其他持有者持有共享锁，返回锁文件路径
+func acquireExclusive(lockDir string, holder LockHolder) (string, error) {
+	path := filepath.Join(lockDir, exclusiveLockFile)
+	for attempt := 0; ; attempt++ {
+		err := createLockFile(path, holder)
+		if err == nil {
+			break
+		}
+		if !os.IsExist(err) {
+			return "", err
+		}
+		if attempt > 0 {
+			return "", fmt.Errorf("锁文件 %s 正被其他进程创建，请稍后重试", path)
+		}
+		// 已存在：对方存活则失败，否则清理过期锁后重试一次
+		if busy := checkLockFile(path); busy != nil {
+			return "", busy
+		}
+	}
+
+	shared, _ := filepath.Glob(filepath.Join(lockDir, sharedLockPrefix+"*.json"))
+	for _, file := range shared {
+		if busy := checkLockFile(file); busy != nil {
+			os.Remove(path)
+			return "", busy
+		}
+	}
+	return path, nil
+}
+
+// acquireShared 确认没有独占锁后写入共享锁文件（每个持有者一个），返回锁文件路径
+func acquireShared(lockDir string, holder LockHolder, seq int) (string, error) {
+	exclusive := filepath.Join(lockDir, exclusiveLockFile)
+	if busy := checkLockFile(exclusive); busy != nil {
+		return "", busy
+	}
+	path := sharedLockPath(lockDir, holder.PID, seq)
+	if err := writeLockFile(path, holder); err != nil {
+		return "", err
+	}
+	// 写入期间可能有进程拿到了独占锁（对方随后会看到本共享锁并放弃，这里同样复查）
+	if busy := checkLockFile(exclusive); busy != nil {
+		os.Remove(path)
+		return "", busy
+	}
+	return path, nil
+}
+
+// checkLockFile 锁文件属于其他存活进程或本进程的其他持有者时返回 *LockBusyError，过期的锁文件被删除
+// 调用方须持有 ownMu
+func checkLockFile(path string) error {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil
+	}
+	var holder LockHolder
+	if data, err := os.ReadFile(path); err != nil || json.Unmarshal(data, &holder) != nil || holder.PID == 0 {
+		if time.Since(info.ModTime()) < lockWriteGrace {
+			return &LockBusyError{Holder: LockHolder{Command: "?", Started: info.ModTime()}, Path: path}
+		}
+		os.Remove(path)
+		return nil
+	}
+	if holder.PID == os.Getpid() {
+		// 本进程的锁文件只有仍被持有时有效，否则来自之前使用相同 PID 的进程
+		if ownLocks[path] {
+			return &LockBusyError{Holder: holder, Path: path}
+		}
+		os.Remove(path)
+		return nil
+	}
+	if !processAlive(holder.PID) {
+		os.Remove(path)
+		return nil
+	}
+	return &LockBusyError{Holder: holder, Path: path}
+}
+
+// createLockFile 原子地创建锁文件：先写临时文件再硬链接，目标已存在时返回 os.ErrExist
+// 其他进程因此不会读到写了一半的锁文件
+func createLockFile(path string, holder LockHolder) error {
+	tmp := fmt.Sprintf("%s.%d.tmp", path, holder.PID)
+	if err := writeLockFile(tmp, holder); err != nil {
+		return err
+	}
+	defer os.Remove(tmp)
+	if err := os.Link(tmp, path); err != nil {
+		if errors.Is(err, os.ErrExist) || Exists(path) {
+			return os.ErrExist
+		}
+		return err
+	}
+	return nil
+}
+
+func writeLockFile(path string, holder LockHolder) error {
+	data, err := json.MarshalIndent(holder, "", "  ")
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(path, data, 0644)
+}
@@ -0,0 +1,193 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// ========== 目录锁测试 ==========
+
+// newLockTarget 创建被锁目录并把锁文件目录指向临时目录，返回被锁目录及其锁目录
+func newLockTarget(t *testing.T) (string, string) {
+	t.Helper()
+	t.Setenv("OPENCODE_LOCKS_DIR", t.TempDir())
+	dir := t.TempDir()
+	resolved, err := filepath.EvalSymlinks(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	locksDir, _ := GetLocksDir()
+	return dir, filepath.Join(locksDir, lockKey(resolved))
+}
+
+// writeForeignLock 模拟其他进程持有的锁文件
+func writeForeignLock(t *testing.T, lockDir, name string, pid int) {
+	t.Helper()
+	if err := EnsureDir(lockDir); err != nil {
+		t.Fatal(err)
+	}
+	holder := LockHolder{PID: pid, Command: "opencode-cli full", Started: time.Now()}
+	if err := writeLockFile(filepath.Join(lockDir, name), holder); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// exitedPID 返回一个已退出进程的 PID
+func exitedPID(t *testing.T) int {
+	t.Helper()
+	cmd := exec.Command("git", "--version")
+	if err := cmd.Run(); err != nil {
+		t.Skip("无法启动子进程")
+	}
+	return cmd.Process.Pid
+}
+
+func TestAcquireLock_BusyAndStale(t *testing.T) {
+	dir, lockDir := newLockTarget(t)
+
+	// 存活的其他进程（go test 的父进程）持有独占锁
+	writeForeignLock(t, lockDir, exclusiveLockFile, os.Getppid())
+	for _, mode := range []LockMode{LockShared, LockExclusive} {
+		_, err := AcquireLock(dir, mode, "test")
+		var busy *LockBusyError
+		if !errors.As(err, &busy) || busy.Holder.PID != os.Getppid() || busy.Holder.Command != "opencode-cli full" {
+			t.Errorf("%s 锁应因占用失败: %v", mode, err)
+		}
+	}
+
+	// 持有者已退出：视为过期锁
+	writeForeignLock(t, lockDir, exclusiveLockFile, exitedPID(t))
+	lock, err := AcquireLock(dir, LockExclusive, "test")
+	if err != nil {
+		t.Fatalf("过期锁应被清理: %v", err)
+	}
+	var holder LockHolder
+	if err := ReadJSON(filepath.Join(lockDir, exclusiveLockFile), &holder); err != nil || holder.PID != os.Getpid() {
+		t.Errorf("锁文件应记录本进程: %+v, %v", holder, err)
+	}
+	lock.Release()
+	if FileExists(filepath.Join(lockDir, exclusiveLockFile)) {
+		t.Error("释放后应删除锁文件")
+	}
+}
+
+func TestAcquireLock_SharedExclusive(t *testing.T) {
+	dir, lockDir := newLockTarget(t)
+
+	// 共享锁可以并存，但会阻止独占锁
+	writeForeignLock(t, lockDir, sharedLockPrefix+"other.json", os.Getppid())
+	shared, err := AcquireLock(dir, LockShared, "verify")
+	if err != nil {
+		t.Fatalf("共享锁应可并存: %v", err)
+	}
+	shared.Release()
+
+	if _, err := AcquireLock(dir, LockExclusive, "update"); err == nil {
+		t.Error("其他进程持有共享锁时独占锁应失败")
+	}
+	if FileExists(filepath.Join(lockDir, exclusiveLockFile)) {
+		t.Error("获取失败时不应留下独占锁文件")
+	}
+}
+
+func TestAcquireLock_SameProcess(t *testing.T) {
+	dir, lockDir := newLockTarget(t)
+
+	// 同一进程内不可重入：嵌套获取与其他进程一样失败
+	outer, err := AcquireLock(dir, LockExclusive, "full")
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, mode := range []LockMode{LockShared, LockExclusive} {
+		_, err := AcquireLock(dir, mode, "verify")
+		var busy *LockBusyError
+		if !errors.As(err, &busy) || busy.Holder.PID != os.Getpid() || busy.Holder.Command != "full" {
+			t.Errorf("本进程持有独占锁时 %s 锁应失败: %v", mode, err)
+		}
+	}
+	outer.Release()
+
+	// 共享锁可以并存，但不能升级为独占锁
+	first, err := AcquireLock(dir, LockShared, "versions")
+	if err != nil {
+		t.Fatal(err)
+	}
+	second, err := AcquireLock(dir, LockShared, "verify")
+	if err != nil {
+		t.Fatalf("共享锁应可并存: %v", err)
+	}
+	if _, err := AcquireLock(dir, LockExclusive, "versions"); err == nil {
+		t.Error("持有共享锁时独占锁应失败")
+	}
+	first.Release()
+	first.Release()
+	if _, err := AcquireLock(dir, LockExclusive, "versions"); err == nil {
+		t.Error("仍有共享锁时独占锁应失败")
+	}
+	second.Release()
+	if files, _ := filepath.Glob(filepath.Join(lockDir, "*.json")); len(files) != 0 {
+		t.Errorf("释放后应删除全部锁文件: %v", files)
+	}
+}
+
+func TestAcquireLock_Goroutines(t *testing.T) {
+	dir, _ := newLockTarget(t)
+
+	// 后台 goroutine 持有独占锁时，前台获取失败，释放后成功
+	held := make(chan *Lock)
+	done := make(chan struct{})
+	go func() {
+		lock, err := AcquireLock(dir, LockExclusive, "菜单后台检查更新")
+		if err != nil {
+			t.Error(err)
+			close(held)
+			return
+		}
+		held <- lock
+		<-done
+		lock.Release()
+		close(held)
+	}()
+	if <-held == nil {
+		t.FailNow()
+	}
+	_, err := AcquireLock(dir, LockExclusive, "update")
+	var busy *LockBusyError
+	if !errors.As(err, &busy) || busy.Holder.Command != "菜单后台检查更新" {
+		t.Errorf("其他 goroutine 持有锁时应失败: %v", err)
+	}
+	close(done)
+	<-held
+	lock, err := AcquireLock(dir, LockExclusive, "update")
+	if err != nil {
+		t.Fatalf("释放后应可获取: %v", err)
+	}
+	lock.Release()
+
+	// 同时争抢时只有一个 goroutine 获取成功
+	const workers = 8
+	results := make(chan *Lock, workers)
+	start := make(chan struct{})
+	for n := 0; n < workers; n++ {
+		go func() {
+			<-start
+			lock, _ := AcquireLock(dir, LockExclusive, "worker")
+			results <- lock
+		}()
+	}
+	close(start)
+	acquired := 0
+	for n := 0; n < workers; n++ {
+		if lock := <-results; lock != nil {
+			acquired++
+			defer lock.Release()
+		}
+	}
+	if acquired != 1 {
+		t.Errorf("应只有 1 个 goroutine 获取独占锁, got %d", acquired)
+	}
+}
@@ -0,0 +1,257 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"sort"
+	"strings"
+)
+
+// ManifestFileName 汉化包元信息文件名
+const ManifestFileName = "config.json"
+
+// PackManifest 汉化包元信息 (config.json)
+type PackManifest struct {
+	Name            string `json:"name"`
+	Version         string `json:"version"`
+	Description     string `json:"description"`
+	LastUpdate      string `json:"lastUpdate"`
+	SupportedCommit string `json:"supportedCommit"`
+	Upstream        struct {
+		Repo    string `json:"repo"`
+		URL     string `json:"url"`
+		Branch  string `json:"branch"`
+		Version string `json:"version"`
+	} `json:"upstream"`
+	// Targets 汉化包覆盖的上游包范围，为空时使用 DefaultTargets
+	Targets []PackTarget `json:"targets,omitempty"`
+	// Modules 按模块分组的配置文件列表（相对汉化包根目录）
+	Modules map[string][]string `json:"modules"`
+	// Flavors 可选的汉化风味，按名称索引
+	Flavors map[string]Flavor `json:"flavors,omitempty"`
+}
+
+// ManifestIssueKind 清单问题类型
+type ManifestIssueKind string
+
+const (
+	// ManifestMissing 清单中列出但磁盘上不存在
+	ManifestMissing ManifestIssueKind = "missing"
+	// ManifestUnlisted 磁盘上存在但清单中未列出
+	ManifestUnlisted ManifestIssueKind = "unlisted"
+	// ManifestInvalid JSON 格式错误
+	ManifestInvalid ManifestIssueKind = "invalid"
+)
+
+// ManifestIssue 清单与磁盘不一致的问题
+type ManifestIssue struct {
+	Kind    ManifestIssueKind
+	Path    string
+	Line    int
+	Column  int
+	Message string
+}
+
+// String 返回问题的可读描述
+func (m ManifestIssue) String() string {
+	switch m.Kind {
+	case ManifestMissing:
+		return fmt.Sprintf("%s: 清单中列出但文件不存在", m.Path)
+	case ManifestUnlisted:
+		return fmt.Sprintf("%s: 文件存在但未在清单 modules 中列出", m.Path)
+	default:
+		if m.Line > 0 {
+			return fmt.Sprintf("%s:%d:%d: JSON 格式错误: %s", m.Path, m.Line, m.Column, m.Message)
+		}
+		return fmt.Sprintf("%s: JSON 格式错误: %s", m.Path, m.Message)
+	}
+}
+
+// packFS 返回汉化包的文件系统视图（显式指定、内嵌资源或外部目录）
+func (i *I18n) packFS() (fs.FS, error) {
+	if i.pack != nil {
+		return i.pack, nil
+	}
+	if i.useEmbedded {
+		return fs.Sub(embeddedAssets, i.i18nDir)
+	}
+	return os.DirFS(i.i18nDir), nil
+}
+
+// PackDir 返回外部汉化包目录，使用内嵌资源或显式文件系统时返回 false
+func (i *I18n) PackDir() (string, bool) {
+	if i.useEmbedded || i.pack != nil {
+		return "", false
+	}
+	return i.i18nDir, true
+}
+
+// LoadManifest 读取汉化包的 config.json
+func (i *I18n) LoadManifest() (*PackManifest, error) {
+	packFS, err := i.packFS()
+	if err != nil {
+		return nil, err
+	}
+	data, err := fs.ReadFile(packFS, ManifestFileName)
+	if err != nil {
+		return nil, err
+	}
+	var manifest PackManifest
+	if err := DecodeJSON(data, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestFileName, err)
+	}
+	return &manifest, nil
+}
+
+// ListPackFiles 列出汉化包中的全部配置文件（相对路径，不含 config.json）
+// 与 LoadConfig 一致：根目录及一级子目录中的 .json 文件
+func ListPackFiles(packFS fs.FS) ([]string, error) {
+	var files []string
+	err := fs.WalkDir(packFS, ".", func(p string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if p != "." && strings.Count(p, "/") >= 1 {
+				return fs.SkipDir
+			}
+			return nil
+		}
+		if strings.HasSuffix(p, ".json") && p != ManifestFileName {
+			files = append(files, p)
+		}
+		return nil
+	})
+	sort.Strings(files)
+	return files, err
+}
+
+// moduleName 返回配置文件所属的模块名（一级目录名，根目录为 root）
+func moduleName(file string) string {
+	if dir := path.Dir(file); dir != "." {
+		return dir
+	}
+	return "root"
+}
+
+// CheckManifest 对比 config.json 的 modules 与磁盘上的配置文件
+// 报告清单中缺失的文件、未列出的文件，以及带行列号的 JSON 格式错误
+func (i *I18n) CheckManifest() ([]ManifestIssue, error) {
+	packFS, err := i.packFS()
+	if err != nil {
+		return nil, err
+	}
+
+	files, err := ListPackFiles(packFS)
+	if err != nil {
+		return nil, err
+	}
+
+	var issues []ManifestIssue
+
+	// 所有文件（包括 config.json）的 JSON 格式
+	for _, file := range append([]string{ManifestFileName}, files...) {
+		data, err := fs.ReadFile(packFS, file)
+		if err != nil {
+			continue
+		}
+		var v interface{}
+		if err := DecodeJSON(data, &v); err != nil {
+			issue := ManifestIssue{Kind: ManifestInvalid, Path: file, Message: err.Error()}
+			var posErr *JSONPositionError
+			if errors.As(err, &posErr) {
+				issue.Line, issue.Column, issue.Message = posErr.Line, posErr.Column, posErr.Err.Error()
+			}
+			issues = append(issues, issue)
+		}
+	}
+
+	manifest, err := i.LoadManifest()
+	if err != nil {
+		// config.json 本身损坏时已在上面报告
+		return issues, nil
+	}
+
+	listed := make(map[string]bool)
+	for _, module := range sortedKeys(manifest.Modules) {
+		for _, file := range manifest.Modules[module] {
+			listed[file] = true
+			if _, err := fs.Stat(packFS, file); err != nil {
+				issues = append(issues, ManifestIssue{Kind: ManifestMissing, Path: file})
+			}
+		}
+	}
+
+	for _, file := range files {
+		if !listed[file] {
+			issues = append(issues, ManifestIssue{Kind: ManifestUnlisted, Path: file})
+		}
+	}
+
+	return issues, nil
+}
+
+// JSONPositionError 带行列号的 JSON 解析错误
+type JSONPositionError struct {
+	Line   int
+	Column int
+	Err    error
+}
+
+func (e *JSONPositionError) Error() string {
+	return fmt.Sprintf("第 %d 行第 %d 列: %v", e.Line, e.Column, e.Err)
+}
+
+func (e *JSONPositionError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSON 解析 JSON，语法或类型错误时附带行列号
+func DecodeJSON(data []byte, v interface{}) error {
+	err := json.Unmarshal(data, v)
+	if err == nil {
+		return nil
+	}
+
+	var offset int64 = -1
+	var syntaxErr *json.SyntaxError
+	var typeErr *json.UnmarshalTypeError
+	if errors.As(err, &syntaxErr) {
+		offset = syntaxErr.Offset
+	} else if errors.As(err, &typeErr) {
+		offset = typeErr.Offset
+	}
+	if offset < 0 {
+		return err
+	}
+
+	line, column := offsetToLineColumn(data, offset)
+	return &JSONPositionError{Line: line, Column: column, Err: err}
+}
+
+// offsetToLineColumn 将字节偏移转换为 1 起始的行列号（列按字符计）
+func offsetToLineColumn(data []byte, offset int64) (int, int) {
+	if offset > int64(len(data)) {
+		offset = int64(len(data))
+	}
+	before := data[:offset]
+	line := bytes.Count(before, []byte("\n")) + 1
+	lineStart := bytes.LastIndexByte(before, '\n') + 1
+	column := len([]rune(string(before[lineStart:]))) + 1
+	return line, column
+}
+
+// sortedKeys 返回 map 的有序键列表
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
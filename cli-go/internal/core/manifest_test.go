@@ -0,0 +1,128 @@
+package core
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+// writePackFile 在临时汉化包目录中写入文件
+func writePackFile(t *testing.T, dir, rel, content string) {
+	t.Helper()
+	path := filepath.Join(dir, filepath.FromSlash(rel))
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatalf("创建目录失败: %v", err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatalf("创建测试文件失败: %v", err)
+	}
+}
+
+// readFileString 读取临时目录中的文件内容
+func readFileString(t *testing.T, dir, rel string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, filepath.FromSlash(rel)))
+	if err != nil {
+		t.Fatalf("读取文件失败: %v", err)
+	}
+	return string(data)
+}
+
+// ========== CheckManifest 测试 ==========
+
+func TestCheckManifest_MissingAndUnlisted(t *testing.T) {
+	packDir := t.TempDir()
+	writePackFile(t, packDir, "config.json", `{
+  "modules": {
+    "dialogs": ["dialogs/dialog-a.json", "dialogs/dialog-gone.json"]
+  }
+}`)
+	writePackFile(t, packDir, "dialogs/dialog-a.json", `{"file": "a.tsx", "replacements": {}}`)
+	writePackFile(t, packDir, "dialogs/dialog-new.json", `{"file": "b.tsx", "replacements": {}}`)
+	writePackFile(t, packDir, "dialogs/dialog-bad.json", "{\n  \"file\": \"c.tsx\",\n  \"replacements\": {,}\n}")
+
+	i18n := &I18n{i18nDir: packDir}
+	issues, err := i18n.CheckManifest()
+	if err != nil {
+		t.Fatalf("CheckManifest 失败: %v", err)
+	}
+
+	found := make(map[ManifestIssueKind][]ManifestIssue)
+	for _, issue := range issues {
+		found[issue.Kind] = append(found[issue.Kind], issue)
+	}
+
+	if len(found[ManifestMissing]) != 1 || found[ManifestMissing][0].Path != "dialogs/dialog-gone.json" {
+		t.Errorf("缺失文件报告错误: %v", found[ManifestMissing])
+	}
+	// dialog-new.json 与 dialog-bad.json 都未列出
+	if len(found[ManifestUnlisted]) != 2 {
+		t.Errorf("未列出文件报告错误: %v", found[ManifestUnlisted])
+	}
+	if len(found[ManifestInvalid]) != 1 || found[ManifestInvalid][0].Line != 3 {
+		t.Errorf("JSON 错误应报告在第 3 行: %v", found[ManifestInvalid])
+	}
+}
+
+// ========== DecodeJSON 测试 ==========
+
+func TestDecodeJSON_Position(t *testing.T) {
+	var v map[string]string
+	err := DecodeJSON([]byte("{\n  \"a\": \"b\"\n  \"c\": \"d\"\n}"), &v)
+
+	var posErr *JSONPositionError
+	if !errors.As(err, &posErr) {
+		t.Fatalf("应返回 JSONPositionError, got %v", err)
+	}
+	if posErr.Line != 3 {
+		t.Errorf("行号错误: got %d, want 3", posErr.Line)
+	}
+}
+
+// ========== FormatJSON / FormatPack 测试 ==========
+
+func TestFormatJSON_CanonicalOrder(t *testing.T) {
+	input := `{"replacements": {"b": "<B>", "a": "A"}, "description": "d", "file": "x.tsx"}`
+	want := `{
+  "file": "x.tsx",
+  "description": "d",
+  "replacements": {
+    "a": "A",
+    "b": "<B>"
+  }
+}
+`
+	got, err := FormatJSON([]byte(input))
+	if err != nil {
+		t.Fatalf("FormatJSON 失败: %v", err)
+	}
+	if string(got) != want {
+		t.Errorf("格式化结果错误:\ngot:\n%s\nwant:\n%s", got, want)
+	}
+}
+
+func TestFormatPack_RewritesModules(t *testing.T) {
+	packDir := t.TempDir()
+	writePackFile(t, packDir, "config.json", `{"name": "pack", "modules": {"dialogs": ["dialogs/gone.json"]}}`)
+	writePackFile(t, packDir, "app.json", `{"file": "app.tsx", "replacements": {}}`)
+	writePackFile(t, packDir, "dialogs/dialog-a.json", `{"file": "a.tsx", "replacements": {}}`)
+
+	changed, err := FormatPack(packDir, false)
+	if err != nil {
+		t.Fatalf("FormatPack 失败: %v", err)
+	}
+	if len(changed) != 3 {
+		t.Errorf("应修改 3 个文件, got %v", changed)
+	}
+
+	i18n := &I18n{i18nDir: packDir}
+	if issues, _ := i18n.CheckManifest(); len(issues) != 0 {
+		t.Errorf("格式化后清单应与磁盘一致: %v", issues)
+	}
+
+	// 再次运行应无修改
+	if changed, _ := FormatPack(packDir, true); len(changed) != 0 {
+		t.Errorf("格式化应幂等, got %v", changed)
+	}
+}
@@ -2,6 +2,7 @@ package core
 
 import (
 	"archive/zip"
+	"context"
 	"crypto/md5"
 	"crypto/sha256"
 	"encoding/hex"
@@ -129,8 +130,23 @@ func ZipDirectory(source, target string) error {
 	})
 }
 
+// archiveBaseName 返回平台压缩包的文件名（不含扩展名）
+func (p *Packager) archiveBaseName(platform string) string {
+	return fmt.Sprintf("opencode-zh-CN-v%s-%s", p.version, platform)
+}
+
+// PartialFiles 返回打包 platform 过程中产生的临时目录和压缩包路径，供中断后清理
+func (p *Packager) PartialFiles(platform string, versionDir string) []string {
+	baseName := p.archiveBaseName(platform)
+	return []string{
+		filepath.Join(versionDir, "temp", baseName),
+		filepath.Join(versionDir, baseName+".zip"),
+	}
+}
+
 // PackagePlatform 打包单个平台
-func (p *Packager) PackagePlatform(platform string, versionDir string) (*PackageInfo, error) {
+// ctx 取消时终止编译，并删除未完成的临时目录和压缩包
+func (p *Packager) PackagePlatform(ctx context.Context, platform string, versionDir string) (*PackageInfo, error) {
 	fmt.Printf("打包 %s...\n", platform)
 
 	// 触发编译
@@ -142,7 +158,7 @@ func (p *Packager) PackagePlatform(platform string, versionDir string) (*Package
 	distPath := builder.GetDistPath(platform)
 	if !Exists(distPath) {
 		fmt.Printf("  编译产物不存在，正在编译 %s...\n", platform)
-		if err := builder.Build(platform, false); err != nil {
+		if err := builder.Build(ctx, platform, false); err != nil {
 			return nil, err
 		}
 	}
@@ -151,7 +167,7 @@ func (p *Packager) PackagePlatform(platform string, versionDir string) (*Package
 		return nil, fmt.Errorf("编译产物仍不存在: %s", distPath)
 	}
 
-	baseName := fmt.Sprintf("opencode-zh-CN-v%s-%s", p.version, platform)
+	baseName := p.archiveBaseName(platform)
 	tempDir := filepath.Join(versionDir, "temp", baseName)
 	
 	// 清理并创建临时目录
@@ -159,6 +175,8 @@ func (p *Packager) PackagePlatform(platform string, versionDir string) (*Package
 	if err := EnsureDir(tempDir); err != nil {
 		return nil, err
 	}
+	// 无论成功、失败还是中断都清理临时目录
+	defer os.RemoveAll(tempDir)
 
 	// 复制二进制文件
 	binName := filepath.Base(distPath)
@@ -171,13 +189,16 @@ func (p *Packager) PackagePlatform(platform string, versionDir string) (*Package
 	outputPath := filepath.Join(versionDir, baseName+".zip")
 	os.Remove(outputPath) // 删除旧文件
 
-	if err := ZipDirectory(tempDir, outputPath); err != nil {
+	err = ZipDirectory(tempDir, outputPath)
+	if err == nil {
+		err = ctx.Err()
+	}
+	if err != nil {
+		// 不留下不完整的压缩包
+		os.Remove(outputPath)
 		return nil, fmt.Errorf("压缩失败: %v", err)
 	}
 
-	// 清理临时目录
-	os.RemoveAll(tempDir)
-
 	// 计算信息
 	fileInfo, err := os.Stat(outputPath)
 	if err != nil {
@@ -0,0 +1,222 @@
+package core
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// canonicalKeyOrder 规范键顺序，按父级键名索引（"" 为顶层）
+// 未列出的键排在已知键之后，按字母顺序排列
+var canonicalKeyOrder = map[string][]string{
+	"": {
+		"file", "target", "maxMatches", "name", "version", "description", "note", "lastUpdate", "testPassRate",
+		"upstream", "supportedCommit", "maintainer", "targets", "modules", "flavors", "maxWidthRatio", "occurrences", "fileOccurrences", "replacements",
+	},
+	"upstream":   {"repo", "url", "branch", "version"},
+	"maintainer": {"name", "wechat", "github"},
+	"targets":    {"name", "root", "include", "extensions"},
+}
+
+// FormatJSON 以规范格式重新输出 JSON：两空格缩进、固定键顺序、不转义 HTML 字符
+func FormatJSON(data []byte) ([]byte, error) {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.UseNumber()
+
+	var v interface{}
+	if err := decoder.Decode(&v); err != nil {
+		// 重新解析以获得行列号
+		if posErr := DecodeJSON(data, &v); posErr != nil {
+			return nil, posErr
+		}
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	if err := writeCanonicalJSON(&buf, v, "", 0); err != nil {
+		return nil, err
+	}
+	buf.WriteByte('\n')
+	return buf.Bytes(), nil
+}
+
+// writeCanonicalJSON 递归输出 JSON 值
+func writeCanonicalJSON(buf *bytes.Buffer, v interface{}, parentKey string, indent int) error {
+	pad := strings.Repeat("  ", indent)
+
+	switch val := v.(type) {
+	case map[string]interface{}:
+		if len(val) == 0 {
+			buf.WriteString("{}")
+			return nil
+		}
+		buf.WriteString("{\n")
+		keys := canonicalKeys(val, parentKey)
+		for idx, key := range keys {
+			buf.WriteString(pad + "  ")
+			writeJSONString(buf, key)
+			buf.WriteString(": ")
+			if err := writeCanonicalJSON(buf, val[key], key, indent+1); err != nil {
+				return err
+			}
+			if idx < len(keys)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(pad + "}")
+
+	case []interface{}:
+		if len(val) == 0 {
+			buf.WriteString("[]")
+			return nil
+		}
+		buf.WriteString("[\n")
+		for idx, item := range val {
+			buf.WriteString(pad + "  ")
+			if err := writeCanonicalJSON(buf, item, parentKey, indent+1); err != nil {
+				return err
+			}
+			if idx < len(val)-1 {
+				buf.WriteByte(',')
+			}
+			buf.WriteByte('\n')
+		}
+		buf.WriteString(pad + "]")
+
+	case string:
+		writeJSONString(buf, val)
+
+	case json.Number:
+		buf.WriteString(val.String())
+
+	case bool:
+		if val {
+			buf.WriteString("true")
+		} else {
+			buf.WriteString("false")
+		}
+
+	case nil:
+		buf.WriteString("null")
+
+	default:
+		return fmt.Errorf("不支持的 JSON 值类型: %T", v)
+	}
+
+	return nil
+}
+
+// canonicalKeys 返回对象键的规范顺序
+func canonicalKeys(obj map[string]interface{}, parentKey string) []string {
+	known := canonicalKeyOrder[parentKey]
+	rank := make(map[string]int, len(known))
+	for idx, key := range known {
+		rank[key] = idx
+	}
+
+	keys := make([]string, 0, len(obj))
+	for key := range obj {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(a, b int) bool {
+		ra, okA := rank[keys[a]]
+		rb, okB := rank[keys[b]]
+		switch {
+		case okA && okB:
+			return ra < rb
+		case okA != okB:
+			return okA
+		default:
+			return keys[a] < keys[b]
+		}
+	})
+	return keys
+}
+
+// writeJSONString 输出 JSON 字符串（不转义 <、>、&）
+func writeJSONString(buf *bytes.Buffer, s string) {
+	var tmp bytes.Buffer
+	encoder := json.NewEncoder(&tmp)
+	encoder.SetEscapeHTML(false)
+	encoder.Encode(s)
+	buf.Write(bytes.TrimRight(tmp.Bytes(), "\n"))
+}
+
+// FormatPack 规范化外部汉化包目录
+// 按磁盘上的文件重写 config.json 的 modules，并统一所有 JSON 文件的缩进与键顺序
+// check 为 true 时不写入，只返回需要修改的文件
+func FormatPack(dir string, check bool) ([]string, error) {
+	files, err := ListPackFiles(os.DirFS(dir))
+	if err != nil {
+		return nil, err
+	}
+
+	var changed []string
+
+	// 1. config.json：重写 modules
+	manifestPath := filepath.Join(dir, ManifestFileName)
+	manifestData, err := os.ReadFile(manifestPath)
+	if err != nil {
+		return nil, err
+	}
+	var manifest map[string]interface{}
+	if err := DecodeJSON(manifestData, &manifest); err != nil {
+		return nil, fmt.Errorf("%s: %w", ManifestFileName, err)
+	}
+	modules := make(map[string]interface{})
+	for _, file := range files {
+		name := moduleName(file)
+		list, _ := modules[name].([]interface{})
+		modules[name] = append(list, file)
+	}
+	manifest["modules"] = modules
+
+	// 重新编码后交给 FormatJSON，保证与其他文件使用同一套规则
+	manifestJSON, err := json.Marshal(manifest)
+	if err != nil {
+		return nil, err
+	}
+	if changedFile, err := formatPackFile(manifestPath, manifestData, manifestJSON, check); err != nil {
+		return nil, err
+	} else if changedFile {
+		changed = append(changed, ManifestFileName)
+	}
+
+	// 2. 各配置文件
+	for _, file := range files {
+		fullPath := filepath.Join(dir, filepath.FromSlash(file))
+		data, err := os.ReadFile(fullPath)
+		if err != nil {
+			return nil, err
+		}
+		changedFile, err := formatPackFile(fullPath, data, data, check)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", file, err)
+		}
+		if changedFile {
+			changed = append(changed, file)
+		}
+	}
+
+	return changed, nil
+}
+
+// formatPackFile 格式化单个文件，original 为磁盘原内容，source 为待格式化内容
+func formatPackFile(fullPath string, original, source []byte, check bool) (bool, error) {
+	formatted, err := FormatJSON(source)
+	if err != nil {
+		return false, err
+	}
+	if bytes.Equal(formatted, original) {
+		return false, nil
+	}
+	if check {
+		return true, nil
+	}
+	return true, os.WriteFile(fullPath, formatted, 0644)
+}
@@ -0,0 +1,255 @@
+package core
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode"
+)
+
+// PlaceholderKind 占位符类型
+type PlaceholderKind string
+
+const (
+	// PlaceholderTemplate 模板字符串插值 ${expr}
+	PlaceholderTemplate PlaceholderKind = "template"
+	// PlaceholderJSX JSX 表达式 {expr}
+	PlaceholderJSX PlaceholderKind = "jsx"
+	// PlaceholderPrintf printf 风格占位符 %s / %d / %1$s
+	PlaceholderPrintf PlaceholderKind = "printf"
+)
+
+// Placeholder 文本中的一个占位符
+// Expr 为规范化后的表达式：去掉空白，字符串字面量内容被清空（可翻译文本不参与比较）
+type Placeholder struct {
+	Kind PlaceholderKind
+	Expr string
+}
+
+// String 返回占位符的可读形式
+func (p Placeholder) String() string {
+	switch p.Kind {
+	case PlaceholderTemplate:
+		return "${" + p.Expr + "}"
+	case PlaceholderJSX:
+		return "{" + p.Expr + "}"
+	default:
+		return p.Expr
+	}
+}
+
+// printfVerbPattern printf 占位符；不支持空格标志，避免把 "50% done" 之类的普通文本误认为 "% d"
+var printfVerbPattern = regexp.MustCompile(`^%(\d+\$)?[-+#0]*\d*(\.\d+)?[sdifvxXoqcbeEgGtTp]`)
+
+// ExtractPlaceholders 提取文本中的全部占位符
+// 支持模板插值 ${...}、JSX 表达式 {...}（括号配对，识别表达式内的字符串）和 printf 占位符
+func ExtractPlaceholders(s string) []Placeholder {
+	var result []Placeholder
+	runes := []rune(s)
+
+	for i := 0; i < len(runes); i++ {
+		switch c := runes[i]; {
+		case c == '$' && i+1 < len(runes) && runes[i+1] == '{':
+			if end := matchBrace(runes, i+1); end > 0 {
+				result = append(result, Placeholder{
+					Kind: PlaceholderTemplate,
+					Expr: normalizeExpression(runes[i+2 : end]),
+				})
+				i = end
+			}
+		case c == '{':
+			if end := matchBrace(runes, i); end > 0 {
+				result = append(result, Placeholder{
+					Kind: PlaceholderJSX,
+					Expr: normalizeExpression(runes[i+1 : end]),
+				})
+				i = end
+			}
+		case c == '%':
+			if i+1 < len(runes) && runes[i+1] == '%' {
+				i++ // %% 是转义的百分号
+				continue
+			}
+			if verb := printfVerbPattern.FindString(string(runes[i:])); verb != "" {
+				result = append(result, Placeholder{Kind: PlaceholderPrintf, Expr: verb})
+				i += len([]rune(verb)) - 1
+			}
+		}
+	}
+
+	return result
+}
+
+// matchBrace 从 start 处的 '{' 开始查找配对的 '}'，跳过表达式内的字符串字面量
+// 返回配对位置，未配对时返回 -1
+func matchBrace(runes []rune, start int) int {
+	depth := 0
+	for i := start; i < len(runes); i++ {
+		switch runes[i] {
+		case '{':
+			depth++
+		case '}':
+			depth--
+			if depth == 0 {
+				return i
+			}
+		case '"', '\'', '`':
+			end := skipStringLiteral(runes, i)
+			if end < 0 {
+				return -1
+			}
+			i = end
+		}
+	}
+	return -1
+}
+
+// skipStringLiteral 跳过 start 处开始的字符串字面量，返回结束引号位置
+// 模板字符串中的 ${...} 会递归配对
+func skipStringLiteral(runes []rune, start int) int {
+	quote := runes[start]
+	for i := start + 1; i < len(runes); i++ {
+		switch {
+		case runes[i] == '\\':
+			i++
+		case runes[i] == quote:
+			return i
+		case quote == '`' && runes[i] == '$' && i+1 < len(runes) && runes[i+1] == '{':
+			end := matchBrace(runes, i+1)
+			if end < 0 {
+				return -1
+			}
+			i = end
+		}
+	}
+	return -1
+}
+
+// normalizeExpression 规范化表达式：去掉空白并清空字符串字面量内容
+// 模板字符串只保留其中的 ${...} 插值
+func normalizeExpression(runes []rune) string {
+	var sb strings.Builder
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		switch {
+		case unicode.IsSpace(c):
+			continue
+		case c == '"' || c == '\'':
+			end := skipStringLiteral(runes, i)
+			if end < 0 {
+				end = len(runes) - 1
+			}
+			sb.WriteRune(c)
+			sb.WriteRune(c)
+			i = end
+		case c == '`':
+			end := skipStringLiteral(runes, i)
+			if end < 0 {
+				end = len(runes) - 1
+			}
+			sb.WriteRune('`')
+			for _, p := range ExtractPlaceholders(string(runes[i+1 : end])) {
+				if p.Kind == PlaceholderTemplate {
+					sb.WriteString(p.String())
+				}
+			}
+			sb.WriteRune('`')
+			i = end
+		default:
+			sb.WriteRune(c)
+		}
+	}
+	return sb.String()
+}
+
+// PlaceholderChange 原文占位符在译文中被修改
+type PlaceholderChange struct {
+	From Placeholder
+	To   Placeholder
+}
+
+// PlaceholderDiff 原文与译文的占位符差异
+type PlaceholderDiff struct {
+	Missing    []Placeholder       // 译文中丢失
+	Duplicated []Placeholder       // 译文中重复出现
+	Added      []Placeholder       // 译文中新增（原文没有）
+	Changed    []PlaceholderChange // 译文中被修改
+}
+
+// OK 占位符集合是否一致（允许调整顺序）
+func (d PlaceholderDiff) OK() bool {
+	return len(d.Missing) == 0 && len(d.Duplicated) == 0 && len(d.Added) == 0 && len(d.Changed) == 0
+}
+
+// Problems 返回可读的问题描述
+func (d PlaceholderDiff) Problems() []string {
+	var problems []string
+	for _, p := range d.Missing {
+		problems = append(problems, fmt.Sprintf("丢失 %s", p))
+	}
+	for _, p := range d.Duplicated {
+		problems = append(problems, fmt.Sprintf("重复 %s", p))
+	}
+	for _, p := range d.Added {
+		problems = append(problems, fmt.Sprintf("新增 %s", p))
+	}
+	for _, c := range d.Changed {
+		problems = append(problems, fmt.Sprintf("修改 %s → %s", c.From, c.To))
+	}
+	return problems
+}
+
+// ComparePlaceholders 比较原文和译文的占位符多重集合
+// 顺序调整是允许的；丢失、重复、修改都视为错误
+func ComparePlaceholders(from, to string) PlaceholderDiff {
+	fromList := ExtractPlaceholders(from)
+	toList := ExtractPlaceholders(to)
+
+	remaining := make(map[Placeholder]int)
+	original := make(map[Placeholder]bool)
+	for _, p := range fromList {
+		remaining[p]++
+		original[p] = true
+	}
+
+	var extra []Placeholder
+	for _, p := range toList {
+		if remaining[p] > 0 {
+			remaining[p]--
+		} else {
+			extra = append(extra, p)
+		}
+	}
+
+	var missing []Placeholder
+	for _, p := range fromList {
+		if remaining[p] > 0 {
+			remaining[p]--
+			missing = append(missing, p)
+		}
+	}
+
+	var diff PlaceholderDiff
+	for _, p := range extra {
+		if original[p] {
+			diff.Duplicated = append(diff.Duplicated, p)
+			continue
+		}
+		// 同类型的丢失与新增配对为"修改"
+		paired := false
+		for i, m := range missing {
+			if m.Kind == p.Kind {
+				diff.Changed = append(diff.Changed, PlaceholderChange{From: m, To: p})
+				missing = append(missing[:i], missing[i+1:]...)
+				paired = true
+				break
+			}
+		}
+		if !paired {
+			diff.Added = append(diff.Added, p)
+		}
+	}
+	diff.Missing = missing
+
+	return diff
+}
@@ -0,0 +1,75 @@
+package core
+
+import "testing"
+
+// ========== ExtractPlaceholders 测试 ==========
+
+func TestExtractPlaceholders_Kinds(t *testing.T) {
+	list := ExtractPlaceholders("`${props.count} items` <b>{value()}</b> %s %1$d 100%%")
+
+	want := []Placeholder{
+		{Kind: PlaceholderTemplate, Expr: "props.count"},
+		{Kind: PlaceholderJSX, Expr: "value()"},
+		{Kind: PlaceholderPrintf, Expr: "%s"},
+		{Kind: PlaceholderPrintf, Expr: "%1$d"},
+	}
+	if len(list) != len(want) {
+		t.Fatalf("占位符数量错误: got %v, want %v", list, want)
+	}
+	for i := range want {
+		if list[i] != want[i] {
+			t.Errorf("占位符 %d 错误: got %v, want %v", i, list[i], want[i])
+		}
+	}
+}
+
+func TestExtractPlaceholders_PercentProse(t *testing.T) {
+	// 百分号后跟空格的普通文本不是 printf 占位符
+	for _, text := range []string{"50% done", "100% sure", "Progress: 20% (3 of 15) in total"} {
+		if list := ExtractPlaceholders(text); len(list) != 0 {
+			t.Errorf("%q 不应包含占位符: %v", text, list)
+		}
+	}
+	if diff := ComparePlaceholders("50% done", "已完成50%"); !diff.OK() {
+		t.Errorf("改写语序不应报告占位符丢失: %v", diff.Problems())
+	}
+}
+
+func TestExtractPlaceholders_StringContentIgnored(t *testing.T) {
+	// 表达式中字符串字面量的内容是可翻译文本，不参与比较
+	from := ExtractPlaceholders(`{ok() ? "Yes" : "No {x}"}`)
+	to := ExtractPlaceholders(`{ok() ? "是" : "否"}`)
+	if len(from) != 1 || len(to) != 1 || from[0] != to[0] {
+		t.Errorf("字符串内容不应影响表达式: from=%v, to=%v", from, to)
+	}
+}
+
+// ========== ComparePlaceholders 测试 ==========
+
+func TestComparePlaceholders_ReorderAllowed(t *testing.T) {
+	diff := ComparePlaceholders("{a} of {b}", "{b} 中的 {a}")
+	if !diff.OK() {
+		t.Errorf("调整顺序应允许: %v", diff.Problems())
+	}
+}
+
+func TestComparePlaceholders_Errors(t *testing.T) {
+	tests := []struct {
+		name  string
+		from  string
+		to    string
+		check func(PlaceholderDiff) bool
+	}{
+		{"丢失", "${count} files", "文件", func(d PlaceholderDiff) bool { return len(d.Missing) == 1 }},
+		{"重复", "{name}", "{name}{name}", func(d PlaceholderDiff) bool { return len(d.Duplicated) == 1 }},
+		{"修改", "${props.count}", "${props.total}", func(d PlaceholderDiff) bool { return len(d.Changed) == 1 }},
+		{"printf 丢失", "%s selected", "已选择", func(d PlaceholderDiff) bool { return len(d.Missing) == 1 }},
+	}
+
+	for _, tt := range tests {
+		diff := ComparePlaceholders(tt.from, tt.to)
+		if diff.OK() || !tt.check(diff) {
+			t.Errorf("%s: 差异不正确 %+v", tt.name, diff)
+		}
+	}
+}
@@ -0,0 +1,19 @@
+//go:build !windows
+// +build !windows
+
+package core
+
+import (
+	"errors"
+	"syscall"
+)
+
+// processAlive 判断进程是否仍在运行（用于检测过期的锁文件）
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	// 信号 0 只做存在性和权限检查；EPERM 表示进程存在但属于其他用户
+	err := syscall.Kill(pid, 0)
+	return err == nil || errors.Is(err, syscall.EPERM)
+}
@@ -0,0 +1,30 @@
+//go:build windows
+// +build windows
+
+package core
+
+import (
+	"golang.org/x/sys/windows"
+)
+
+// stillActive GetExitCodeProcess 对仍在运行的进程返回的退出码 (STILL_ACTIVE)
+const stillActive = 259
+
+// processAlive 判断进程是否仍在运行（用于检测过期的锁文件）
+func processAlive(pid int) bool {
+	if pid <= 0 {
+		return false
+	}
+	handle, err := windows.OpenProcess(windows.PROCESS_QUERY_LIMITED_INFORMATION, false, uint32(pid))
+	if err != nil {
+		// 无权访问说明进程存在
+		return err == windows.ERROR_ACCESS_DENIED
+	}
+	defer windows.CloseHandle(handle)
+
+	var code uint32
+	if err := windows.GetExitCodeProcess(handle, &code); err != nil {
+		return false
+	}
+	return code == stillActive
+}
@@ -0,0 +1,218 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+)
+
+// 伪本地化标记：被汉化包覆盖的文本会被包裹在标记中
+const (
+	pseudoPrefix = "[!! "
+	pseudoSuffix = " !!]"
+	// pseudoPadding 填充字符，在标记内把文本加长 pseudoExpansion%，使截断和宽度问题在界面上显现
+	pseudoPadding   = '~'
+	pseudoExpansion = 35
+)
+
+// pseudoAccents ASCII 字母到带重音字符的映射
+var pseudoAccents = map[rune]rune{
+	'a': 'à', 'b': 'ƀ', 'c': 'ç', 'd': 'ð', 'e': 'é', 'f': 'ƒ', 'g': 'ĝ', 'h': 'ĥ', 'i': 'î',
+	'j': 'ĵ', 'k': 'ķ', 'l': 'ļ', 'm': 'ṁ', 'n': 'ñ', 'o': 'ö', 'p': 'þ', 'q': 'ǫ', 'r': 'ŕ',
+	's': 'ŝ', 't': 'ţ', 'u': 'û', 'v': 'ṽ', 'w': 'ŵ', 'x': 'ẋ', 'y': 'ý', 'z': 'ž',
+	'A': 'À', 'B': 'Ɓ', 'C': 'Ç', 'D': 'Ð', 'E': 'É', 'F': 'Ƒ', 'G': 'Ĝ', 'H': 'Ĥ', 'I': 'Î',
+	'J': 'Ĵ', 'K': 'Ķ', 'L': 'Ļ', 'M': 'Ṁ', 'N': 'Ñ', 'O': 'Ö', 'P': 'Ƥ', 'Q': 'Ǫ', 'R': 'Ŕ',
+	'S': 'Ŝ', 'T': 'Ţ', 'U': 'Û', 'V': 'Ṽ', 'W': 'Ŵ', 'X': 'Ẋ', 'Y': 'Ý', 'Z': 'Ž',
+}
+
+// pseudoLocalize 将文本转换为伪本地化形式，如 "Select model" -> "[!! Ŝéļéçţ ṁöðéļ ~~~~~ !!]"
+// 占位符 (${...}、{...}、%s) 和转义序列保持不变
+func pseudoLocalize(text string) string {
+	out, ok := pseudoText([]rune(text), false)
+	if !ok {
+		return text
+	}
+	return out
+}
+
+// PseudoSource 生成规则原文对应的伪本地化译文
+// 原文包含字符串字面量时只转换字面量内容，否则视为 JSX 文本（跳过标签与表达式）
+// 原文中没有可转换的文本时返回 false
+func PseudoSource(from string) (string, bool) {
+	runes := []rune(from)
+	if !hasStringLiteral(runes) {
+		return pseudoText(runes, true)
+	}
+
+	var sb strings.Builder
+	changed := false
+	for i := 0; i < len(runes); i++ {
+		if !isQuoteStart(runes, i) {
+			sb.WriteRune(runes[i])
+			continue
+		}
+
+		end := skipStringLiteral(runes, i)
+		closed := end >= 0
+		if !closed {
+			end = len(runes)
+		}
+
+		sb.WriteRune(runes[i])
+		if content, ok := pseudoText(runes[i+1:end], false); ok {
+			sb.WriteString(content)
+			changed = true
+		} else {
+			sb.WriteString(string(runes[i+1 : end]))
+		}
+		if closed {
+			sb.WriteRune(runes[end])
+		}
+		i = end
+	}
+
+	return sb.String(), changed
+}
+
+// PseudoConfigs 基于汉化包生成伪本地化配置：每条规则的译文替换为原文的伪本地化形式
+func PseudoConfigs(configs []TranslationConfig) []TranslationConfig {
+	result := make([]TranslationConfig, 0, len(configs))
+	for _, config := range configs {
+		pseudo := config
+		pseudo.Replacements = make(map[string]string, len(config.Replacements))
+		for from := range config.Replacements {
+			if to, ok := PseudoSource(from); ok {
+				pseudo.Replacements[from] = to
+			}
+		}
+		result = append(result, pseudo)
+	}
+	return result
+}
+
+// hasStringLiteral 判断原文是否为包含字符串字面量的代码
+// 以第一个引号字符为准：位于代码上下文中才视为字面量，否则整体按 JSX 文本处理
+func hasStringLiteral(runes []rune) bool {
+	for i, c := range runes {
+		if c == '"' || c == '`' || (c == '\'' && (i == 0 || !isWordRune(runes[i-1]))) {
+			return isQuoteStart(runes, i)
+		}
+	}
+	return false
+}
+
+// isQuoteStart 判断位置 i 是否为字符串字面量的起始引号
+// 引号必须位于原文开头或代码上下文之后（如 = : ( ? 或 return），
+// 因此 JSX 文本中的引号和单词中的撇号（如 subagent's）不视为字面量
+func isQuoteStart(runes []rune, i int) bool {
+	if runes[i] != '"' && runes[i] != '`' && runes[i] != '\'' {
+		return false
+	}
+
+	j := i - 1
+	for j >= 0 && unicode.IsSpace(runes[j]) {
+		j--
+	}
+	if j < 0 {
+		return true
+	}
+	if strings.ContainsRune("=:(,?[{!&|+", runes[j]) {
+		return true
+	}
+	return strings.HasSuffix(string(runes[:j+1]), "return")
+}
+
+// pseudoText 转换一段文本中的字母，并在首尾可见文本处加上标记，标记内按文本长度追加填充
+// jsx 为 true 时跳过 JSX 标签 <...>
+func pseudoText(runes []rune, jsx bool) (string, bool) {
+	out := make([]rune, 0, len(runes)+8)
+	// 记录输出中首个和最后一个文本字符的位置，用于插入标记
+	first, last := -1, -1
+	hasLetter := false
+
+	for i := 0; i < len(runes); i++ {
+		c := runes[i]
+		rawEnd := -1
+		closed := true
+
+		switch {
+		case c == '\\' && i+1 < len(runes):
+			rawEnd = i + 1
+		case c == '$' && i+1 < len(runes) && runes[i+1] == '{':
+			rawEnd, closed = matchExpression(runes, i+1)
+		case c == '{':
+			rawEnd, closed = matchExpression(runes, i)
+		case c == '%':
+			if verb := printfVerbPattern.FindString(string(runes[i:])); verb != "" {
+				rawEnd = i + len([]rune(verb)) - 1
+			}
+		case jsx && c == '<' && (i+1 == len(runes) || runes[i+1] == '/' || unicode.IsLetter(runes[i+1])):
+			rawEnd = matchTag(runes, i)
+		}
+
+		if rawEnd >= i {
+			// 占位符和转义保持原样，但位于文本中间时仍包含在标记内
+			if first >= 0 && closed && !(jsx && c == '<') {
+				last = len(out) + rawEnd - i
+			}
+			out = append(out, runes[i:rawEnd+1]...)
+			i = rawEnd
+			continue
+		}
+
+		if accent, ok := pseudoAccents[c]; ok {
+			c = accent
+			hasLetter = true
+		}
+		if !unicode.IsSpace(c) && !(jsx && c == '>') {
+			if first < 0 {
+				first = len(out)
+			}
+			last = len(out)
+		}
+		out = append(out, c)
+	}
+
+	if !hasLetter {
+		return string(runes), false
+	}
+
+	text := out[first : last+1]
+	var sb strings.Builder
+	sb.WriteString(string(out[:first]))
+	sb.WriteString(pseudoPrefix)
+	sb.WriteString(string(text))
+	sb.WriteRune(' ')
+	sb.WriteString(strings.Repeat(string(pseudoPadding), (len(text)*pseudoExpansion+99)/100))
+	sb.WriteString(pseudoSuffix)
+	sb.WriteString(string(out[last+1:]))
+	return sb.String(), true
+}
+
+// matchTag 从 start 处的 '<' 查找 JSX 标签结束的 '>'，跳过属性中的表达式和字符串
+// 未闭合时返回最后一个位置
+func matchTag(runes []rune, start int) int {
+	for i := start + 1; i < len(runes); i++ {
+		switch runes[i] {
+		case '>':
+			return i
+		case '{':
+			if end := matchBrace(runes, i); end > 0 {
+				i = end
+			}
+		case '"', '\'':
+			if end := skipStringLiteral(runes, i); end > 0 {
+				i = end
+			}
+		}
+	}
+	return len(runes) - 1
+}
+
+// matchExpression 查找表达式结束的 '}'
+// 原文在表达式中间截断时（如 "Edit {normalizePath"），其余部分都视为代码，返回 false
+func matchExpression(runes []rune, start int) (int, bool) {
+	if end := matchBrace(runes, start); end > 0 {
+		return end, true
+	}
+	return len(runes) - 1, false
+}
@@ -0,0 +1,96 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// ========== pseudoLocalize 测试 ==========
+
+func TestPseudoLocalize(t *testing.T) {
+	got := pseudoLocalize("Select model")
+	want := "[!! Ŝéļéçţ ṁöðéļ ~~~~~ !!]"
+	if got != want {
+		t.Errorf("伪本地化结果错误: got %q, want %q", got, want)
+	}
+
+	// 占位符保持不变
+	got = pseudoLocalize("Press ${key} to %s")
+	want = "[!! Ƥŕéŝŝ ${key} ţö %s ~~~~~~~ !!]"
+	if got != want {
+		t.Errorf("占位符应保持不变: got %q, want %q", got, want)
+	}
+}
+
+func TestPseudoLocalize_Expansion(t *testing.T) {
+	// 标记内的文本比原文长 30%~40%，加上标记后更长，使截断问题显现
+	for _, text := range []string{"OK", "Select model", "Press enter to continue with the selected provider"} {
+		got := pseudoLocalize(text)
+		inner := strings.TrimSuffix(strings.TrimPrefix(got, pseudoPrefix), pseudoSuffix)
+		padding := len(inner) - len(strings.TrimRight(inner, string(pseudoPadding)))
+		if min, max := len(text)*30/100, (len(text)*40+99)/100; padding < min || padding > max || padding == 0 {
+			t.Errorf("%q: 填充 %d 个字符, want %d~%d (%q)", text, padding, min, max, got)
+		}
+		if n := len([]rune(got)); n < len(text)*13/10+len(pseudoPrefix)+len(pseudoSuffix) {
+			t.Errorf("%q: 伪本地化结果长度 %d 不足", text, n)
+		}
+	}
+}
+
+// ========== PseudoSource 测试 ==========
+
+func TestPseudoSource(t *testing.T) {
+	tests := []struct {
+		name string
+		from string
+		want string
+	}{
+		{"字符串字面量", `return "Select model"`, `return "[!! Ŝéļéçţ ṁöðéļ ~~~~~ !!]"`},
+		{"多个字面量", `? "Yes" : "No"`, `? "[!! Ýéŝ ~~ !!]" : "[!! Ñö ~ !!]"`},
+		{"模板字符串", "message: `Saved to ${path}`", "message: `[!! Ŝàṽéð ţö ${path} ~~~~~~ !!]`"},
+		{"JSX 文本", `<text fg={theme.text}>ok</text>`, `<text fg={theme.text}>[!! öķ ~ !!]</text>`},
+		{"JSX 撇号", "the subagent's session", "[!! ţĥé ŝûƀàĝéñţ'ŝ ŝéŝŝîöñ ~~~~~~~~ !!]"},
+		{"截断的表达式", "Edit {normalizePath", "[!! Éðîţ ~~ !!] {normalizePath"},
+	}
+
+	for _, tt := range tests {
+		got, ok := PseudoSource(tt.from)
+		if !ok || got != tt.want {
+			t.Errorf("%s: got %q (%v), want %q", tt.name, got, ok, tt.want)
+		}
+	}
+}
+
+func TestPseudoSource_NoText(t *testing.T) {
+	if _, ok := PseudoSource(`{" "}`); ok {
+		t.Error("没有可转换文本时应返回 false")
+	}
+}
+
+// ========== PseudoConfigs 测试 ==========
+
+func TestPseudoConfigs_AppliesThroughApplyConfig(t *testing.T) {
+	tmpDir := t.TempDir()
+	writePackFile(t, tmpDir, "packages/opencode/src/app.tsx", `const title = "Select model"`)
+
+	i18n := &I18n{
+		opencodeDir: tmpDir,
+	}
+
+	configs := PseudoConfigs([]TranslationConfig{{
+		File: "packages/opencode/src/app.tsx",
+		Replacements: map[string]string{
+			`"Select model"`: `"选择模型"`,
+		},
+	}})
+
+	result := i18n.ApplyConfig(configs[0], false)
+	if result.Replacements.Success != 1 {
+		t.Fatalf("伪本地化规则应匹配, got success=%d", result.Replacements.Success)
+	}
+
+	content := readFileString(t, tmpDir, "packages/opencode/src/app.tsx")
+	if content != `const title = "[!! Ŝéļéçţ ṁöðéļ ~~~~~ !!]"` {
+		t.Errorf("文件内容不正确, got %q", content)
+	}
+}
@@ -0,0 +1,178 @@
+package core
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// UpstreamRelease 上游源码仓库的一个发布标签
+type UpstreamRelease struct {
+	Tag    string
+	Commit string
+	// Date 标签（或其提交）的日期，YYYY-MM-DD
+	Date string
+	// Version 该标签下 packages/opencode/package.json 的 version，读取失败时为空
+	Version string
+	// Packs 声明支持该版本的汉化包版本
+	Packs []string
+}
+
+// PackClaim 某个汉化包版本声明支持的上游版本
+type PackClaim struct {
+	Version         string
+	UpstreamVersion string
+	SupportedCommit string
+}
+
+// VersionMatch 汉化规则在某个上游版本上的匹配情况
+type VersionMatch struct {
+	Rules   int
+	Matched int
+	// Files 找不到目标文件或不在目标范围内而跳过的配置文件数
+	Files int
+}
+
+// Percent 规则匹配率（0-100），没有规则时为 0
+func (m VersionMatch) Percent() float64 {
+	if m.Rules == 0 {
+		return 0
+	}
+	return float64(m.Matched) * 100 / float64(m.Rules)
+}
+
+// ListUpstreamReleases 按创建时间从新到旧列出源码仓库的标签，limit <= 0 表示全部
+func ListUpstreamReleases(dir string, limit int) ([]UpstreamRelease, error) {
+	// %(*objectname) 为附注标签指向的提交，轻量标签为空
+	out, err := ExecInDirQuiet(dir, "git", "for-each-ref", "--sort=-creatordate",
+		"--format=%(refname:short)%09%(objectname)%09%(*objectname)%09%(creatordate:short)", "refs/tags")
+	if err != nil {
+		return nil, err
+	}
+
+	var releases []UpstreamRelease
+	for _, line := range strings.Split(out, "\n") {
+		fields := strings.Split(line, "\t")
+		if len(fields) != 4 {
+			continue
+		}
+		release := UpstreamRelease{Tag: fields[0], Commit: fields[1], Date: fields[3]}
+		if fields[2] != "" {
+			release.Commit = fields[2]
+		}
+		release.Version = releasePackageVersion(dir, release.Commit)
+		releases = append(releases, release)
+		if limit > 0 && len(releases) >= limit {
+			break
+		}
+	}
+	return releases, nil
+}
+
+// releasePackageVersion 读取提交中 packages/opencode/package.json 的版本号
+func releasePackageVersion(dir, commit string) string {
+	data, err := ExecInDirQuiet(dir, "git", "show", commit+":packages/opencode/package.json")
+	if err != nil {
+		return ""
+	}
+	var pkg struct {
+		Version string `json:"version"`
+	}
+	if err := json.Unmarshal([]byte(data), &pkg); err != nil {
+		return ""
+	}
+	return pkg.Version
+}
+
+// PackClaims 返回当前及历史汉化包版本声明支持的上游版本
+// 汉化包位于 git 仓库中时读取 config.json 的提交历史，否则只包含当前版本
+func (i *I18n) PackClaims() ([]PackClaim, error) {
+	manifest, err := i.LoadManifest()
+	if err != nil {
+		return nil, err
+	}
+	claims := []PackClaim{manifestClaim(manifest)}
+	seen := map[PackClaim]bool{claims[0]: true}
+
+	dir, ok := i.PackDir()
+	if !ok {
+		return claims, nil
+	}
+	out, err := ExecInDirQuiet(dir, "git", "log", "--format=%H", "--", ManifestFileName)
+	if err != nil {
+		return claims, nil
+	}
+	for _, commit := range strings.Fields(out) {
+		data, err := ExecInDirQuiet(dir, "git", "show", commit+":./"+ManifestFileName)
+		if err != nil {
+			continue
+		}
+		var old PackManifest
+		if err := json.Unmarshal([]byte(data), &old); err != nil {
+			continue
+		}
+		if claim := manifestClaim(&old); !seen[claim] {
+			seen[claim] = true
+			claims = append(claims, claim)
+		}
+	}
+	return claims, nil
+}
+
+// manifestClaim 提取清单中的支持声明
+func manifestClaim(manifest *PackManifest) PackClaim {
+	return PackClaim{
+		Version:         manifest.Version,
+		UpstreamVersion: manifest.Upstream.Version,
+		SupportedCommit: manifest.SupportedCommit,
+	}
+}
+
+// MarkSupportedReleases 根据汉化包声明填充各版本的 Packs
+// supportedCommit 等于标签提交，或 upstream.version 等于标签的 package.json 版本即视为支持
+func MarkSupportedReleases(releases []UpstreamRelease, claims []PackClaim) {
+	for n := range releases {
+		release := &releases[n]
+		release.Packs = nil
+		for _, claim := range claims {
+			if claim.Version == "" {
+				continue
+			}
+			byCommit := claim.SupportedCommit != "" && claim.SupportedCommit == release.Commit
+			byVersion := release.Version != "" && trimVersion(claim.UpstreamVersion) == trimVersion(release.Version)
+			if (byCommit || byVersion) && !containsString(release.Packs, claim.Version) {
+				release.Packs = append(release.Packs, claim.Version)
+			}
+		}
+	}
+}
+
+// trimVersion 去掉版本号的 v 前缀
+func trimVersion(version string) string {
+	return strings.TrimPrefix(strings.TrimSpace(version), "v")
+}
+
+// containsString 判断切片中是否包含 s
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchRate 以模拟应用统计规则在当前源码目录中的匹配率（不修改文件）
+func (i *I18n) MatchRate(ctx context.Context, configs []TranslationConfig) (*VersionMatch, error) {
+	report, err := i.ApplyAll(ctx, configs, ApplyOptions{DryRun: true})
+	if err != nil {
+		return nil, fmt.Errorf("模拟应用失败: %w", err)
+	}
+	match := &VersionMatch{Matched: report.Stats.Replacements.Success, Files: report.Stats.Files.Skipped}
+	// 跳过的文件不计入 Stats.Replacements，按展开后的配置统计规则总数
+	for _, config := range report.Configs {
+		match.Rules += len(config.Replacements)
+	}
+	return match, nil
+}
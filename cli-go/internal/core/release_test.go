@@ -0,0 +1,105 @@
+package core
+
+import (
+	"context"
+	"testing"
+)
+
+// ========== 上游版本测试 ==========
+
+func TestListUpstreamReleases(t *testing.T) {
+	dir, hashes := newGitRepo(t, 1)
+	writePackFile(t, dir, "packages/opencode/package.json", `{"name": "opencode", "version": "1.2.0"}`)
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "release")
+	release := runGit(t, dir, "rev-parse", "HEAD")
+	runGit(t, dir, "tag", "-a", "v1.2.0", "-m", "v1.2.0")
+
+	releases, err := ListUpstreamReleases(dir, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	byTag := make(map[string]UpstreamRelease)
+	for _, r := range releases {
+		byTag[r.Tag] = r
+	}
+	if len(byTag) != 2 {
+		t.Fatalf("应列出 2 个标签: %+v", releases)
+	}
+	// 附注标签应解析到其指向的提交
+	if r := byTag["v1.2.0"]; r.Commit != release || r.Version != "1.2.0" || len(r.Date) != len("2006-01-02") {
+		t.Errorf("v1.2.0 信息不符: %+v", r)
+	}
+	if r := byTag["v1"]; r.Commit != hashes[0] || r.Version != "" {
+		t.Errorf("v1 没有 package.json 时版本应为空: %+v", r)
+	}
+
+	if limited, _ := ListUpstreamReleases(dir, 1); len(limited) != 1 {
+		t.Errorf("limit 应生效: %+v", limited)
+	}
+}
+
+func TestMarkSupportedReleases(t *testing.T) {
+	releases := []UpstreamRelease{
+		{Tag: "v1.1.53", Commit: "aaa", Version: "1.1.53"},
+		{Tag: "v1.1.52", Commit: "bbb", Version: "1.1.52"},
+		{Tag: "v1.1.40", Commit: "ccc", Version: "1.1.40"},
+	}
+	claims := []PackClaim{
+		{Version: "6.2", UpstreamVersion: "1.1.53", SupportedCommit: "aaa"},
+		{Version: "6.1", UpstreamVersion: "v1.1.52"},
+		{Version: "6.0", UpstreamVersion: "1.1.51", SupportedCommit: "bbb"},
+	}
+	MarkSupportedReleases(releases, claims)
+
+	if got := releases[0].Packs; len(got) != 1 || got[0] != "6.2" {
+		t.Errorf("v1.1.53 应由 6.2 支持: %v", got)
+	}
+	if got := releases[1].Packs; len(got) != 2 || got[0] != "6.1" || got[1] != "6.0" {
+		t.Errorf("v1.1.52 应按版本号和提交分别匹配 6.1 / 6.0: %v", got)
+	}
+	if len(releases[2].Packs) != 0 {
+		t.Errorf("v1.1.40 不应被支持: %v", releases[2].Packs)
+	}
+}
+
+func TestPackClaims_History(t *testing.T) {
+	dir, _ := newGitRepo(t, 1)
+	writePackFile(t, dir, "pack/config.json", `{"version": "1.0", "upstream": {"version": "1.1.40"}}`)
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "pack 1.0")
+	writePackFile(t, dir, "pack/config.json", `{"version": "1.1", "upstream": {"version": "1.1.41"}, "supportedCommit": "abc"}`)
+	runGit(t, dir, "commit", "-q", "-am", "pack 1.1")
+
+	i18n := &I18n{i18nDir: dir + "/pack"}
+	claims, err := i18n.PackClaims()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(claims) != 2 || claims[0].Version != "1.1" || claims[0].SupportedCommit != "abc" || claims[1].UpstreamVersion != "1.1.40" {
+		t.Errorf("应包含当前和历史版本的声明: %+v", claims)
+	}
+}
+
+func TestMatchRate(t *testing.T) {
+	dir := t.TempDir()
+	target := "packages/opencode/src/app.tsx"
+	writePackFile(t, dir, target, `<text>Hello</text>`)
+
+	i18n := &I18n{opencodeDir: dir}
+	configs := []TranslationConfig{
+		{FileName: "app.json", File: target, Replacements: map[string]string{"Hello": "你好", "Bye": "再见"}},
+		{FileName: "gone.json", File: "packages/opencode/src/gone.tsx", Replacements: map[string]string{"Gone": "消失"}},
+	}
+
+	match, err := i18n.MatchRate(context.Background(), configs)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if match.Rules != 3 || match.Matched != 1 || match.Files != 1 {
+		t.Errorf("匹配统计不符: %+v", match)
+	}
+	if readFileString(t, dir, target) != `<text>Hello</text>` {
+		t.Error("统计匹配率不应修改文件")
+	}
+}
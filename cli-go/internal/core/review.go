@@ -0,0 +1,126 @@
+package core
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"sort"
+)
+
+// ReviewDecision 交互审查中对一处匹配的决定
+type ReviewDecision int
+
+const (
+	// ReviewAccept 替换这一处
+	ReviewAccept ReviewDecision = iota
+	// ReviewSkip 跳过这一处
+	ReviewSkip
+	// ReviewAcceptRule 替换这一处以及该规则在本文件中剩余的所有匹配
+	ReviewAcceptRule
+)
+
+// OccurrenceReviewer 审查一处匹配，total 为该规则在文件中的匹配总数
+type OccurrenceReviewer func(occ Occurrence, total int) ReviewDecision
+
+// NeedsReview 判断规则的匹配是否需要人工确认：匹配多于一处，或任一处位于可疑位置
+func NeedsReview(occurrences []Occurrence) bool {
+	if len(occurrences) > 1 {
+		return true
+	}
+	for _, occ := range occurrences {
+		if occ.Kind.Suspicious() {
+			return true
+		}
+	}
+	return false
+}
+
+// reviewRule 审查一条规则在文件中的全部匹配
+// 返回接受的位置以及对应的序号（从 1 开始）；无需审查时全部接受
+func reviewRule(sc *occurrenceScanner, config *TranslationConfig, find string, positions []int, length int, review OccurrenceReviewer) ([]int, []int) {
+	occurrences := make([]Occurrence, len(positions))
+	for n, pos := range positions {
+		occ := sc.locate(pos, length, 2)
+		occ.Config = config
+		occ.From = find
+		occ.To = config.Replacements[find]
+		occ.Index = n + 1
+		occurrences[n] = occ
+	}
+
+	indexes := make([]int, 0, len(positions))
+	if !NeedsReview(occurrences) {
+		for n := range positions {
+			indexes = append(indexes, n+1)
+		}
+		return positions, indexes
+	}
+
+	var selected []int
+	acceptRest := false
+	for n, occ := range occurrences {
+		decision := ReviewAccept
+		if !acceptRest {
+			decision = review(occ, len(occurrences))
+		}
+		if decision == ReviewAcceptRule {
+			acceptRest = true
+		}
+		if decision != ReviewSkip {
+			selected = append(selected, positions[n])
+			indexes = append(indexes, n+1)
+		}
+	}
+	return selected, indexes
+}
+
+// SaveOccurrenceFilters 将匹配过滤器写回配置文件的 occurrences 字段，并按 pack fmt 的规则格式化
+// file 非空时（glob 配置展开出的文件）写入 fileOccurrences 中该文件的条目
+func SaveOccurrenceFilters(configPath, file string, filters map[string][]int) error {
+	data, err := os.ReadFile(configPath)
+	if err != nil {
+		return err
+	}
+
+	var raw map[string]interface{}
+	if err := DecodeJSON(data, &raw); err != nil {
+		return fmt.Errorf("%s: %w", configPath, err)
+	}
+
+	occurrences, _ := raw["occurrences"].(map[string]interface{})
+	if occurrences == nil {
+		occurrences = make(map[string]interface{})
+	}
+	var perFile map[string]interface{}
+	if file != "" {
+		perFile, _ = raw["fileOccurrences"].(map[string]interface{})
+		if perFile == nil {
+			perFile = make(map[string]interface{})
+		}
+		occurrences, _ = perFile[file].(map[string]interface{})
+		if occurrences == nil {
+			occurrences = make(map[string]interface{})
+		}
+	}
+	for from, indexes := range filters {
+		sorted := append([]int{}, indexes...)
+		sort.Ints(sorted)
+		occurrences[from] = sorted
+	}
+	if file != "" {
+		perFile[file] = occurrences
+		raw["fileOccurrences"] = perFile
+	} else {
+		raw["occurrences"] = occurrences
+	}
+
+	encoded, err := json.Marshal(raw)
+	if err != nil {
+		return err
+	}
+	formatted, err := FormatJSON(encoded)
+	if err != nil {
+		return err
+	}
+	return os.WriteFile(configPath, formatted, 0644)
+}
@@ -0,0 +1,121 @@
+package core
+
+import (
+	"context"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ========== 交互审查测试 ==========
+
+func TestApplyAll_ReviewSelections(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := "packages/opencode/src/plan.tsx"
+	writePackFile(t, tmpDir, target, "const a = \"Free\";\nconst b = \"Free\";\nconst c = \"Free\";\nconst d = \"Once\";\n")
+
+	i18n := &I18n{opencodeDir: tmpDir}
+	configs := []TranslationConfig{{
+		File:         target,
+		Replacements: map[string]string{`"Free"`: `"免费"`, `"Once"`: `"一次"`},
+	}}
+
+	var asked []int
+	review := func(occ Occurrence, total int) ReviewDecision {
+		asked = append(asked, occ.Index)
+		if occ.Index == 1 {
+			return ReviewSkip
+		}
+		return ReviewAcceptRule
+	}
+
+	report, err := i18n.ApplyAll(context.Background(), configs, ApplyOptions{Review: review})
+	if err != nil {
+		t.Fatalf("ApplyAll 失败: %v", err)
+	}
+
+	// "Once" 只有一处且位于字符串中，不需要确认；选择 a 后不再询问剩余匹配
+	if len(asked) != 2 {
+		t.Errorf("应询问 2 次, got %v", asked)
+	}
+	want := "const a = \"Free\";\nconst b = \"免费\";\nconst c = \"免费\";\nconst d = \"一次\";\n"
+	if got := readFileString(t, tmpDir, target); got != want {
+		t.Errorf("文件内容错误:\ngot  %q\nwant %q", got, want)
+	}
+
+	selections := report.Files[0].Selections
+	if len(selections) != 1 || len(selections[`"Free"`]) != 2 || selections[`"Free"`][0] != 2 {
+		t.Errorf("选择记录错误: %v", selections)
+	}
+}
+
+func TestApplyConfig_OccurrenceFilter(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := "packages/opencode/src/plan.tsx"
+	writePackFile(t, tmpDir, target, `"Free" "Free" "Free"`)
+
+	i18n := &I18n{opencodeDir: tmpDir}
+	result := i18n.ApplyConfig(TranslationConfig{
+		File:         target,
+		Replacements: map[string]string{`"Free"`: `"免费"`},
+		Occurrences:  map[string][]int{`"Free"`: {3, 1}},
+	}, false)
+
+	if result.Replacements.Success != 1 {
+		t.Errorf("规则应计为匹配成功, got %+v", result.Replacements)
+	}
+	if got := readFileString(t, tmpDir, target); got != `"免费" "Free" "免费"` {
+		t.Errorf("应只替换过滤器中的匹配, got %q", got)
+	}
+}
+
+// ========== SaveOccurrenceFilters 测试 ==========
+
+func TestSaveOccurrenceFilters(t *testing.T) {
+	packDir := t.TempDir()
+	writePackFile(t, packDir, "dialogs/plan.json", `{"replacements": {"\"Free\"": "\"免费\""}, "file": "src/plan.tsx"}`)
+
+	path := filepath.Join(packDir, "dialogs", "plan.json")
+	if err := SaveOccurrenceFilters(path, "", map[string][]int{`"Free"`: {3, 2}}); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+
+	config, err := LoadI18nConfig(path)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if got := config.Occurrences[`"Free"`]; len(got) != 2 || got[0] != 2 || got[1] != 3 {
+		t.Errorf("过滤器错误: %v", got)
+	}
+
+	// 写回的文件应符合 pack fmt 格式
+	content := readFileString(t, packDir, "dialogs/plan.json")
+	formatted, err := FormatJSON([]byte(content))
+	if err != nil || string(formatted) != content {
+		t.Errorf("保存的文件不是规范格式:\n%s", content)
+	}
+	if strings.Index(content, `"occurrences"`) > strings.Index(content, `"replacements"`) {
+		t.Error("occurrences 应位于 replacements 之前")
+	}
+}
+
+func TestSaveOccurrenceFilters_GlobFile(t *testing.T) {
+	packDir := t.TempDir()
+	writePackFile(t, packDir, "shared.json", `{"file": "src/ui/dialog-*.tsx", "occurrences": {"\"Ok\"": [1]}, "replacements": {"\"Cancel\"": "\"取消\""}}`)
+
+	path := filepath.Join(packDir, "shared.json")
+	if err := SaveOccurrenceFilters(path, "src/ui/dialog-b.tsx", map[string][]int{`"Cancel"`: {2}}); err != nil {
+		t.Fatalf("保存失败: %v", err)
+	}
+
+	config, err := LoadI18nConfig(path)
+	if err != nil {
+		t.Fatalf("重新加载失败: %v", err)
+	}
+	if got := config.FileOccurrences["src/ui/dialog-b.tsx"][`"Cancel"`]; len(got) != 1 || got[0] != 2 {
+		t.Errorf("应按文件保存过滤器: %v", config.FileOccurrences)
+	}
+	if _, ok := config.Occurrences[`"Cancel"`]; ok || len(config.Occurrences) != 1 {
+		t.Errorf("不应修改整个配置的过滤器: %v", config.Occurrences)
+	}
+}
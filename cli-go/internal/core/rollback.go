@@ -0,0 +1,190 @@
+package core
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+)
+
+// Rollback 命令被中断时需要撤销的操作，按注册的逆序执行
+type Rollback struct {
+	steps []rollbackStep
+}
+
+type rollbackStep struct {
+	desc string
+	undo func() error
+}
+
+// Add 注册恢复步骤，desc 描述恢复的内容（如 "恢复暂存的更改"）
+func (r *Rollback) Add(desc string, undo func() error) {
+	r.steps = append(r.steps, rollbackStep{desc: desc, undo: undo})
+}
+
+// Len 返回已注册的步骤数
+func (r *Rollback) Len() int {
+	return len(r.steps)
+}
+
+// Run 逆序执行全部恢复步骤并输出结果，返回是否全部成功；执行后清空
+func (r *Rollback) Run(w io.Writer) bool {
+	success := true
+	for n := len(r.steps) - 1; n >= 0; n-- {
+		step := r.steps[n]
+		if err := step.undo(); err != nil {
+			fmt.Fprintf(w, "  ✗ %s: %v\n", step.desc, err)
+			success = false
+			continue
+		}
+		fmt.Fprintf(w, "  ↩ %s\n", step.desc)
+	}
+	r.steps = nil
+	return success
+}
+
+// FileJournal 记录本次修改前的文件内容，用于中断后还原（并发安全）
+type FileJournal struct {
+	mu        sync.Mutex
+	originals map[string]journalEntry
+}
+
+type journalEntry struct {
+	data   []byte
+	mode   os.FileMode
+	exists bool
+}
+
+// Record 在第一次修改 path 之前记录其内容，之后的调用不覆盖
+func (j *FileJournal) Record(path string) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.originals == nil {
+		j.originals = make(map[string]journalEntry)
+	}
+	if _, ok := j.originals[path]; ok {
+		return
+	}
+	entry := journalEntry{}
+	if info, err := os.Stat(path); err == nil {
+		if data, err := os.ReadFile(path); err == nil {
+			entry = journalEntry{data: data, mode: info.Mode().Perm(), exists: true}
+		}
+	}
+	j.originals[path] = entry
+}
+
+// Len 返回已记录的文件数
+func (j *FileJournal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.originals)
+}
+
+// Restore 还原全部已记录的文件（原本不存在的文件被删除），返回还原的路径
+func (j *FileJournal) Restore() ([]string, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	var restored []string
+	for _, path := range sortedKeys(j.originals) {
+		entry := j.originals[path]
+		var err error
+		if entry.exists {
+			err = os.WriteFile(path, entry.data, entry.mode)
+			if err == nil {
+				err = os.Chmod(path, entry.mode)
+			}
+		} else {
+			err = os.Remove(path)
+			if os.IsNotExist(err) {
+				err = nil
+			}
+		}
+		if err != nil {
+			return restored, fmt.Errorf("还原 %s 失败: %w", filepath.Base(path), err)
+		}
+		restored = append(restored, path)
+	}
+	j.originals = nil
+	return restored, nil
+}
+
+// RepoSnapshot Git 仓库的检出位置与汉化分支位置，用于中断后恢复
+type RepoSnapshot struct {
+	dir string
+	// branch 当前分支，分离 HEAD 时为空
+	branch string
+	head   string
+	// translation 汉化分支指向的提交，分支不存在时为空
+	translation string
+	// clean 快照时工作区没有任何修改（包括未跟踪文件），恢复时可以安全地 git clean
+	clean bool
+}
+
+// SnapshotRepo 记录仓库当前的检出位置
+func SnapshotRepo(dir string) (*RepoSnapshot, error) {
+	head, err := GitResolveCommit(dir, "HEAD")
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 HEAD: %w", err)
+	}
+	snapshot := &RepoSnapshot{dir: dir, branch: GitCurrentBranch(dir), head: head}
+	if GitBranchExists(dir, TranslationBranch) {
+		snapshot.translation, _ = GitResolveCommit(dir, "refs/heads/"+TranslationBranch)
+	}
+	if out, err := ExecInDirQuiet(dir, "git", "status", "--porcelain"); err == nil && out == "" {
+		snapshot.clean = true
+	}
+	return snapshot, nil
+}
+
+// String 返回快照的检出位置（分支名或短哈希）
+func (s *RepoSnapshot) String() string {
+	if s.branch != "" {
+		return s.branch
+	}
+	return ShortHash(s.head)
+}
+
+// Clean 返回快照时工作区是否没有任何修改
+func (s *RepoSnapshot) Clean() bool {
+	return s.clean
+}
+
+// Restore 丢弃已跟踪文件的修改，把分支和 HEAD 恢复到快照时的位置
+// 快照时工作区干净则同时删除新增的未跟踪文件，否则保留，以免删掉用户的文件
+func (s *RepoSnapshot) Restore() error {
+	// 可能停在迁移手工提交的 cherry-pick 中
+	ExecInDirQuiet(s.dir, "git", "cherry-pick", "--abort")
+
+	if _, err := ExecInDirQuiet(s.dir, "git", "reset", "-q", "--hard"); err != nil {
+		return err
+	}
+	if s.clean {
+		if _, err := ExecInDirQuiet(s.dir, "git", "clean", "-fdq"); err != nil {
+			return err
+		}
+	}
+
+	args := []string{"checkout", "-q", "--force", "--detach", s.head}
+	if s.branch != "" {
+		args = []string{"checkout", "-q", "--force", "-B", s.branch, s.head}
+	}
+	if _, err := ExecInDirQuiet(s.dir, "git", args...); err != nil {
+		return err
+	}
+
+	if s.branch == TranslationBranch {
+		return nil
+	}
+	if s.translation != "" {
+		_, err := ExecInDirQuiet(s.dir, "git", "update-ref", "refs/heads/"+TranslationBranch, s.translation)
+		return err
+	}
+	if GitBranchExists(s.dir, TranslationBranch) {
+		_, err := ExecInDirQuiet(s.dir, "git", "branch", "-q", "-D", TranslationBranch)
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,123 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+// ========== 中断回滚测试 ==========
+
+func TestRollback_ReverseOrder(t *testing.T) {
+	var order []string
+	rollback := &Rollback{}
+	rollback.Add("first", func() error { order = append(order, "first"); return nil })
+	rollback.Add("second", func() error { return errors.New("boom") })
+	rollback.Add("third", func() error { order = append(order, "third"); return nil })
+
+	var out strings.Builder
+	if rollback.Run(&out) {
+		t.Error("有步骤失败时应返回 false")
+	}
+	if strings.Join(order, ",") != "third,first" {
+		t.Errorf("应逆序执行且失败后继续: %v", order)
+	}
+	if !strings.Contains(out.String(), "↩ third") || !strings.Contains(out.String(), "✗ second: boom") {
+		t.Errorf("输出错误: %q", out.String())
+	}
+	if rollback.Len() != 0 {
+		t.Error("执行后应清空")
+	}
+}
+
+func TestFileJournal_Restore(t *testing.T) {
+	dir := t.TempDir()
+	writePackFile(t, dir, "a.ts", "original")
+	existing := filepath.Join(dir, "a.ts")
+	created := filepath.Join(dir, "i18n.ts")
+
+	journal := &FileJournal{}
+	journal.Record(existing)
+	journal.Record(created)
+	writePackFile(t, dir, "a.ts", "changed")
+	// 之后的记录不覆盖第一次的内容
+	journal.Record(existing)
+	writePackFile(t, dir, "i18n.ts", "new")
+
+	restored, err := journal.Restore()
+	if err != nil {
+		t.Fatalf("还原失败: %v", err)
+	}
+	if len(restored) != 2 {
+		t.Errorf("应还原 2 个文件: %v", restored)
+	}
+	if got := readFileString(t, dir, "a.ts"); got != "original" {
+		t.Errorf("内容应还原: %q", got)
+	}
+	if _, err := os.Stat(created); !os.IsNotExist(err) {
+		t.Error("原本不存在的文件应被删除")
+	}
+	if journal.Len() != 0 {
+		t.Error("还原后应清空记录")
+	}
+}
+
+func TestRepoSnapshot_Restore(t *testing.T) {
+	dir, hashes := newGitRepo(t, 2)
+	runGit(t, dir, "branch", TranslationBranch, hashes[0])
+
+	snapshot, err := SnapshotRepo(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !snapshot.Clean() || snapshot.String() != "dev" {
+		t.Fatalf("快照错误: %+v", snapshot)
+	}
+
+	// 模拟中断前的操作：重置汉化分支并留下未提交的修改
+	runGit(t, dir, "checkout", "-q", "-B", TranslationBranch, hashes[1])
+	writePackFile(t, dir, "file.txt", "half applied")
+	writePackFile(t, dir, "i18n.ts", "new")
+
+	if err := snapshot.Restore(); err != nil {
+		t.Fatalf("恢复失败: %v", err)
+	}
+	if branch := GitCurrentBranch(dir); branch != "dev" {
+		t.Errorf("应回到 dev: %q", branch)
+	}
+	if tip := runGit(t, dir, "rev-parse", TranslationBranch); tip != hashes[0] {
+		t.Errorf("汉化分支应恢复到 %s: %s", hashes[0], tip)
+	}
+	if out := runGit(t, dir, "status", "--porcelain"); out != "" {
+		t.Errorf("工作区应恢复干净: %q", out)
+	}
+}
+
+func TestGitStashChanges_UntrackedOnly(t *testing.T) {
+	t.Setenv("GIT_COMMITTER_NAME", "test")
+	t.Setenv("GIT_COMMITTER_EMAIL", "test@example.com")
+	dir, _ := newGitRepo(t, 1)
+
+	// 只有未跟踪文件时不会创建暂存，不能误报（否则回滚会弹出无关的暂存）
+	writePackFile(t, dir, "untracked.txt", "x")
+	if stashed, err := GitStashChanges(dir); err != nil || stashed {
+		t.Errorf("不应创建暂存: %v, %v", stashed, err)
+	}
+
+	writePackFile(t, dir, "file.txt", "dirty")
+	if stashed, err := GitStashChanges(dir); err != nil || !stashed {
+		t.Errorf("应创建暂存: %v, %v", stashed, err)
+	}
+}
+
+func TestExecLiveContext_Cancelled(t *testing.T) {
+	dir, _ := newGitRepo(t, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := ExecLiveContext(ctx, dir, nil, "git", "status"); !errors.Is(err, context.Canceled) {
+		t.Errorf("应返回 ctx 的错误: %v", err)
+	}
+}
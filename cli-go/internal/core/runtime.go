@@ -0,0 +1,462 @@
+package core
+
+import (
+	"bytes"
+	"crypto/sha1"
+	"encoding/hex"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+const (
+	// RuntimeLocale 运行时字典中汉化包对应的语言
+	RuntimeLocale = "zh-CN"
+	// RuntimeLangEnv 运行时选择语言的环境变量
+	RuntimeLangEnv = "OPENCODE_LANG"
+	// RuntimeFunc 生成的字典查找函数名
+	RuntimeFunc = "__i18n"
+	// RuntimeModuleRelPath 生成的字典模块位置（相对 OpenCode 源码根目录）
+	RuntimeModuleRelPath = "packages/opencode/src/i18n/opencode-i18n.ts"
+)
+
+// RuleID 返回规则的稳定 ID，格式为 "<分类>/<配置名>#<原文哈希>"
+func RuleID(config TranslationConfig, from string) string {
+	sum := sha1.Sum([]byte(from))
+	return ruleConfigName(config) + "#" + hex.EncodeToString(sum[:])[:8]
+}
+
+// ruleConfigName 返回规则 ID 中的配置部分，格式为 "<分类>/<配置名>"（根目录配置没有分类）
+func ruleConfigName(config TranslationConfig) string {
+	name := strings.TrimSuffix(config.FileName, ".json")
+	if config.Category != "" && config.Category != "root" {
+		name = config.Category + "/" + name
+	}
+	return name
+}
+
+// RuntimeSkip 无法改写为字典查找的规则
+type RuntimeSkip struct {
+	Config *TranslationConfig
+	From   string
+	Reason string
+}
+
+// RuntimePlan 运行时字典策略的改写计划
+type RuntimePlan struct {
+	// Configs 替换规则的译文被改写为字典查找调用，仍通过 ApplyConfig 应用
+	Configs []TranslationConfig
+	// Entries 规则 ID -> 译文（JS 字符串字面量形式）
+	Entries map[string]string
+	Skipped []RuntimeSkip
+}
+
+// segment 原文中的一段：代码或可翻译文本
+type segment struct {
+	text  string
+	quote rune // 字符串字面量的引号，JSX 文本为 0
+	// translatable 为 true 时 text 是字面量内容或 JSX 文本
+	translatable bool
+	closed       bool
+}
+
+// PlanRuntimeDictionary 为汉化包生成运行时字典改写计划
+// 原文中的每个字符串字面量或 JSX 文本被改写为 __i18n("<规则 ID>", <英文原文>)，
+// 译文写入字典；无法安全改写的规则记录在 Skipped 中
+func PlanRuntimeDictionary(configs []TranslationConfig) *RuntimePlan {
+	plan := &RuntimePlan{Entries: make(map[string]string)}
+
+	for idx := range configs {
+		config := &configs[idx]
+		rewritten := *config
+		rewritten.Replacements = make(map[string]string, len(config.Replacements))
+
+		for _, r := range sortedReplacements(config.Replacements) {
+			id := RuleID(*config, r.From)
+			code, entries, reason := rewriteForRuntime(id, r.From, r.To)
+			if reason != "" {
+				plan.Skipped = append(plan.Skipped, RuntimeSkip{Config: config, From: r.From, Reason: reason})
+				continue
+			}
+			rewritten.Replacements[r.From] = code
+			for key, value := range entries {
+				plan.Entries[key] = value
+			}
+		}
+
+		plan.Configs = append(plan.Configs, rewritten)
+	}
+
+	return plan
+}
+
+// reasonNoContext 原文是没有引号或标签的纯文本，无法确定其在源码中的语法位置
+const reasonNoContext = "原文缺少引号或标签上下文"
+
+// pairSegments 拆分规则原文与译文，检查两者只有可翻译文本不同
+// 返回两组片段及发生变化的片段下标，结构不一致时返回原因
+func pairSegments(from, to string) ([]segment, []segment, []int, string) {
+	fromSegs := splitSegments(from)
+	toSegs := splitSegments(to)
+	if len(fromSegs) == 1 && !fromSegs[0].translatable {
+		return nil, nil, nil, reasonNoContext
+	}
+	if len(fromSegs) != len(toSegs) {
+		return nil, nil, nil, "原文与译文结构不一致"
+	}
+
+	var changed []int
+	for n := range fromSegs {
+		f, t := fromSegs[n], toSegs[n]
+		if f.translatable != t.translatable || f.quote != t.quote {
+			return nil, nil, nil, "原文与译文结构不一致"
+		}
+		if !f.translatable {
+			if f.text != t.text {
+				return nil, nil, nil, "译文修改了代码部分"
+			}
+			continue
+		}
+		if f.text != t.text {
+			changed = append(changed, n)
+		}
+	}
+	if len(changed) == 0 {
+		return nil, nil, nil, "未找到可改写的字符串字面量或 JSX 文本"
+	}
+	return fromSegs, toSegs, changed, ""
+}
+
+// rewriteForRuntime 将单条规则改写为字典查找调用
+// 返回改写后的代码、字典条目，失败时返回原因
+func rewriteForRuntime(id, from, to string) (string, map[string]string, string) {
+	fromSegs, toSegs, changed, reason := pairSegments(from, to)
+	if reason != "" {
+		return "", nil, reason
+	}
+
+	entries := make(map[string]string)
+	var sb strings.Builder
+	for n, f := range fromSegs {
+		key := id
+		if len(changed) > 1 {
+			key = fmt.Sprintf("%s.%d", id, n)
+		}
+
+		isChanged := false
+		for _, c := range changed {
+			isChanged = isChanged || c == n
+		}
+		if !isChanged {
+			sb.WriteString(segmentSource(f))
+			continue
+		}
+
+		t := toSegs[n]
+		switch {
+		case f.quote != 0 && !f.closed:
+			return "", nil, "字符串字面量被截断"
+		case f.quote == '`' && strings.Contains(f.text, "${"):
+			return "", nil, "模板字符串包含插值"
+		case f.quote != 0:
+			entries[key] = string(t.quote) + t.text + string(t.quote)
+			call := fmt.Sprintf(`%s(%q, %s)`, RuntimeFunc, key, segmentSource(f))
+			// JSX 属性 title="..." 需要包裹为表达式
+			if isJSXAttribute(fromSegs, n) {
+				call = "{" + call + "}"
+			}
+			sb.WriteString(call)
+		default:
+			entries[key] = jsString(t.text)
+			sb.WriteString(fmt.Sprintf(`{%s(%q, %s)}`, RuntimeFunc, key, jsString(f.text)))
+		}
+	}
+
+	return sb.String(), entries, ""
+}
+
+var (
+	// jsxAttrValuePattern 以 JSX 属性名加 = 结尾的代码（属性名紧邻 =，前面是空白或开头）
+	jsxAttrValuePattern = regexp.MustCompile(`(^|\s)[A-Za-z_$][\w$:-]*=$`)
+	// jsxAttrListPattern 只由带值的 JSX 属性组成的代码（字面量内容已去掉；不接受无值属性，以免把 const x= 当作属性）
+	jsxAttrListPattern = regexp.MustCompile(`^(\s*[A-Za-z_$][\w$:-]*=(""|\{[^{}]*\}))*\s*$`)
+	// jsxAttrTailPattern 属性值之后的代码：下一个属性或标签结束
+	jsxAttrTailPattern = regexp.MustCompile(`^(/?>|[A-Za-z_$][\w$:-]*(=|\s|/?>|$))`)
+)
+
+// isJSXAttribute 判断 segs[n] 处的字符串字面量是否为 JSX 属性值
+// 属性名须紧邻 =（排除 ==、!=、<=、a = b 等），且位于未闭合的 <tag ... 中；
+// 规则本身只是属性片段（如 pending="..."）时，前后的代码也须都是属性或标签结束
+func isJSXAttribute(segs []segment, n int) bool {
+	code := func(list []segment) string {
+		var sb strings.Builder
+		for _, s := range list {
+			if s.translatable {
+				sb.WriteString(`""`)
+			} else {
+				sb.WriteString(s.text)
+			}
+		}
+		return sb.String()
+	}
+	before := code(segs[:n])
+	loc := jsxAttrValuePattern.FindStringIndex(before)
+	if loc == nil {
+		return false
+	}
+	before = before[:loc[0]]
+
+	// 向前查找所在的标签，属性值表达式 {...} 中的 < 和 > 不计
+	depth := 0
+	for i := len(before) - 1; i >= 0; i-- {
+		switch before[i] {
+		case '}':
+			depth++
+		case '{':
+			if depth == 0 {
+				return false
+			}
+			depth--
+		case '>':
+			if depth == 0 {
+				return false
+			}
+		case '<':
+			if depth == 0 {
+				next := byte(0)
+				if i+1 < len(before) {
+					next = before[i+1]
+				}
+				return next >= 'a' && next <= 'z' || next >= 'A' && next <= 'Z'
+			}
+		}
+	}
+
+	after := strings.TrimSpace(code(segs[n+1:]))
+	return jsxAttrListPattern.MatchString(before) && (after == "" || jsxAttrTailPattern.MatchString(after))
+}
+
+// jsString 将文本编码为 JS 字符串字面量
+func jsString(s string) string {
+	var buf bytes.Buffer
+	writeJSONString(&buf, s)
+	return buf.String()
+}
+
+// segmentSource 返回片段在源码中的原始形式
+func segmentSource(s segment) string {
+	if s.quote == 0 {
+		return s.text
+	}
+	if s.closed {
+		return string(s.quote) + s.text + string(s.quote)
+	}
+	return string(s.quote) + s.text
+}
+
+// splitSegments 将原文拆分为代码与可翻译文本
+// 包含字符串字面量时拆出字面量内容；否则拆出被 > 和 < 包围的 JSX 文本
+func splitSegments(s string) []segment {
+	runes := []rune(s)
+	var segs []segment
+	var code strings.Builder
+
+	flushCode := func() {
+		if code.Len() > 0 {
+			segs = append(segs, segment{text: code.String()})
+			code.Reset()
+		}
+	}
+
+	if hasStringLiteral(runes) {
+		for i := 0; i < len(runes); i++ {
+			if !isQuoteStart(runes, i) {
+				code.WriteRune(runes[i])
+				continue
+			}
+			end := skipStringLiteral(runes, i)
+			closed := end >= 0
+			if !closed {
+				end = len(runes)
+			}
+			flushCode()
+			segs = append(segs, segment{
+				text:         string(runes[i+1 : end]),
+				quote:        runes[i],
+				translatable: true,
+				closed:       closed,
+			})
+			i = end
+		}
+		flushCode()
+		return segs
+	}
+
+	for i := 0; i < len(runes); i++ {
+		code.WriteRune(runes[i])
+		if runes[i] != '>' {
+			continue
+		}
+		end := i + 1
+		for end < len(runes) && runes[end] != '<' && runes[end] != '{' {
+			end++
+		}
+		text := string(runes[i+1 : end])
+		if end < len(runes) && runes[end] == '<' && strings.TrimSpace(text) != "" {
+			flushCode()
+			segs = append(segs, segment{text: text, translatable: true, closed: true})
+			i = end - 1
+		}
+	}
+	flushCode()
+	return segs
+}
+
+// sortedReplacements 返回按原文排序的替换规则列表
+func sortedReplacements(m map[string]string) []Replacement {
+	list := make([]Replacement, 0, len(m))
+	for _, from := range sortedKeys(m) {
+		list = append(list, Replacement{From: from, To: m[from]})
+	}
+	return list
+}
+
+// RuntimeModulePath 返回字典模块的完整路径
+func (i *I18n) RuntimeModulePath() string {
+	return filepath.Join(i.opencodeDir, filepath.FromSlash(RuntimeModuleRelPath))
+}
+
+// GenerateRuntimeModule 生成 TypeScript 字典模块内容
+func GenerateRuntimeModule(entries map[string]string) string {
+	var sb strings.Builder
+	sb.WriteString("// 由 opencode-cli 根据汉化包生成，请勿手动修改\n")
+	sb.WriteString(fmt.Sprintf("// 通过环境变量 %s 选择语言 (%s / en)，缺失的条目回退为英文原文\n\n", RuntimeLangEnv, RuntimeLocale))
+	sb.WriteString("const dictionaries: Record<string, Record<string, string>> = {\n")
+	sb.WriteString(fmt.Sprintf("  %q: {\n", RuntimeLocale))
+	for _, key := range sortedKeys(entries) {
+		sb.WriteString(fmt.Sprintf("    %q: %s,\n", key, entries[key]))
+	}
+	sb.WriteString("  },\n}\n\n")
+	sb.WriteString(fmt.Sprintf("const lang = process.env.%s ?? %q\n\n", RuntimeLangEnv, RuntimeLocale))
+	sb.WriteString(fmt.Sprintf("export function %s(id: string, fallback: string): string {\n", RuntimeFunc))
+	sb.WriteString("  return dictionaries[lang]?.[id] ?? fallback\n")
+	sb.WriteString("}\n")
+	return sb.String()
+}
+
+// WriteRuntimeModule 写入字典模块
+func (i *I18n) WriteRuntimeModule(entries map[string]string) error {
+	path := i.RuntimeModulePath()
+	if err := EnsureDir(filepath.Dir(path)); err != nil {
+		return err
+	}
+	return os.WriteFile(path, []byte(GenerateRuntimeModule(entries)), 0644)
+}
+
+// runtimeCallPrefix 字典调用的开头 __i18n("<ID>", ，紧跟其后的字面量是已改写过的英文原文
+var runtimeCallPrefix = regexp.MustCompile(regexp.QuoteMeta(RuntimeFunc) + `\("[^"\n]*",\s*$`)
+
+// skipRuntimeCalls 去掉已位于字典调用参数中的匹配位置，使运行时改写可以重复应用
+func skipRuntimeCalls(content string, positions []int) []int {
+	const lookBehind = 256
+	var kept []int
+	for _, pos := range positions {
+		start := pos - lookBehind
+		if start < 0 {
+			start = 0
+		}
+		if !runtimeCallPrefix.MatchString(content[start:pos]) {
+			kept = append(kept, pos)
+		}
+	}
+	return kept
+}
+
+// directivePattern 文件开头的指令（"use client"、'use strict' 等）
+var directivePattern = regexp.MustCompile(`^(["'])use [A-Za-z ]+["'];?$`)
+
+// headerEnd 返回文件开头 shebang、指令和注释块结束处的字节偏移（不含其后的空行），导入插入在此处
+func headerEnd(content string) int {
+	end, offset := 0, 0
+	inBlock := false
+	for n, line := range strings.SplitAfter(content, "\n") {
+		trimmed := strings.TrimSpace(line)
+		next := offset + len(line)
+		switch {
+		case inBlock:
+			inBlock = !strings.Contains(trimmed, "*/")
+			end = next
+		case n == 0 && strings.HasPrefix(trimmed, "#!"),
+			strings.HasPrefix(trimmed, "//"),
+			directivePattern.MatchString(trimmed):
+			end = next
+		case strings.HasPrefix(trimmed, "/*"):
+			inBlock = !strings.Contains(trimmed[2:], "*/")
+			end = next
+		case trimmed == "":
+			// 空行可能位于注释块之间，继续查找
+		default:
+			return end
+		}
+		offset = next
+	}
+	return end
+}
+
+// EnsureRuntimeImport 在目标文件开头添加字典模块的导入（已存在时跳过）
+// 导入放在 shebang、"use client" 等指令和开头的注释（如 // @ts-nocheck）之后
+func (i *I18n) EnsureRuntimeImport(targetPath string) error {
+	file, err := ReadTextFile(targetPath)
+	if err != nil {
+		return err
+	}
+
+	rel, err := filepath.Rel(filepath.Dir(targetPath), strings.TrimSuffix(i.RuntimeModulePath(), ".ts"))
+	if err != nil {
+		return err
+	}
+	rel = filepath.ToSlash(rel)
+	if !strings.HasPrefix(rel, ".") {
+		rel = "./" + rel
+	}
+
+	importLine := fmt.Sprintf("import { %s } from %q", RuntimeFunc, rel)
+	if strings.Contains(file.Content, importLine) {
+		return nil
+	}
+
+	// 导入放在 BOM 与开头的指令和注释之后；纯 CRLF 文件的内容已规范化为 LF，写回时恢复
+	at := headerEnd(file.Content)
+	head := file.Content[:at]
+	if head != "" && !strings.HasSuffix(head, "\n") {
+		head += "\n"
+	}
+	file.Content = head + importLine + "\n" + file.Content[at:]
+	return file.Write(targetPath)
+}
+
+var runtimeCallPattern = regexp.MustCompile(regexp.QuoteMeta(RuntimeFunc) + `\("([^"]+)"`)
+
+// VerifyRuntimeCallSites 检查文件中的每个字典调用都有对应条目，返回缺失条目的 "文件: ID" 列表
+func VerifyRuntimeCallSites(files []string, entries map[string]string) ([]string, error) {
+	var missing []string
+	sorted := append([]string(nil), files...)
+	sort.Strings(sorted)
+
+	for _, file := range sorted {
+		content, err := os.ReadFile(file)
+		if err != nil {
+			return nil, err
+		}
+		for _, m := range runtimeCallPattern.FindAllStringSubmatch(string(content), -1) {
+			if _, ok := entries[m[1]]; !ok {
+				missing = append(missing, fmt.Sprintf("%s: %s", file, m[1]))
+			}
+		}
+	}
+
+	return missing, nil
+}
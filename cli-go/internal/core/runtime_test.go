@@ -0,0 +1,218 @@
+package core
+
+import (
+	"strings"
+	"testing"
+)
+
+// ========== PlanRuntimeDictionary 测试 ==========
+
+func TestPlanRuntimeDictionary_Rewrites(t *testing.T) {
+	config := TranslationConfig{
+		FileName: "dialog-model.json",
+		Category: "dialogs",
+		Replacements: map[string]string{
+			`title: "Select model"`:           `title: "选择模型"`,
+			`<text fg={theme.text}>ok</text>`: `<text fg={theme.text}>好的</text>`,
+			`<box title="Commands">`:          `<box title="命令">`,
+		},
+	}
+
+	plan := PlanRuntimeDictionary([]TranslationConfig{config})
+	if len(plan.Skipped) != 0 {
+		t.Fatalf("不应跳过规则: %v", plan.Skipped)
+	}
+
+	tests := []struct {
+		from string
+		want string
+	}{
+		{`title: "Select model"`, `title: __i18n("%s", "Select model")`},
+		{`<text fg={theme.text}>ok</text>`, `<text fg={theme.text}>{__i18n("%s", "ok")}</text>`},
+		{`<box title="Commands">`, `<box title={__i18n("%s", "Commands")}>`},
+	}
+	for _, tt := range tests {
+		id := RuleID(config, tt.from)
+		want := strings.Replace(tt.want, "%s", id, 1)
+		if got := plan.Configs[0].Replacements[tt.from]; got != want {
+			t.Errorf("改写错误:\n got %s\nwant %s", got, want)
+		}
+	}
+
+	if got := plan.Entries[RuleID(config, `title: "Select model"`)]; got != `"选择模型"` {
+		t.Errorf("字典条目错误: got %s", got)
+	}
+	if !strings.HasPrefix(RuleID(config, "x"), "dialogs/dialog-model#") {
+		t.Errorf("规则 ID 格式错误: %s", RuleID(config, "x"))
+	}
+}
+
+func TestRewriteForRuntime_JSXAttribute(t *testing.T) {
+	tests := []struct {
+		from string
+		want string
+	}{
+		// JSX 属性包裹为表达式
+		{`pending="Writing"`, `pending={__i18n("id", "Writing")}`},
+		{`<box a={x > 1} title="T">`, `<box a={x > 1} title={__i18n("id", "T")}>`},
+		{`<input placeholder="Type" />`, `<input placeholder={__i18n("id", "Type")} />`},
+		// 普通 TS/JS 代码保持为表达式
+		{`x="Foo";`, `x=__i18n("id", "Foo");`},
+		{`const x="Foo"`, `const x=__i18n("id", "Foo")`},
+		{`if (a==="Foo")`, `if (a===__i18n("id", "Foo"))`},
+		{`if (a!="Foo")`, `if (a!=__i18n("id", "Foo"))`},
+		{`function f(p="Foo")`, `function f(p=__i18n("id", "Foo"))`},
+		{`label = "Foo"`, `label = __i18n("id", "Foo")`},
+	}
+	for _, tt := range tests {
+		to := strings.Replace(strings.Replace(tt.from, `"Foo"`, `"甲"`, 1), `"Writing"`, `"写入"`, 1)
+		to = strings.Replace(strings.Replace(to, `"T"`, `"标题"`, 1), `"Type"`, `"输入"`, 1)
+		got, _, reason := rewriteForRuntime("id", tt.from, to)
+		if reason != "" {
+			t.Errorf("%s: 不应跳过: %s", tt.from, reason)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("%s 改写错误:\n got %s\nwant %s", tt.from, got, tt.want)
+		}
+	}
+}
+
+func TestPlanRuntimeDictionary_Skipped(t *testing.T) {
+	plan := PlanRuntimeDictionary([]TranslationConfig{{
+		FileName: "app.json",
+		Replacements: map[string]string{
+			"message: `Saved ${path}`": "message: `已保存 ${path}`",
+			"Prev":                     "上一个",
+			`label: "A", x`:            `label: "甲", y`,
+		},
+	}})
+
+	if len(plan.Skipped) != 3 {
+		t.Fatalf("应跳过 3 条规则, got %v", plan.Skipped)
+	}
+	if len(plan.Configs[0].Replacements) != 0 || len(plan.Entries) != 0 {
+		t.Error("跳过的规则不应生成改写或字典条目")
+	}
+}
+
+// ========== 应用与校验测试 ==========
+
+func TestRuntimeStrategy_ApplyAndVerify(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := "packages/opencode/src/cli/cmd/tui/app.tsx"
+	writePackFile(t, tmpDir, target, `const title = "Select model"`+"\n")
+
+	i18n := &I18n{opencodeDir: tmpDir}
+	plan := PlanRuntimeDictionary([]TranslationConfig{{
+		File:         target,
+		FileName:     "app.json",
+		Replacements: map[string]string{`"Select model"`: `"选择模型"`},
+	}})
+
+	result := i18n.ApplyConfig(plan.Configs[0], false)
+	if result.Replacements.Success != 1 {
+		t.Fatalf("改写规则应匹配, got success=%d", result.Replacements.Success)
+	}
+
+	path := i18n.GetTargetFilePath(plan.Configs[0])
+	if err := i18n.EnsureRuntimeImport(path); err != nil {
+		t.Fatalf("添加导入失败: %v", err)
+	}
+	// 重复调用不应重复导入
+	if err := i18n.EnsureRuntimeImport(path); err != nil {
+		t.Fatalf("添加导入失败: %v", err)
+	}
+	if err := i18n.WriteRuntimeModule(plan.Entries); err != nil {
+		t.Fatalf("写入字典模块失败: %v", err)
+	}
+
+	content := readFileString(t, tmpDir, target)
+	importLine := `import { __i18n } from "../../../i18n/opencode-i18n"`
+	if strings.Count(content, importLine) != 1 {
+		t.Errorf("导入语句错误:\n%s", content)
+	}
+
+	module := readFileString(t, tmpDir, RuntimeModuleRelPath)
+	if !strings.Contains(module, `"选择模型"`) || !strings.Contains(module, "process.env.OPENCODE_LANG") {
+		t.Errorf("字典模块内容错误:\n%s", module)
+	}
+
+	if missing, err := VerifyRuntimeCallSites([]string{path}, plan.Entries); err != nil || len(missing) != 0 {
+		t.Errorf("所有调用点都应有条目: %v %v", missing, err)
+	}
+	if missing, _ := VerifyRuntimeCallSites([]string{path}, map[string]string{}); len(missing) != 1 {
+		t.Errorf("应报告缺失条目, got %v", missing)
+	}
+}
+
+func TestRuntimeStrategy_ApplyTwice(t *testing.T) {
+	tmpDir := t.TempDir()
+	target := "packages/opencode/src/app.tsx"
+	writePackFile(t, tmpDir, target, `const a = "Select model"`+"\n"+`const b = "Select model"`+"\n")
+
+	i18n := &I18n{opencodeDir: tmpDir}
+	plan := PlanRuntimeDictionary([]TranslationConfig{{
+		File:         target,
+		FileName:     "app.json",
+		Replacements: map[string]string{`"Select model"`: `"选择模型"`},
+	}})
+
+	i18n.ApplyConfig(plan.Configs[0], false)
+	first := readFileString(t, tmpDir, target)
+	if strings.Count(first, RuntimeFunc+"(") != 2 {
+		t.Fatalf("两处都应改写:\n%s", first)
+	}
+
+	result := i18n.ApplyConfig(plan.Configs[0], false)
+	if got := readFileString(t, tmpDir, target); got != first {
+		t.Errorf("重复应用不应再次包裹:\n got %s\nwant %s", got, first)
+	}
+	if result.Replacements.Success != 1 {
+		t.Errorf("已改写的规则仍应计为匹配: %+v", result.Replacements)
+	}
+}
+
+func TestEnsureRuntimeImport_AfterHeader(t *testing.T) {
+	tests := []struct {
+		name, content, want string
+	}{
+		{
+			"pragmas",
+			"\"use client\"\n// @ts-nocheck\n\nimport a from \"a\"\n",
+			"\"use client\"\n// @ts-nocheck\nIMPORT\n\nimport a from \"a\"\n",
+		},
+		{
+			"shebang and block comment",
+			"#!/usr/bin/env bun\n/*\n * license\n */\nconst x = 1\n",
+			"#!/usr/bin/env bun\n/*\n * license\n */\nIMPORT\nconst x = 1\n",
+		},
+		{
+			"no header",
+			"const x = 1\n",
+			"IMPORT\nconst x = 1\n",
+		},
+		{
+			"crlf",
+			"'use strict';\r\nconst x = 1\r\n",
+			"'use strict';\r\nIMPORT\r\nconst x = 1\r\n",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			tmpDir := t.TempDir()
+			target := "packages/opencode/src/app.tsx"
+			writePackFile(t, tmpDir, target, tt.content)
+
+			i18n := &I18n{opencodeDir: tmpDir}
+			if err := i18n.EnsureRuntimeImport(i18n.GetTargetFilePath(TranslationConfig{File: target})); err != nil {
+				t.Fatal(err)
+			}
+			want := strings.Replace(tt.want, "IMPORT", `import { __i18n } from "./i18n/opencode-i18n"`, 1)
+			if got := readFileString(t, tmpDir, target); got != want {
+				t.Errorf("导入位置错误:\n got %q\nwant %q", got, want)
+			}
+		})
+	}
+}
@@ -0,0 +1,72 @@
+package core
+
+import (
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// SettingsFileName 用户设置文件名
+const SettingsFileName = "settings.json"
+
+// Settings 用户设置 (~/.opencode-i18n/settings.json)，保存跨命令持久的选择
+type Settings struct {
+	// Flavor 当前选择的汉化风味，为空表示不使用
+	Flavor string `json:"flavor,omitempty"`
+	// UpstreamList 上游仓库与镜像，按顺序探测，为空时使用 DefaultUpstreams
+	UpstreamList []Upstream `json:"upstreams,omitempty"`
+	// Mirror 优先使用的上游名称
+	Mirror string `json:"mirror,omitempty"`
+	// SparsePaths 稀疏克隆时检出的目录，为空时使用 DefaultSparsePaths
+	SparsePaths []string `json:"sparsePaths,omitempty"`
+}
+
+// GetSettingsPath 获取用户设置文件路径
+// 统一使用 ~/.opencode-i18n/settings.json，支持环境变量覆盖
+func GetSettingsPath() (string, error) {
+	// 环境变量 OPENCODE_I18N_SETTINGS (开发者可自定义，用于本地调试)
+	if envPath := os.Getenv("OPENCODE_I18N_SETTINGS"); envPath != "" {
+		return envPath, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".opencode-i18n", SettingsFileName), nil
+}
+
+// LoadSettings 读取用户设置，文件不存在时返回空设置
+func LoadSettings() (*Settings, error) {
+	path, err := GetSettingsPath()
+	if err != nil {
+		return nil, err
+	}
+	return loadSettingsFile(path)
+}
+
+// SaveSettings 保存用户设置
+func SaveSettings(settings *Settings) error {
+	path, err := GetSettingsPath()
+	if err != nil {
+		return err
+	}
+	return WriteJSON(path, settings)
+}
+
+// loadSettingsFile 读取指定路径的设置文件
+func loadSettingsFile(path string) (*Settings, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return &Settings{}, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	var settings Settings
+	if err := DecodeJSON(data, &settings); err != nil {
+		return nil, err
+	}
+	return &settings, nil
+}
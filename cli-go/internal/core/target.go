@@ -0,0 +1,203 @@
+package core
+
+import (
+	"fmt"
+	"io/fs"
+	"path"
+	"path/filepath"
+	"strings"
+)
+
+// PackTarget 汉化包声明的上游包范围（config.json targets）
+type PackTarget struct {
+	// Name 目标名称，规则通过 target 字段引用
+	Name string `json:"name"`
+	// Root 包根目录（相对源码根目录，如 packages/opencode）
+	Root string `json:"root"`
+	// Include 包内文件的 glob 列表（相对 Root，支持 **），为空表示全部文件
+	Include []string `json:"include,omitempty"`
+	// Extensions 文件扩展名列表，为空表示不限
+	Extensions []string `json:"extensions,omitempty"`
+}
+
+// DefaultTargets config.json 未声明 targets 时的默认范围（仅 packages/opencode 的 TUI 源码）
+var DefaultTargets = []PackTarget{{
+	Name:       "opencode",
+	Root:       "packages/opencode",
+	Include:    []string{"src/**"},
+	Extensions: []string{".tsx", ".jsx"},
+}}
+
+// Contains 判断源码根目录下的相对路径（/ 分隔）是否属于该目标
+func (t PackTarget) Contains(rel string) bool {
+	root := strings.Trim(t.Root, "/")
+	if root != "" {
+		if !strings.HasPrefix(rel, root+"/") {
+			return false
+		}
+		rel = rel[len(root)+1:]
+	}
+
+	if len(t.Extensions) > 0 {
+		ext := path.Ext(rel)
+		matched := false
+		for _, want := range t.Extensions {
+			if strings.EqualFold(ext, want) {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return false
+		}
+	}
+
+	if len(t.Include) == 0 {
+		return true
+	}
+	for _, pattern := range t.Include {
+		if MatchGlob(pattern, rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// MatchGlob 匹配 / 分隔的路径，** 匹配零个或多个目录，其余按 path.Match 规则
+func MatchGlob(pattern, name string) bool {
+	return matchGlobParts(strings.Split(pattern, "/"), strings.Split(name, "/"))
+}
+
+func matchGlobParts(pattern, name []string) bool {
+	for len(pattern) > 0 {
+		if pattern[0] == "**" {
+			// 连续的 ** 等价于一个
+			for len(pattern) > 0 && pattern[0] == "**" {
+				pattern = pattern[1:]
+			}
+			if len(pattern) == 0 {
+				return true
+			}
+			for n := range name {
+				if matchGlobParts(pattern, name[n:]) {
+					return true
+				}
+			}
+			return false
+		}
+		if len(name) == 0 {
+			return false
+		}
+		if ok, err := path.Match(pattern[0], name[0]); err != nil || !ok {
+			return false
+		}
+		pattern, name = pattern[1:], name[1:]
+	}
+	return len(name) == 0
+}
+
+// Targets 返回生效的目标列表：LoadConfig 读取到的 config.json targets，未声明时为 DefaultTargets
+func (i *I18n) Targets() []PackTarget {
+	if len(i.targets) > 0 {
+		return i.targets
+	}
+	return DefaultTargets
+}
+
+// loadTargets 从 config.json 读取 targets，清单不存在或未声明时使用默认值
+func (i *I18n) loadTargets() {
+	i.targets = nil
+	if manifest, err := i.LoadManifest(); err == nil {
+		i.targets = manifest.Targets
+	}
+}
+
+// targetByName 按名称查找目标
+func (i *I18n) targetByName(name string) (PackTarget, bool) {
+	for _, target := range i.Targets() {
+		if target.Name == name {
+			return target, true
+		}
+	}
+	return PackTarget{}, false
+}
+
+// targetRoot 返回规则 file 所相对的目录（相对源码根目录，/ 分隔）
+// 规则的 target 字段指定目标时为该目标的 Root；以 packages/ 开头的 file 视为相对源码根目录（返回空）；
+// 其余相对第一个目标的 Root
+func (i *I18n) targetRoot(config TranslationConfig) (string, error) {
+	if config.Target != "" {
+		target, ok := i.targetByName(config.Target)
+		if !ok {
+			return "", fmt.Errorf("config.json 中未声明目标 %q", config.Target)
+		}
+		return strings.Trim(target.Root, "/"), nil
+	}
+	if strings.HasPrefix(filepath.ToSlash(config.File), "packages/") {
+		return "", nil
+	}
+	return strings.Trim(i.Targets()[0].Root, "/"), nil
+}
+
+// targetRelPath 返回规则目标文件相对源码根目录的路径（/ 分隔）
+func (i *I18n) targetRelPath(config TranslationConfig) (string, error) {
+	root, err := i.targetRoot(config)
+	if err != nil {
+		return "", err
+	}
+	return path.Join(root, filepath.ToSlash(config.File)), nil
+}
+
+// InTargets 判断规则的目标文件是否在 targets 声明的范围内
+// config.json 未声明 targets 时不限制（兼容旧汉化包），但 target 字段引用的目标必须存在
+func (i *I18n) InTargets(config TranslationConfig) bool {
+	rel, err := i.targetRelPath(config)
+	if err != nil {
+		return false
+	}
+	if len(i.targets) == 0 {
+		return true
+	}
+	for _, target := range i.targets {
+		if target.Contains(rel) {
+			return true
+		}
+	}
+	return false
+}
+
+// TargetFiles 列出源码目录中属于任一目标的全部文件（绝对路径），跳过 node_modules 和隐藏目录
+func (i *I18n) TargetFiles() ([]string, error) {
+	var files []string
+	seen := make(map[string]bool)
+	for _, target := range i.Targets() {
+		root := filepath.Join(i.opencodeDir, filepath.FromSlash(target.Root))
+		if !DirExists(root) {
+			continue
+		}
+		err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+			if err != nil {
+				return err
+			}
+			if d.IsDir() {
+				if p != root && (d.Name() == "node_modules" || strings.HasPrefix(d.Name(), ".")) {
+					return filepath.SkipDir
+				}
+				return nil
+			}
+			rel, err := filepath.Rel(i.opencodeDir, p)
+			if err != nil {
+				return err
+			}
+			if !seen[p] && target.Contains(filepath.ToSlash(rel)) {
+				seen[p] = true
+				files = append(files, p)
+			}
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+	return files, nil
+}
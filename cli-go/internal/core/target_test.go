@@ -0,0 +1,134 @@
+package core
+
+import (
+	"path/filepath"
+	"sort"
+	"testing"
+)
+
+// ========== MatchGlob 测试 ==========
+
+func TestMatchGlob(t *testing.T) {
+	tests := []struct {
+		pattern, name string
+		want          bool
+	}{
+		{"src/**", "src/a.tsx", true},
+		{"src/**", "src/cli/cmd/tui/app.tsx", true},
+		{"src/**/*.tsx", "src/app.tsx", true},
+		{"src/**/*.tsx", "src/ui/dialog.tsx", true},
+		{"src/**/*.tsx", "src/ui/dialog.ts", false},
+		{"src/*.tsx", "src/ui/dialog.tsx", false},
+		{"**/dialog-*.tsx", "src/ui/dialog-help.tsx", true},
+		{"src/**", "test/a.tsx", false},
+		{"app/[a-z]*.ts", "app/main.ts", true},
+	}
+	for _, tt := range tests {
+		if got := MatchGlob(tt.pattern, tt.name); got != tt.want {
+			t.Errorf("MatchGlob(%q, %q) = %v, want %v", tt.pattern, tt.name, got, tt.want)
+		}
+	}
+}
+
+// newTargetPack 创建声明了多个 targets 的汉化包和源码目录
+func newTargetPack(t *testing.T) (*I18n, string) {
+	t.Helper()
+	packDir := t.TempDir()
+	srcDir := t.TempDir()
+	writePackFile(t, packDir, "config.json", `{
+  "targets": [
+    {"name": "opencode", "root": "packages/opencode", "include": ["src/**"], "extensions": [".tsx"]},
+    {"name": "web", "root": "packages/web", "include": ["src/**", "pages/*.astro"]}
+  ]
+}`)
+	writePackFile(t, packDir, "root.json", `{"file": "src/app.tsx", "replacements": {"Hello": "你好"}}`)
+	writePackFile(t, packDir, "web/page.json", `{"target": "web", "file": "pages/index.astro", "replacements": {"Hello": "你好"}}`)
+	writePackFile(t, packDir, "web/outside.json", `{"file": "packages/sdk/src/index.ts", "replacements": {"Hello": "你好"}}`)
+	writePackFile(t, packDir, "web/unknown.json", `{"target": "desktop", "file": "src/main.ts", "replacements": {"Hello": "你好"}}`)
+
+	for _, rel := range []string{
+		"packages/opencode/src/app.tsx",
+		"packages/opencode/src/util.ts",
+		"packages/opencode/test/app.tsx",
+		"packages/web/pages/index.astro",
+		"packages/web/src/node_modules/dep/index.js",
+		"packages/sdk/src/index.ts",
+	} {
+		writePackFile(t, srcDir, rel, `"Hello"`)
+	}
+
+	i18n := &I18n{i18nDir: packDir, opencodeDir: srcDir}
+	return i18n, srcDir
+}
+
+// ========== targets 测试 ==========
+
+func TestTargets_ApplyRespectsDeclaredTargets(t *testing.T) {
+	i18n, srcDir := newTargetPack(t)
+	configs, err := i18n.LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig 失败: %v", err)
+	}
+
+	byName := make(map[string]TranslationConfig)
+	for _, config := range configs {
+		byName[config.FileName] = config
+	}
+
+	if got := i18n.GetTargetFilePath(byName["page.json"]); got != filepath.Join(srcDir, "packages", "web", "pages", "index.astro") {
+		t.Errorf("target 字段路径错误: %s", got)
+	}
+	if i18n.GetTargetFilePath(byName["unknown.json"]) != "" {
+		t.Error("未声明的目标应返回空路径")
+	}
+
+	for name, wantApplied := range map[string]bool{
+		"root.json":    true,
+		"page.json":    true,
+		"outside.json": false,
+		"unknown.json": false,
+	} {
+		result := i18n.ApplyConfig(byName[name], true)
+		if applied := !result.Skipped; applied != wantApplied {
+			t.Errorf("%s: applied = %v, want %v (%s)", name, applied, wantApplied, result.SkipReason)
+		}
+	}
+}
+
+func TestTargets_TargetFiles(t *testing.T) {
+	i18n, srcDir := newTargetPack(t)
+	if _, err := i18n.LoadConfig(); err != nil {
+		t.Fatalf("LoadConfig 失败: %v", err)
+	}
+
+	files, err := i18n.TargetFiles()
+	if err != nil {
+		t.Fatalf("TargetFiles 失败: %v", err)
+	}
+	var rels []string
+	for _, file := range files {
+		rel, _ := filepath.Rel(srcDir, file)
+		rels = append(rels, filepath.ToSlash(rel))
+	}
+	sort.Strings(rels)
+
+	want := []string{"packages/opencode/src/app.tsx", "packages/web/pages/index.astro"}
+	if len(rels) != len(want) || rels[0] != want[0] || rels[1] != want[1] {
+		t.Errorf("TargetFiles = %v, want %v", rels, want)
+	}
+}
+
+func TestTargets_DefaultWithoutDeclaration(t *testing.T) {
+	srcDir := t.TempDir()
+	writePackFile(t, srcDir, "packages/sdk/src/index.ts", `"Hello"`)
+
+	// 未声明 targets 时保持原有行为：不限制范围，file 默认相对 packages/opencode
+	i18n := &I18n{opencodeDir: srcDir}
+	config := TranslationConfig{File: "packages/sdk/src/index.ts", Replacements: map[string]string{"Hello": "你好"}}
+	if result := i18n.ApplyConfig(config, true); result.Skipped {
+		t.Errorf("未声明 targets 时不应跳过: %s", result.SkipReason)
+	}
+	if got := i18n.GetTargetFilePath(TranslationConfig{File: "src/a.tsx"}); got != filepath.Join(srcDir, "packages", "opencode", "src", "a.tsx") {
+		t.Errorf("默认前缀错误: %s", got)
+	}
+}
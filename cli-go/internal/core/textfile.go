@@ -0,0 +1,94 @@
+package core
+
+import (
+	"bytes"
+	"io/fs"
+	"os"
+	"strings"
+)
+
+// utf8BOM UTF-8 字节顺序标记
+const utf8BOM = "\xef\xbb\xbf"
+
+// TextFile 读入内存的源码文件
+// Content 去掉 BOM，纯 CRLF 文件的换行统一为 LF 以便匹配规则；写回时恢复原始格式
+type TextFile struct {
+	Content string
+	// CRLF 文件中所有换行都是 CRLF
+	CRLF bool
+	// MixedNewlines 文件同时包含 CRLF 和 LF，Content 保持原样
+	MixedNewlines bool
+	BOM           bool
+	Mode          fs.FileMode
+}
+
+// ReadTextFile 读取文本文件并记录其换行符风格、BOM 和权限
+// 混合换行的文件保持原样，不做规范化，以免写回时改动未翻译的行
+func ReadTextFile(path string) (*TextFile, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, err
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	f := DecodeTextFile(data)
+	f.Mode = info.Mode().Perm()
+	return f, nil
+}
+
+// DecodeTextFile 按 ReadTextFile 的规则解析内容（如 git show 的输出），不含权限信息
+func DecodeTextFile(data []byte) *TextFile {
+	f := &TextFile{}
+	if bytes.HasPrefix(data, []byte(utf8BOM)) {
+		f.BOM = true
+		data = data[len(utf8BOM):]
+	}
+
+	content := string(data)
+	if crlf := strings.Count(content, "\r\n"); crlf > 0 {
+		if crlf == strings.Count(content, "\n") {
+			f.CRLF = true
+			content = strings.ReplaceAll(content, "\r\n", "\n")
+		} else {
+			f.MixedNewlines = true
+		}
+	}
+	f.Content = content
+	return f
+}
+
+// Newline 返回文件使用的换行符
+func (f *TextFile) Newline() string {
+	if f.CRLF {
+		return "\r\n"
+	}
+	return "\n"
+}
+
+// Bytes 按原始格式编码内容
+func (f *TextFile) Bytes() []byte {
+	content := f.Content
+	if f.CRLF {
+		content = strings.ReplaceAll(content, "\n", "\r\n")
+	}
+	if f.BOM {
+		content = utf8BOM + content
+	}
+	return []byte(content)
+}
+
+// Write 按原始格式和权限写回文件
+func (f *TextFile) Write(path string) error {
+	mode := f.Mode
+	if mode == 0 {
+		mode = 0644
+	}
+	if err := os.WriteFile(path, f.Bytes(), mode); err != nil {
+		return err
+	}
+	// 文件已存在时 WriteFile 不修改权限，这里显式恢复
+	return os.Chmod(path, mode)
+}
@@ -0,0 +1,177 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os/exec"
+	"strings"
+	"time"
+)
+
+// Upstream OpenCode 上游仓库或镜像
+type Upstream struct {
+	Name string `json:"name"`
+	URL  string `json:"url"`
+}
+
+// DefaultUpstreams settings.json 未配置 upstreams 时使用的上游列表（按尝试顺序）
+var DefaultUpstreams = []Upstream{
+	{Name: "github", URL: "https://github.com/anomalyco/opencode.git"},
+	{Name: "gitee", URL: "https://gitee.com/mirrors/opencode.git"},
+}
+
+// UpstreamProbeTimeout 单个上游连通性探测的超时时间
+const UpstreamProbeTimeout = 15 * time.Second
+
+// translationRepoName 汉化项目仓库名，源码目录的 origin 不允许指向它
+const translationRepoName = "OpenCodeChineseTranslation"
+
+// IsTranslationRepo 判断远程地址是否为汉化项目仓库（而非 OpenCode 源码）
+func IsTranslationRepo(url string) bool {
+	return strings.Contains(url, translationRepoName)
+}
+
+// Upstreams 返回用户配置的上游列表，未配置时为 DefaultUpstreams
+// 已选择的镜像 (Mirror) 排在最前
+func (s *Settings) Upstreams() []Upstream {
+	list := s.UpstreamList
+	if len(list) == 0 {
+		list = DefaultUpstreams
+	}
+
+	ordered := make([]Upstream, 0, len(list))
+	for _, upstream := range list {
+		if upstream.Name == s.Mirror {
+			ordered = append(ordered, upstream)
+		}
+	}
+	for _, upstream := range list {
+		if upstream.Name != s.Mirror {
+			ordered = append(ordered, upstream)
+		}
+	}
+	return ordered
+}
+
+// FindUpstream 按名称或地址查找上游
+func FindUpstream(list []Upstream, nameOrURL string) (Upstream, bool) {
+	for _, upstream := range list {
+		if upstream.Name == nameOrURL || sameRemoteURL(upstream.URL, nameOrURL) {
+			return upstream, true
+		}
+	}
+	return Upstream{}, false
+}
+
+// ExcludeUpstream 返回去掉地址为 url 的上游后的列表
+func ExcludeUpstream(list []Upstream, url string) []Upstream {
+	var others []Upstream
+	for _, upstream := range list {
+		if !sameRemoteURL(upstream.URL, url) {
+			others = append(others, upstream)
+		}
+	}
+	return others
+}
+
+// sameRemoteURL 比较两个远程地址（忽略末尾的 / 和 .git）
+func sameRemoteURL(a, b string) bool {
+	normalize := func(url string) string {
+		url = strings.TrimSuffix(strings.TrimSpace(url), "/")
+		return strings.ToLower(strings.TrimSuffix(url, ".git"))
+	}
+	return normalize(a) == normalize(b)
+}
+
+// ProbeUpstream 通过 git ls-remote 探测上游是否可访问
+func ProbeUpstream(url string) error {
+	ctx, cancel := context.WithTimeout(context.Background(), UpstreamProbeTimeout)
+	defer cancel()
+
+	cmd := exec.CommandContext(ctx, "git", "ls-remote", "--heads", url)
+	// 禁止 git 弹出凭据输入，不可访问的仓库直接失败
+	cmd.Env = append(cmd.Environ(), "GIT_TERMINAL_PROMPT=0")
+	if out, err := cmd.CombinedOutput(); err != nil {
+		if ctx.Err() != nil {
+			return fmt.Errorf("连接超时 (%s)", UpstreamProbeTimeout)
+		}
+		return fmt.Errorf("%w: %s", err, strings.TrimSpace(string(out)))
+	}
+	return nil
+}
+
+// SelectUpstream 按顺序探测上游，返回第一个可访问的
+// probe 为 nil 时使用 ProbeUpstream；全部不可访问时返回汇总错误
+func SelectUpstream(list []Upstream, probe func(url string) error) (Upstream, error) {
+	if probe == nil {
+		probe = ProbeUpstream
+	}
+
+	var errs []error
+	for _, upstream := range list {
+		if IsTranslationRepo(upstream.URL) {
+			errs = append(errs, fmt.Errorf("%s: 指向汉化项目仓库，已忽略", upstream.Name))
+			continue
+		}
+		fmt.Printf("正在探测上游 %s (%s)...\n", upstream.Name, upstream.URL)
+		if err := probe(upstream.URL); err != nil {
+			fmt.Printf("  ✗ 不可访问: %v\n", err)
+			errs = append(errs, fmt.Errorf("%s: %w", upstream.Name, err))
+			continue
+		}
+		return upstream, nil
+	}
+	if len(errs) == 0 {
+		return Upstream{}, errors.New("未配置任何上游仓库")
+	}
+	return Upstream{}, fmt.Errorf("所有上游均不可访问: %w", errors.Join(errs...))
+}
+
+// FetchOrigin 从 origin 拉取分支和标签（浅克隆跟随全部标签会拉回完整历史，只拉取分支）
+func FetchOrigin(ctx context.Context, dir string) error {
+	args := []string{"fetch", "origin", "--tags"}
+	if GitIsShallow(dir) {
+		args = args[:2]
+	}
+	return ExecLiveContext(ctx, dir, nil, "git", args...)
+}
+
+// SetOriginURL 将源码仓库的 origin 指向 url 并从中拉取
+// 拒绝操作汉化项目仓库；拉取失败时恢复原地址
+func SetOriginURL(ctx context.Context, dir, url string) error {
+	if IsTranslationRepo(url) {
+		return fmt.Errorf("拒绝将 origin 指向汉化项目仓库: %s", url)
+	}
+
+	current, err := GetGitRemoteURL(dir)
+	if err != nil {
+		// 没有 origin 时直接添加
+		if _, err := ExecInDirQuiet(dir, "git", "remote", "add", "origin", url); err != nil {
+			return err
+		}
+		if err := FetchOrigin(ctx, dir); err != nil {
+			ExecInDirQuiet(dir, "git", "remote", "remove", "origin")
+			return fmt.Errorf("从新上游获取失败: %w", err)
+		}
+		return nil
+	}
+	if IsTranslationRepo(current) {
+		return fmt.Errorf("目标目录是汉化项目仓库 (%s)，而非 OpenCode 源码目录", current)
+	}
+	if sameRemoteURL(current, url) {
+		return FetchOrigin(ctx, dir)
+	}
+
+	fmt.Printf("正在将 origin 从 %s 切换到 %s...\n", current, url)
+	if _, err := ExecInDirQuiet(dir, "git", "remote", "set-url", "origin", url); err != nil {
+		return err
+	}
+	if err := FetchOrigin(ctx, dir); err != nil {
+		if _, restoreErr := ExecInDirQuiet(dir, "git", "remote", "set-url", "origin", current); restoreErr != nil {
+			return fmt.Errorf("从新上游获取失败 (%v)，且恢复原地址失败: %w", err, restoreErr)
+		}
+		return fmt.Errorf("从新上游获取失败，已恢复原地址: %w", err)
+	}
+	return nil
+}
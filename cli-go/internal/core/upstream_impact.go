@@ -0,0 +1,111 @@
+package core
+
+import (
+	"strconv"
+	"strings"
+)
+
+// UpstreamImpact 上游新提交对汉化包的影响
+type UpstreamImpact struct {
+	// Ref 比较的远程分支，如 origin/dev
+	Ref string
+	// Commits 尚未合入的上游提交数
+	Commits int
+	// Touching 其中修改了汉化包引用文件的提交数
+	Touching int
+	// Broken 当前能匹配、但在 Ref 上不再匹配的规则数
+	Broken int
+}
+
+// Harmless 上游更新不涉及汉化包引用的文件
+func (u UpstreamImpact) Harmless() bool {
+	return u.Touching == 0
+}
+
+// UpstreamRef 返回源码仓库用于检测更新的远程分支：优先 origin/dev，其次 origin/main
+func UpstreamRef(dir string) string {
+	if _, err := GitResolveCommit(dir, "origin/dev"); err == nil {
+		return "origin/dev"
+	}
+	return "origin/main"
+}
+
+// UpstreamImpact 比较当前源码与 ref 的内容，统计新提交中涉及汉化文件的数量和将失效的规则
+// 只读取 git 对象（需先 fetch），不修改工作区；位于汉化分支时以其上游提交为基准
+func (i *I18n) UpstreamImpact(configs []TranslationConfig, ref string) (*UpstreamImpact, error) {
+	dir := i.opencodeDir
+	base := "HEAD"
+	if GitCurrentBranch(dir) == TranslationBranch {
+		upstream, err := TranslationBase(dir)
+		if err != nil {
+			return nil, err
+		}
+		base = upstream
+	}
+
+	impact := &UpstreamImpact{Ref: ref}
+	count, err := ExecInDirQuiet(dir, "git", "rev-list", "--count", base+".."+ref)
+	if err != nil {
+		return nil, err
+	}
+	if impact.Commits, err = strconv.Atoi(count); err != nil || impact.Commits == 0 {
+		return impact, err
+	}
+
+	// 汉化包引用的文件及其规则
+	configs, _, err = i.ExpandGlobs(configs)
+	if err != nil {
+		return nil, err
+	}
+	rules := make(map[string][]TranslationConfig)
+	var paths []string
+	for _, config := range configs {
+		if config.File == "" || !i.InTargets(config) {
+			continue
+		}
+		rel, err := i.targetRelPath(config)
+		if err != nil {
+			continue
+		}
+		if _, seen := rules[rel]; !seen {
+			paths = append(paths, rel)
+		}
+		rules[rel] = append(rules[rel], config)
+	}
+	if len(paths) == 0 {
+		return impact, nil
+	}
+
+	args := append([]string{"rev-list", "--count", base + ".." + ref, "--"}, paths...)
+	if count, err = ExecInDirQuiet(dir, "git", args...); err != nil {
+		return nil, err
+	}
+	if impact.Touching, err = strconv.Atoi(count); err != nil || impact.Touching == 0 {
+		return impact, err
+	}
+
+	args = append([]string{"-c", "core.quotepath=off", "diff", "--name-only", base, ref, "--"}, paths...)
+	changed, err := ExecInDirQuiet(dir, "git", args...)
+	if err != nil {
+		return nil, err
+	}
+	for _, rel := range strings.Split(changed, "\n") {
+		if rel == "" {
+			continue
+		}
+		before, ok := gitShowTextFile(dir, base, rel)
+		if !ok {
+			continue
+		}
+		after, exists := gitShowTextFile(dir, ref, rel)
+		for _, config := range rules[rel] {
+			for _, from := range orderedRules(config.Replacements) {
+				to := config.Replacements[from]
+				if i.ruleMatchesIn(before, from, to) && (!exists || !i.ruleMatchesIn(after, from, to)) {
+					impact.Broken++
+				}
+			}
+		}
+	}
+	return impact, nil
+}
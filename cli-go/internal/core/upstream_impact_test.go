@@ -0,0 +1,62 @@
+package core
+
+import (
+	"testing"
+)
+
+// ========== 上游更新影响测试 ==========
+
+func TestUpstreamImpact(t *testing.T) {
+	dir, _ := newGitRepo(t, 1)
+	target := "packages/opencode/src/app.tsx"
+	writePackFile(t, dir, target, "<text>Hello</text>\n<text>Bye</text>\n")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "app")
+
+	i18n := &I18n{opencodeDir: dir}
+	configs := []TranslationConfig{{
+		FileName:     "app.json",
+		File:         target,
+		Replacements: map[string]string{"Hello": "你好", "Bye": "再见", "Missing": "缺失"},
+	}}
+
+	// 模拟 origin/dev：一个无关提交和一个删除 Bye 的提交
+	runGit(t, dir, "checkout", "-q", "-b", "upstream")
+	writePackFile(t, dir, "README.md", "docs")
+	runGit(t, dir, "add", "-A")
+	runGit(t, dir, "commit", "-q", "-m", "docs")
+
+	impact, err := i18n.UpstreamImpact(configs, "HEAD")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if impact.Commits != 0 {
+		t.Errorf("HEAD 相对自身没有新提交: %+v", impact)
+	}
+
+	runGit(t, dir, "checkout", "-q", "dev")
+	impact, err = i18n.UpstreamImpact(configs, "upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if impact.Commits != 1 || !impact.Harmless() {
+		t.Errorf("只修改文档的更新应无影响: %+v", impact)
+	}
+
+	runGit(t, dir, "checkout", "-q", "upstream")
+	writePackFile(t, dir, target, "<text>Hello</text>\n<text>Goodbye now</text>\n")
+	runGit(t, dir, "commit", "-q", "-am", "reword bye")
+	runGit(t, dir, "checkout", "-q", "dev")
+
+	// 工作区中已应用的汉化不影响比较
+	writePackFile(t, dir, target, "<text>你好</text>\n<text>再见</text>\n")
+
+	impact, err = i18n.UpstreamImpact(configs, "upstream")
+	if err != nil {
+		t.Fatal(err)
+	}
+	// Missing 在当前版本已失效，不计入
+	if impact.Commits != 2 || impact.Touching != 1 || impact.Broken != 1 {
+		t.Errorf("应报告 1 个提交涉及汉化、1 条规则失效: %+v", impact)
+	}
+}
@@ -0,0 +1,94 @@
+package core
+
+import (
+	"context"
+	"errors"
+	"strings"
+	"testing"
+)
+
+// ========== 上游选择测试 ==========
+
+func TestSettings_UpstreamsOrder(t *testing.T) {
+	settings := &Settings{}
+	if got := settings.Upstreams(); len(got) != len(DefaultUpstreams) || got[0].Name != "github" {
+		t.Errorf("未配置时应使用默认列表: %v", got)
+	}
+
+	settings.Mirror = "gitee"
+	if got := settings.Upstreams(); got[0].Name != "gitee" || got[1].Name != "github" {
+		t.Errorf("选择的镜像应排在最前: %v", got)
+	}
+
+	settings.UpstreamList = []Upstream{{Name: "corp", URL: "https://git.example.com/opencode.git"}}
+	if got := settings.Upstreams(); len(got) != 1 || got[0].Name != "corp" {
+		t.Errorf("应使用用户配置的列表: %v", got)
+	}
+}
+
+func TestFindUpstream(t *testing.T) {
+	if upstream, ok := FindUpstream(DefaultUpstreams, "https://github.com/anomalyco/opencode/"); !ok || upstream.Name != "github" {
+		t.Errorf("应按地址匹配（忽略 .git 和 /）: %v %v", upstream, ok)
+	}
+	if _, ok := FindUpstream(DefaultUpstreams, "gitlab"); ok {
+		t.Error("未知名称不应匹配")
+	}
+	if others := ExcludeUpstream(DefaultUpstreams, "https://github.com/anomalyco/opencode"); len(others) != 1 || others[0].Name != "gitee" {
+		t.Errorf("应排除当前 origin: %v", others)
+	}
+}
+
+func TestSelectUpstream_FallbackChain(t *testing.T) {
+	list := []Upstream{
+		{Name: "github", URL: "https://github.com/anomalyco/opencode.git"},
+		{Name: "fork", URL: "https://github.com/someone/OpenCodeChineseTranslation.git"},
+		{Name: "gitee", URL: "https://gitee.com/mirrors/opencode.git"},
+	}
+
+	var probed []string
+	probe := func(url string) error {
+		probed = append(probed, url)
+		if strings.Contains(url, "github") {
+			return errors.New("timeout")
+		}
+		return nil
+	}
+
+	upstream, err := SelectUpstream(list, probe)
+	if err != nil || upstream.Name != "gitee" {
+		t.Fatalf("应回退到 gitee: %v %v", upstream, err)
+	}
+	// 指向汉化项目仓库的条目不探测
+	if len(probed) != 2 {
+		t.Errorf("应探测 2 个上游, got %v", probed)
+	}
+
+	if _, err := SelectUpstream(list[:1], probe); err == nil || !strings.Contains(err.Error(), "github") {
+		t.Errorf("全部不可访问时应返回汇总错误: %v", err)
+	}
+}
+
+func TestSetOriginURL(t *testing.T) {
+	dir, _ := newGitRepo(t, 1)
+	// 本地仓库作为上游，fetch 可以成功
+	origin, _ := newGitRepo(t, 1)
+
+	if err := SetOriginURL(context.Background(), dir, origin); err != nil {
+		t.Fatalf("添加 origin 失败: %v", err)
+	}
+	if url, _ := GetGitRemoteURL(dir); url != origin {
+		t.Errorf("origin 应为 %s, got %s", origin, url)
+	}
+
+	// 无法访问的新地址：恢复原地址
+	if err := SetOriginURL(context.Background(), dir, origin+"-missing"); err == nil {
+		t.Error("fetch 失败时应返回错误")
+	}
+	if url, _ := GetGitRemoteURL(dir); url != origin {
+		t.Errorf("失败后应恢复原地址, got %s", url)
+	}
+
+	if err := SetOriginURL(context.Background(), dir, "https://github.com/x/OpenCodeChineseTranslation.git"); err == nil {
+		t.Error("应拒绝指向汉化项目仓库")
+	}
+}
@@ -1,6 +1,7 @@
 package core
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -185,6 +186,24 @@ func ExecLiveEnv(name string, args []string, env []string) error {
 	return cmd.Run()
 }
 
+// ExecLiveContext 在 dir 中实时执行命令（dir 为空表示当前目录，env 为空表示继承环境变量）
+// ctx 取消时终止子进程并返回 ctx 的错误
+func ExecLiveContext(ctx context.Context, dir string, env []string, name string, args ...string) error {
+	cmd := exec.CommandContext(ctx, name, args...)
+	cmd.Dir = dir
+	cmd.Env = env
+	cmd.Stdout = os.Stdout
+	cmd.Stderr = os.Stderr
+	cmd.Stdin = os.Stdin
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		return err
+	}
+	return nil
+}
+
 // Exists 检查路径是否存在
 func Exists(path string) bool {
 	_, err := os.Stat(path)
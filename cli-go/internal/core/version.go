@@ -66,6 +66,56 @@ func GetOpencodeInfo() OpencodeInfo {
 	return info
 }
 
+// SupportedStatus 源码 HEAD 相对汉化包 supportedCommit 的位置
+type SupportedStatus struct {
+	// Commit supportedCommit 的短哈希，汉化包未声明时为空
+	Commit string
+	// Known 本地仓库中存在该提交（否则需先 fetch）
+	Known  bool
+	Ahead  int
+	Behind int
+}
+
+// OnSupported 判断源码是否正好位于 supportedCommit
+func (s SupportedStatus) OnSupported() bool {
+	return s.Known && s.Ahead == 0 && s.Behind == 0
+}
+
+// Summary 返回简短的中文描述
+func (s SupportedStatus) Summary() string {
+	switch {
+	case s.Commit == "":
+		return "汉化包未声明支持版本"
+	case !s.Known:
+		return fmt.Sprintf("支持版本 %s 不在本地仓库", s.Commit)
+	case s.OnSupported():
+		return fmt.Sprintf("源码位于支持版本 %s", s.Commit)
+	case s.Behind == 0:
+		return fmt.Sprintf("源码领先支持版本 %s %d 个提交", s.Commit, s.Ahead)
+	case s.Ahead == 0:
+		return fmt.Sprintf("源码落后支持版本 %s %d 个提交", s.Commit, s.Behind)
+	default:
+		return fmt.Sprintf("源码与支持版本 %s 分叉 (领先 %d, 落后 %d)", s.Commit, s.Ahead, s.Behind)
+	}
+}
+
+// GetSupportedStatus 比较源码 HEAD 与 supportedCommit
+func GetSupportedStatus(dir, supportedCommit string) SupportedStatus {
+	status := SupportedStatus{Commit: ShortHash(supportedCommit)}
+	if supportedCommit == "" {
+		return status
+	}
+	if _, err := GitResolveCommit(dir, supportedCommit); err != nil {
+		return status
+	}
+	ahead, behind, err := GitAheadBehind(dir, supportedCommit, "HEAD")
+	if err != nil {
+		return status
+	}
+	status.Known, status.Ahead, status.Behind = true, ahead, behind
+	return status
+}
+
 // GetOpencodeChangelog 获取 OpenCode 更新日志
 func GetOpencodeChangelog(limit int) string {
 	opencodeDir, err := GetOpencodeDir()
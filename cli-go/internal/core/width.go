@@ -0,0 +1,116 @@
+package core
+
+import (
+	"strings"
+	"unicode"
+
+	"github.com/mattn/go-runewidth"
+)
+
+// DefaultWidthRatio 译文显示宽度相对原文的默认上限
+// CJK 字符占两个终端单元格，超过该比例的译文可能在固定宽度的 TUI 布局中溢出
+const DefaultWidthRatio = 1.5
+
+// WidthIssue 显示宽度超限的翻译条目
+type WidthIssue struct {
+	Config    *TranslationConfig
+	From      string
+	To        string
+	FromWidth int
+	ToWidth   int
+	Limit     float64
+}
+
+// Ratio 返回译文与原文的显示宽度比
+func (w WidthIssue) Ratio() float64 {
+	if w.FromWidth == 0 {
+		return 0
+	}
+	return float64(w.ToWidth) / float64(w.FromWidth)
+}
+
+// DisplayWidth 计算字符串在终端中的显示宽度（单元格数）
+// 多行文本取最宽一行
+func DisplayWidth(s string) int {
+	maxWidth := 0
+	for _, line := range strings.Split(s, "\n") {
+		if w := runewidth.StringWidth(line); w > maxWidth {
+			maxWidth = w
+		}
+	}
+	return maxWidth
+}
+
+// WidthRatioLimit 返回配置文件生效的宽度比例上限
+// 配置文件中的 maxWidthRatio 优先于全局默认值
+func (c *TranslationConfig) WidthRatioLimit(defaultRatio float64) float64 {
+	if c.MaxWidthRatio > 0 {
+		return c.MaxWidthRatio
+	}
+	return defaultRatio
+}
+
+// CheckDisplayWidth 检查所有翻译条目的显示宽度增长
+// 只比较原文与译文中实际变化的部分（去掉公共前后缀），避免代码片段稀释比例
+func CheckDisplayWidth(configs []TranslationConfig, defaultRatio float64) []WidthIssue {
+	var issues []WidthIssue
+
+	for idx := range configs {
+		config := &configs[idx]
+		limit := config.WidthRatioLimit(defaultRatio)
+		if limit <= 0 {
+			continue
+		}
+
+		for _, r := range config.GetReplacementsList() {
+			fromPart, toPart := changedParts(r.From, r.To)
+			fromWidth := DisplayWidth(fromPart)
+			toWidth := DisplayWidth(toPart)
+			if fromWidth == 0 {
+				continue
+			}
+			if float64(toWidth) > float64(fromWidth)*limit {
+				issues = append(issues, WidthIssue{
+					Config:    config,
+					From:      r.From,
+					To:        r.To,
+					FromWidth: fromWidth,
+					ToWidth:   toWidth,
+					Limit:     limit,
+				})
+			}
+		}
+	}
+
+	return issues
+}
+
+// changedParts 去掉两个字符串的公共前缀和后缀，返回各自变化的部分
+// 公共部分的边界回退到单词边界，避免 "MCPs" -> "MCP服务器" 只比较 "s"
+func changedParts(a, b string) (string, string) {
+	ar, br := []rune(a), []rune(b)
+
+	prefix := 0
+	for prefix < len(ar) && prefix < len(br) && ar[prefix] == br[prefix] {
+		prefix++
+	}
+	for prefix > 0 && isWordRune(ar[prefix-1]) {
+		prefix--
+	}
+
+	suffix := 0
+	for suffix < len(ar)-prefix && suffix < len(br)-prefix &&
+		ar[len(ar)-1-suffix] == br[len(br)-1-suffix] {
+		suffix++
+	}
+	for suffix > 0 && isWordRune(ar[len(ar)-suffix]) {
+		suffix--
+	}
+
+	return string(ar[prefix : len(ar)-suffix]), string(br[prefix : len(br)-suffix])
+}
+
+// isWordRune 判断字符是否属于单词（字母或数字）
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
@@ -0,0 +1,52 @@
+package core
+
+import "testing"
+
+// ========== DisplayWidth 测试 ==========
+
+func TestDisplayWidth_CJK(t *testing.T) {
+	if w := DisplayWidth("Select model"); w != 12 {
+		t.Errorf("英文宽度错误: got %d, want 12", w)
+	}
+	if w := DisplayWidth("选择模型"); w != 8 {
+		t.Errorf("中文宽度错误: got %d, want 8", w)
+	}
+	if w := DisplayWidth("short\n更长的一行"); w != 10 {
+		t.Errorf("多行应取最宽一行: got %d, want 10", w)
+	}
+}
+
+// ========== CheckDisplayWidth 测试 ==========
+
+func TestCheckDisplayWidth_FlagsGrowth(t *testing.T) {
+	configs := []TranslationConfig{{
+		File: "src/app.tsx",
+		Replacements: map[string]string{
+			`title="MCPs"`:          `title="MCP服务器"`,
+			`return "Select model"`: `return "选择模型"`,
+		},
+	}}
+
+	issues := CheckDisplayWidth(configs, 1.5)
+	if len(issues) != 1 {
+		t.Fatalf("应有 1 条超限, got %d", len(issues))
+	}
+	// 公共前缀应回退到单词边界，比较 "MCPs" 而非 "s"
+	if issues[0].FromWidth != 4 || issues[0].ToWidth != 9 {
+		t.Errorf("宽度计算错误: got %d -> %d, want 4 -> 9", issues[0].FromWidth, issues[0].ToWidth)
+	}
+}
+
+func TestCheckDisplayWidth_PerFileOverride(t *testing.T) {
+	configs := []TranslationConfig{{
+		File:          "src/app.tsx",
+		MaxWidthRatio: 3,
+		Replacements: map[string]string{
+			`title="MCPs"`: `title="MCP服务器"`,
+		},
+	}}
+
+	if issues := CheckDisplayWidth(configs, 1.5); len(issues) != 0 {
+		t.Errorf("maxWidthRatio 覆盖后不应报告, got %d 条", len(issues))
+	}
+}
@@ -0,0 +1,200 @@
+package core
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+)
+
+// LocaleEnglish 不应用汉化的构建
+const LocaleEnglish = "en"
+
+// Worktree 构建用的独立 git worktree
+type Worktree struct {
+	Name   string
+	Path   string
+	Commit string
+	// ModTime 最近一次创建或重置的时间
+	ModTime time.Time
+	// NodeModules 已安装依赖的 node_modules 目录
+	NodeModules []string
+}
+
+// GetWorktreesDir 获取构建 worktree 目录
+// 统一使用 ~/.opencode-i18n/worktrees，支持环境变量覆盖
+func GetWorktreesDir() (string, error) {
+	// 环境变量 OPENCODE_WORKTREES_DIR (开发者可自定义，用于本地调试)
+	if envDir := os.Getenv("OPENCODE_WORKTREES_DIR"); envDir != "" {
+		return envDir, nil
+	}
+
+	homeDir, err := os.UserHomeDir()
+	if err != nil {
+		return "", err
+	}
+	return filepath.Join(homeDir, ".opencode-i18n", "worktrees"), nil
+}
+
+// unsafeNameChars worktree 目录名中不允许的字符
+var unsafeNameChars = regexp.MustCompile(`[^A-Za-z0-9._-]+`)
+
+// WorktreeName 返回 ref 与语言对应的 worktree 目录名，如 v1.1.53-zh-CN、dev-en
+func WorktreeName(ref, locale string) string {
+	name := strings.Trim(unsafeNameChars.ReplaceAllString(ref, "-"), "-.")
+	if name == "" {
+		name = "HEAD"
+	}
+	return name + "-" + locale
+}
+
+// ParseLocale 校验构建语言
+func ParseLocale(locale string) (string, error) {
+	switch locale {
+	case RuntimeLocale, LocaleEnglish:
+		return locale, nil
+	}
+	return "", fmt.Errorf("未知语言 %q (可选 %s / %s)", locale, RuntimeLocale, LocaleEnglish)
+}
+
+// ScanWorktreeName 逐个版本检查汉化匹配率时复用的 worktree
+const ScanWorktreeName = "versions-scan"
+
+// CreateWorktree 在 worktrees 目录中为 ref 创建（或重置）独立的工作区
+// sourceDir 为主源码仓库；已存在的 worktree 被强制重置到 ref 的提交并清理未跟踪文件，已安装的 node_modules 保留
+func CreateWorktree(sourceDir, ref, locale string) (*Worktree, error) {
+	return prepareWorktree(sourceDir, WorktreeName(ref, locale), ref)
+}
+
+// ScanWorktree 将 ScanWorktreeName 工作区重置到 ref，用于只读检查
+func ScanWorktree(sourceDir, ref string) (*Worktree, error) {
+	return prepareWorktree(sourceDir, ScanWorktreeName, ref)
+}
+
+// prepareWorktree 创建名为 name 的 worktree 或将其重置到 ref
+func prepareWorktree(sourceDir, name, ref string) (*Worktree, error) {
+	root, err := GetWorktreesDir()
+	if err != nil {
+		return nil, err
+	}
+	commit, err := GitResolveCommit(sourceDir, ref)
+	if err != nil {
+		return nil, fmt.Errorf("无法解析 %s: %w", ref, err)
+	}
+
+	wt := &Worktree{Name: name, Commit: commit}
+	wt.Path = filepath.Join(root, wt.Name)
+
+	if DirExists(wt.Path) {
+		fmt.Printf("正在重置工作区 %s 到 %s...\n", wt.Name, ShortHash(commit))
+		if _, err := ExecInDirQuiet(wt.Path, "git", "checkout", "-q", "--force", "--detach", commit); err != nil {
+			return nil, err
+		}
+		// -fd 不删除被忽略的文件，node_modules 得以保留
+		if _, err := ExecInDirQuiet(wt.Path, "git", "clean", "-fdq"); err != nil {
+			return nil, err
+		}
+	} else {
+		if err := EnsureDir(root); err != nil {
+			return nil, err
+		}
+		fmt.Printf("正在创建工作区 %s (%s)...\n", wt.Name, ShortHash(commit))
+		if _, err := ExecInDirQuiet(sourceDir, "git", "worktree", "add", "--detach", wt.Path, commit); err != nil {
+			return nil, err
+		}
+	}
+
+	now := time.Now()
+	os.Chtimes(wt.Path, now, now)
+	wt.ModTime = now
+	wt.NodeModules = findNodeModules(wt.Path)
+	return wt, nil
+}
+
+// ListWorktrees 列出主源码仓库在 worktrees 目录中的全部 worktree，按名称排序
+func ListWorktrees(sourceDir string) ([]Worktree, error) {
+	root, err := GetWorktreesDir()
+	if err != nil {
+		return nil, err
+	}
+	// git 输出的是解析过符号链接的路径
+	if resolved, err := filepath.EvalSymlinks(root); err == nil {
+		root = resolved
+	}
+	out, err := ExecInDirQuiet(sourceDir, "git", "worktree", "list", "--porcelain")
+	if err != nil {
+		return nil, err
+	}
+
+	var list []Worktree
+	var current *Worktree
+	for _, line := range strings.Split(out, "\n") {
+		switch {
+		case strings.HasPrefix(line, "worktree "):
+			path := filepath.Clean(strings.TrimPrefix(line, "worktree "))
+			current = nil
+			if rel, err := filepath.Rel(root, path); err == nil && rel != "." && !strings.HasPrefix(rel, "..") {
+				list = append(list, Worktree{Name: filepath.Base(path), Path: path})
+				current = &list[len(list)-1]
+			}
+		case strings.HasPrefix(line, "HEAD ") && current != nil:
+			current.Commit = strings.TrimPrefix(line, "HEAD ")
+		}
+	}
+
+	for n := range list {
+		if info, err := os.Stat(list[n].Path); err == nil {
+			list[n].ModTime = info.ModTime()
+		}
+		list[n].NodeModules = findNodeModules(list[n].Path)
+	}
+	sort.Slice(list, func(a, b int) bool { return list[a].Name < list[b].Name })
+	return list, nil
+}
+
+// RemoveWorktree 删除 worktree（包括其中的依赖和构建产物）
+func RemoveWorktree(sourceDir string, wt Worktree) error {
+	if _, err := ExecInDirQuiet(sourceDir, "git", "worktree", "remove", "--force", wt.Path); err != nil {
+		// 目录已被手工删除时只需清理记录
+		if DirExists(wt.Path) {
+			return err
+		}
+	}
+	_, err := ExecInDirQuiet(sourceDir, "git", "worktree", "prune")
+	return err
+}
+
+// RemoveNodeModules 删除 worktree 中的 node_modules，保留源码以便下次快速重建
+func RemoveNodeModules(wt Worktree) error {
+	for _, dir := range wt.NodeModules {
+		if err := os.RemoveAll(dir); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// findNodeModules 查找仓库根目录和 packages/* 下的 node_modules
+func findNodeModules(root string) []string {
+	var dirs []string
+	if dir := filepath.Join(root, "node_modules"); DirExists(dir) {
+		dirs = append(dirs, dir)
+	}
+	matches, _ := filepath.Glob(filepath.Join(root, "packages", "*", "node_modules"))
+	for _, dir := range matches {
+		if DirExists(dir) {
+			dirs = append(dirs, dir)
+		}
+	}
+	return dirs
+}
+
+// WithSourceDir 返回使用同一汉化包、但作用于另一个源码目录的副本
+func (i *I18n) WithSourceDir(dir string) *I18n {
+	clone := *i
+	clone.opencodeDir = dir
+	return &clone
+}
@@ -0,0 +1,95 @@
+package core
+
+import (
+	"path/filepath"
+	"testing"
+)
+
+// ========== 构建工作区测试 ==========
+
+func TestWorktreeName(t *testing.T) {
+	cases := map[[2]string]string{
+		{"v1.1.53", "zh-CN"}:     "v1.1.53-zh-CN",
+		{"origin/dev", "en"}:     "origin-dev-en",
+		{"feature/a b", "zh-CN"}: "feature-a-b-zh-CN",
+		{"", "en"}:               "HEAD-en",
+	}
+	for in, want := range cases {
+		if got := WorktreeName(in[0], in[1]); got != want {
+			t.Errorf("WorktreeName(%q, %q) = %q, 期望 %q", in[0], in[1], got, want)
+		}
+	}
+
+	if _, err := ParseLocale("fr"); err == nil {
+		t.Error("未知语言应报错")
+	}
+}
+
+func TestCreateWorktree_IsolatedFromSource(t *testing.T) {
+	dir, hashes := newGitRepo(t, 2)
+	t.Setenv("OPENCODE_WORKTREES_DIR", filepath.Join(t.TempDir(), "worktrees"))
+
+	wt, err := CreateWorktree(dir, "v1", RuntimeLocale)
+	if err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+	if wt.Name != "v1-zh-CN" || wt.Commit != hashes[0] {
+		t.Errorf("工作区信息不符: %+v", wt)
+	}
+	if got := readFileString(t, wt.Path, "file.txt"); got != "x" {
+		t.Errorf("工作区应检出 v1: %q", got)
+	}
+
+	// 在工作区中修改不影响主仓库
+	writePackFile(t, wt.Path, "file.txt", "汉化")
+	writePackFile(t, wt.Path, "untracked.txt", "temp")
+	writePackFile(t, wt.Path, "node_modules/dep/index.js", "dep")
+	if got := readFileString(t, dir, "file.txt"); got != "xx" {
+		t.Errorf("主仓库不应被修改: %q", got)
+	}
+
+	// 重建时重置修改和未跟踪文件，被忽略的 node_modules 保留
+	writePackFile(t, dir, ".git/info/exclude", "node_modules/\n")
+	wt, err = CreateWorktree(dir, "dev", RuntimeLocale)
+	if err != nil {
+		t.Fatalf("创建工作区失败: %v", err)
+	}
+	if got := readFileString(t, wt.Path, "file.txt"); got != "xx" {
+		t.Errorf("工作区应检出 dev: %q", got)
+	}
+
+	wt, err = CreateWorktree(dir, "v1", RuntimeLocale)
+	if err != nil {
+		t.Fatalf("重置工作区失败: %v", err)
+	}
+	if got := readFileString(t, wt.Path, "file.txt"); got != "x" {
+		t.Errorf("重置后应恢复 v1 的内容: %q", got)
+	}
+	if Exists(filepath.Join(wt.Path, "untracked.txt")) {
+		t.Error("重置后应清理未跟踪文件")
+	}
+	if len(wt.NodeModules) != 1 {
+		t.Fatalf("node_modules 应保留: %v", wt.NodeModules)
+	}
+
+	list, err := ListWorktrees(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(list) != 2 || list[0].Name != "dev-zh-CN" || list[1].Name != "v1-zh-CN" {
+		t.Fatalf("应列出两个工作区（不含主仓库）: %+v", list)
+	}
+
+	if err := RemoveNodeModules(list[1]); err != nil || DirExists(list[1].NodeModules[0]) {
+		t.Errorf("应删除 node_modules: %v", err)
+	}
+	if err := RemoveWorktree(dir, list[1]); err != nil {
+		t.Fatalf("删除工作区失败: %v", err)
+	}
+	if DirExists(list[1].Path) {
+		t.Error("工作区目录应被删除")
+	}
+	if list, _ := ListWorktrees(dir); len(list) != 1 {
+		t.Errorf("删除后应剩一个工作区: %+v", list)
+	}
+}
@@ -14,12 +14,13 @@ type Tutorial struct {
 	Content []string
 }
 
-// 主菜单项目 (24个功能)
+// 主菜单项目 (25个功能)
 var MainMenuItems = []MenuItem{
 	{Key: "[*]", Name: "一键全流程", Value: "full", Desc: "一键全流程：清理 + 更新 + 汉化 + 验证 + 编译 + 部署。推荐首次使用。"},
 	{Key: "[D]", Name: "下载预编译版", Value: "download", Desc: "从 GitHub Releases 下载预编译的汉化版，无需 Bun/Node.js 环境。"},
 	{Key: "[E]", Name: "安装编译环境", Value: "env-install", Desc: "一键安装编译所需环境 (Git/Node.js/Bun/npm)，支持 Windows/macOS/Linux。"},
 	{Key: "[>]", Name: "更新源码", Value: "update", Desc: "从 GitHub 获取最新 OpenCode 源码。如遇冲突可选择强制覆盖。"},
+	{Key: "[R]", Name: "选择版本", Value: "versions", Desc: "列出上游发布标签及汉化包支持情况，检出指定版本并报告汉化匹配率。"},
 	{Key: "[~]", Name: "恢复源码", Value: "restore", Desc: "使用 Git 清除所有本地修改，恢复到纯净状态。用于解决汉化冲突或重置。"},
 	{Key: "[W]", Name: "应用汉化", Value: "apply", Desc: "将 opencode-i18n 中的汉化配置注入到源码中。包含变量保护机制。"},
 	{Key: "[V]", Name: "验证汉化", Value: "verify", Desc: "检查汉化配置格式、变量完整性以及翻译覆盖率。推荐在编译前运行。"},
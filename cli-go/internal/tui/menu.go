@@ -2,6 +2,7 @@ package tui
 
 import (
 	"fmt"
+	"io"
 	"os"
 	"os/exec"
 	"strings"
@@ -39,12 +40,17 @@ type StatusInfo struct {
 	ScriptUpdate  bool
 	SourceUpdate  bool
 	CheckComplete bool
+	// Supported 源码相对汉化包 supportedCommit 的位置
+	Supported core.SupportedStatus
+	// SourceImpact 上游新提交对汉化的影响，无法分析时为 nil
+	SourceImpact *core.UpstreamImpact
 }
 
 // StatusUpdateMsg 状态更新消息
 type StatusUpdateMsg struct {
 	ScriptUpdate  bool
 	SourceUpdate  bool
+	SourceImpact  *core.UpstreamImpact
 	CheckComplete bool
 }
 
@@ -71,9 +77,14 @@ func (m MenuModel) Init() tea.Cmd {
 func checkUpdateCmd() tea.Msg {
 	scriptUpdate := checkScriptUpdate()
 	sourceUpdate := checkSourceUpdate()
+	var sourceImpact *core.UpstreamImpact
+	if sourceUpdate {
+		sourceImpact = checkSourceImpact()
+	}
 	return StatusUpdateMsg{
 		ScriptUpdate:  scriptUpdate,
 		SourceUpdate:  sourceUpdate,
+		SourceImpact:  sourceImpact,
 		CheckComplete: true,
 	}
 }
@@ -98,6 +109,27 @@ func checkSourceUpdate() bool {
 	return checkGitUpdate(opencodeDir)
 }
 
+// checkSourceImpact 分析上游新提交涉及的汉化文件和将失效的规则（在 checkSourceUpdate 的 fetch 之后调用）
+func checkSourceImpact() *core.UpstreamImpact {
+	opencodeDir, err := getOpencodeDir()
+	if err != nil {
+		return nil
+	}
+	i18n, err := core.ResolveI18n(io.Discard)
+	if err != nil {
+		return nil
+	}
+	configs, err := i18n.LoadConfig()
+	if err != nil {
+		return nil
+	}
+	impact, err := i18n.UpstreamImpact(configs, core.UpstreamRef(opencodeDir))
+	if err != nil {
+		return nil
+	}
+	return impact
+}
+
 // checkGitUpdate 检查 Git 仓库是否有更新
 func checkGitUpdate(dir string) bool {
 	if dir == "" {
@@ -118,19 +150,19 @@ func checkGitUpdate(dir string) bool {
 	}
 
 	// git fetch --quiet
-	fetchCmd := exec.Command("git", "fetch", "--quiet")
-	fetchCmd.Dir = dir
-	// 显式静默：防止任何后台输出破坏 TUI 界面
-	fetchCmd.Stdout = nil
-	fetchCmd.Stderr = nil
-	fetchCmd.Run() // 忽略错误，如果没网等情况
+	// 其他终端或菜单中的操作正在 update / apply 等修改源码时跳过（锁不可重入），只比较已获取的提交
+	if lock, err := core.AcquireLock(dir, core.LockExclusive, "opencode-cli (菜单后台检查更新)"); err == nil {
+		fetchCmd := exec.Command("git", "fetch", "--quiet")
+		fetchCmd.Dir = dir
+		// 显式静默：防止任何后台输出破坏 TUI 界面
+		fetchCmd.Stdout = nil
+		fetchCmd.Stderr = nil
+		fetchCmd.Run() // 忽略错误，如果没网等情况
+		lock.Release()
+	}
 
 	// 优先检查 origin/dev (开发分支)，其次 origin/main
-	target := "origin/dev"
-	// 检查 origin/dev 是否存在
-	if err := exec.Command("git", "-C", dir, "rev-parse", "--verify", "origin/dev").Run(); err != nil {
-		target = "origin/main"
-	}
+	target := core.UpstreamRef(dir)
 
 	// git rev-list --count HEAD..target
 	revCmd := exec.Command("git", "rev-list", "--count", "HEAD.."+target)
@@ -192,6 +224,7 @@ func (m MenuModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	case StatusUpdateMsg:
 		m.Status.ScriptUpdate = msg.ScriptUpdate
 		m.Status.SourceUpdate = msg.SourceUpdate
+		m.Status.SourceImpact = msg.SourceImpact
 		m.Status.CheckComplete = msg.CheckComplete
 		return m, nil
 
@@ -564,16 +597,54 @@ func (m MenuModel) renderStatus() []string {
 			updates = append(updates, SuccessStyle.Render("✓ 汉化脚本最新"))
 		}
 		if m.Status.SourceUpdate {
-			updates = append(updates, WarnStyle.Render("● OpenCode可更新"))
+			updates = append(updates, m.renderSourceUpdate())
 		} else {
 			updates = append(updates, SuccessStyle.Render("✓ OpenCode最新"))
 		}
 		lines = append(lines, strings.Join(updates, "  "))
 	}
+	if pin := m.renderSupported(); pin != "" {
+		lines[len(lines)-1] += "  " + pin
+	}
 
 	return lines
 }
 
+// renderSourceUpdate 渲染上游更新及其对汉化的影响
+// 与脚本更新、支持版本共用一行，措辞保持紧凑
+func (m MenuModel) renderSourceUpdate() string {
+	impact := m.Status.SourceImpact
+	switch {
+	case impact == nil:
+		return WarnStyle.Render("● OpenCode可更新")
+	case impact.Harmless():
+		return SuccessStyle.Render(fmt.Sprintf("● OpenCode+%d 不涉及汉化", impact.Commits))
+	case impact.Broken == 0:
+		return WarnStyle.Render(fmt.Sprintf("● OpenCode+%d 汉化相关%d", impact.Commits, impact.Touching))
+	default:
+		return ErrorStyle.Render(fmt.Sprintf("● OpenCode+%d 汉化相关%d 失效规则%d", impact.Commits, impact.Touching, impact.Broken))
+	}
+}
+
+// renderSupported 渲染源码相对支持版本的位置
+func (m MenuModel) renderSupported() string {
+	s := m.Status.Supported
+	switch {
+	case !m.Status.SourceExists || s.Commit == "":
+		return ""
+	case !s.Known:
+		return DimStyle.Render("? 支持版本未获取")
+	case s.OnSupported():
+		return SuccessStyle.Render("✓ 位于支持版本")
+	case s.Behind == 0:
+		return WarnStyle.Render(fmt.Sprintf("↑%d 领先支持版本", s.Ahead))
+	case s.Ahead == 0:
+		return WarnStyle.Render(fmt.Sprintf("↓%d 落后支持版本", s.Behind))
+	default:
+		return WarnStyle.Render(fmt.Sprintf("↑%d↓%d 偏离支持版本", s.Ahead, s.Behind))
+	}
+}
+
 // 辅助函数
 
 func padRight(s string, width int) string {
@@ -0,0 +1,299 @@
+// Package i18n 提供可嵌入的 OpenCode 汉化引擎
+//
+// 与命令行不同，这里的所有输入都通过 Options 显式传入：汉化包来自任意 fs.FS，
+// 源码目录由调用方指定，日志写入调用方提供的 io.Writer，不读取环境变量也不写标准输出。
+//
+//	engine, err := i18n.New(i18n.Options{
+//		Pack:      os.DirFS("opencode-i18n"),
+//		SourceDir: "/path/to/opencode",
+//	})
+//	rules, err := engine.Rules(ctx)
+//	report, err := engine.Apply(ctx, rules, i18n.ApplyOptions{DryRun: true})
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"io/fs"
+
+	"opencode-cli/internal/core"
+)
+
+// 结果类型与内部实现共享
+type (
+	// Rule 一个汉化配置文件（目标文件及其替换规则）
+	Rule = core.TranslationConfig
+	// FileResult 单个配置文件的应用结果
+	FileResult = core.ApplyResult
+	// ApplyOptions 批量应用选项
+	ApplyOptions = core.ApplyOptions
+	// ApplyReport 批量应用结果
+	ApplyReport = core.ApplyReport
+	// ApplyStats 批量应用统计
+	ApplyStats = core.ApplyStats
+	// RuntimeReport 运行时字典策略的结果
+	RuntimeReport = core.RuntimeReport
+	// Strategy 汉化应用策略
+	Strategy = core.Strategy
+	// Manifest 汉化包 config.json
+	Manifest = core.PackManifest
+	// ManifestIssue 汉化包清单问题
+	ManifestIssue = core.ManifestIssue
+	// RuleConflict 规则之间的冲突
+	RuleConflict = core.RuleConflict
+	// Occurrence 规则在源码中的一处匹配
+	Occurrence = core.Occurrence
+	// OccurrenceReviewer 交互审查回调
+	OccurrenceReviewer = core.OccurrenceReviewer
+	// ReviewDecision 交互审查的决定
+	ReviewDecision = core.ReviewDecision
+	// Flavor 叠加在译文上的术语替换
+	Flavor = core.Flavor
+	// Target 汉化包覆盖的上游包范围
+	Target = core.PackTarget
+	// GlobExpansion file 为 glob 的规则的展开结果
+	GlobExpansion = core.GlobExpansion
+	// BranchReport 汉化分支的重建结果
+	BranchReport = core.BranchReport
+	// RuleBisect 一条规则在上游提交范围内失效的位置
+	RuleBisect = core.RuleBisect
+	// PackClaim 某个汉化包版本声明支持的上游版本
+	PackClaim = core.PackClaim
+	// VersionMatch 汉化规则在某个上游版本上的匹配情况
+	VersionMatch = core.VersionMatch
+)
+
+const (
+	StrategyReplace = core.StrategyReplace
+	StrategyRuntime = core.StrategyRuntime
+
+	ReviewAccept     = core.ReviewAccept
+	ReviewSkip       = core.ReviewSkip
+	ReviewAcceptRule = core.ReviewAcceptRule
+
+	FlavorNone = core.FlavorNone
+
+	TranslationBranch = core.TranslationBranch
+)
+
+// Options 引擎配置
+type Options struct {
+	// Pack 汉化包（包含 config.json 的目录视图），为 nil 时使用内置汉化包
+	Pack fs.FS
+	// SourceDir OpenCode 源码根目录，只读取汉化包时可以为空
+	SourceDir string
+	// Logger 日志输出，为 nil 时丢弃
+	Logger io.Writer
+}
+
+// Engine 汉化引擎
+type Engine struct {
+	i18n *core.I18n
+}
+
+// New 使用显式配置创建引擎
+func New(opts Options) (*Engine, error) {
+	i18n, err := core.NewI18nWithOptions(core.Options{
+		Pack:      opts.Pack,
+		SourceDir: opts.SourceDir,
+		Output:    opts.Logger,
+	})
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{i18n: i18n}, nil
+}
+
+// Default 按命令行的规则解析汉化包和源码目录（环境变量、项目目录），提示写入 logger
+func Default(logger io.Writer) (*Engine, error) {
+	i18n, err := core.ResolveI18n(logger)
+	if err != nil {
+		return nil, err
+	}
+	return &Engine{i18n: i18n}, nil
+}
+
+// EmbeddedPack 返回内置汉化包
+func EmbeddedPack() fs.FS {
+	return core.EmbeddedPack()
+}
+
+// ParseStrategy 解析应用策略名称
+func ParseStrategy(name string) (Strategy, error) {
+	return core.ParseStrategy(name)
+}
+
+// FindRules 按规则 ID（如 dialogs/dialog-model#1a2b3c4d）或配置文件名查找规则
+// from 非空时只返回原文等于 from 的规则
+func FindRules(rules []Rule, spec, from string) ([]RuleBisect, error) {
+	return core.FindRules(rules, spec, from)
+}
+
+// SourceDir 返回源码根目录
+func (e *Engine) SourceDir() string {
+	return e.i18n.SourceDir()
+}
+
+// WithSourceDir 返回使用同一汉化包、作用于另一个源码目录（如构建 worktree）的引擎
+func (e *Engine) WithSourceDir(dir string) *Engine {
+	return &Engine{i18n: e.i18n.WithSourceDir(dir)}
+}
+
+// PackDir 返回磁盘上的汉化包目录，使用内置汉化包或 fs.FS 时返回 false
+func (e *Engine) PackDir() (string, bool) {
+	return e.i18n.PackDir()
+}
+
+// Rules 读取汉化包中的所有规则
+func (e *Engine) Rules(ctx context.Context) ([]Rule, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.i18n.LoadConfig()
+}
+
+// SelectModules 按 config.json 的模块筛选规则，only 为空表示全部模块
+func (e *Engine) SelectModules(rules []Rule, only, exclude []string) ([]Rule, error) {
+	return e.i18n.SelectModules(rules, only, exclude)
+}
+
+// ApplyFlavor 返回叠加指定风味后的规则副本，name 为空或 FlavorNone 时原样返回
+func (e *Engine) ApplyFlavor(rules []Rule, name string) ([]Rule, error) {
+	return e.i18n.ApplyFlavor(rules, name)
+}
+
+// Selection 模块与风味选择，对应 apply 的 --only / --exclude / --flavor
+type Selection struct {
+	// Only 只使用这些模块，为空表示全部模块
+	Only    []string
+	Exclude []string
+	// Flavor 叠加的风味，为空或 FlavorNone 时不叠加
+	Flavor string
+}
+
+// Select 按 sel 筛选模块并叠加风味
+func (e *Engine) Select(rules []Rule, sel Selection) ([]Rule, error) {
+	rules, err := e.SelectModules(rules, sel.Only, sel.Exclude)
+	if err != nil {
+		return nil, err
+	}
+	return e.ApplyFlavor(rules, sel.Flavor)
+}
+
+// Manifest 读取汉化包的 config.json
+func (e *Engine) Manifest() (*Manifest, error) {
+	return e.i18n.LoadManifest()
+}
+
+// CheckManifest 检查 config.json 清单与汉化包文件是否一致
+func (e *Engine) CheckManifest() ([]ManifestIssue, error) {
+	return e.i18n.CheckManifest()
+}
+
+// Conflicts 检查规则之间的重复、遮蔽与链式替换
+func (e *Engine) Conflicts(rules []Rule) []RuleConflict {
+	return e.i18n.FindRuleConflicts(rules)
+}
+
+// Targets 返回汉化包声明的上游包范围（需先调用 Rules）
+func (e *Engine) Targets() []Target {
+	return e.i18n.Targets()
+}
+
+// InTargets 判断规则的目标文件是否属于 Targets 范围（需先调用 Rules）
+func (e *Engine) InTargets(rule Rule) bool {
+	return e.i18n.InTargets(rule)
+}
+
+// ExpandGlobs 将 file 为 glob 的规则展开为每个匹配文件一条规则
+func (e *Engine) ExpandGlobs(rules []Rule) ([]Rule, []GlobExpansion, error) {
+	return e.i18n.ExpandGlobs(rules)
+}
+
+// TargetFiles 列出源码目录中属于 Targets 范围的全部文件
+func (e *Engine) TargetFiles() ([]string, error) {
+	return e.i18n.TargetFiles()
+}
+
+// TargetPath 返回规则对应的源码文件路径
+func (e *Engine) TargetPath(rule Rule) string {
+	return e.i18n.GetTargetFilePath(rule)
+}
+
+// Occurrences 列出每条规则在源码中的全部匹配位置（不修改文件）
+func (e *Engine) Occurrences(ctx context.Context, rules []Rule, contextLines int) ([]Occurrence, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.i18n.FindOccurrences(rules, contextLines)
+}
+
+// SaveOccurrenceFilters 将匹配过滤器写回规则文件，只支持磁盘上的汉化包
+func (e *Engine) SaveOccurrenceFilters(rule Rule, filters map[string][]int) error {
+	if _, ok := e.PackDir(); !ok {
+		return fmt.Errorf("汉化包不在磁盘上，无法保存 %s", rule.FileName)
+	}
+	// glob 规则展开出的文件各自记录过滤器
+	file := ""
+	if rule.Glob != "" {
+		file = rule.File
+	}
+	return core.SaveOccurrenceFilters(rule.ConfigPath, file, filters)
+}
+
+// RuleMatchesAt 判断规则在源码仓库的 ref 上是否仍能匹配
+func (e *Engine) RuleMatchesAt(rule RuleBisect, ref string) bool {
+	return e.i18n.RuleMatchesAt(rule, ref)
+}
+
+// BisectRule 在源码仓库的 good..bad 范围内查找规则第一次无法匹配的提交
+func (e *Engine) BisectRule(ctx context.Context, rule RuleBisect, good, bad string) (*RuleBisect, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+	return e.i18n.BisectRule(rule, good, bad)
+}
+
+// PackClaims 返回汉化包声明支持的上游版本
+func (e *Engine) PackClaims() ([]PackClaim, error) {
+	return e.i18n.PackClaims()
+}
+
+// MatchRate 统计规则在源码目录中的匹配率（不修改文件）
+func (e *Engine) MatchRate(ctx context.Context, rules []Rule) (*VersionMatch, error) {
+	return e.i18n.MatchRate(ctx, rules)
+}
+
+// Apply 将规则应用到源码目录
+func (e *Engine) Apply(ctx context.Context, rules []Rule, opts ApplyOptions) (*ApplyReport, error) {
+	return e.i18n.ApplyAll(ctx, rules, opts)
+}
+
+// CommitBranch 在源码仓库的 base 提交之上重建 TranslationBranch 分支，应用规则并提交
+// base 为空时：当前位于汉化分支则使用其上游提交，否则使用 HEAD
+func (e *Engine) CommitBranch(ctx context.Context, rules []Rule, base string, opts ApplyOptions) (*BranchReport, error) {
+	if base == "" {
+		base = "HEAD"
+		if core.GitCurrentBranch(e.SourceDir()) == core.TranslationBranch {
+			upstream, err := core.TranslationBase(e.SourceDir())
+			if err != nil {
+				return nil, err
+			}
+			base = upstream
+		}
+	}
+	return e.i18n.BuildTranslationBranch(ctx, base, rules, opts)
+}
+
+// RebuildBranch 在当前检出的上游提交之上，按 sel 选择的模块和风味重建 TranslationBranch 分支
+func (e *Engine) RebuildBranch(ctx context.Context, sel Selection) (*BranchReport, error) {
+	rules, err := e.Rules(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if rules, err = e.Select(rules, sel); err != nil {
+		return nil, err
+	}
+	return e.CommitBranch(ctx, rules, "HEAD", ApplyOptions{})
+}
@@ -0,0 +1,236 @@
+package i18n_test
+
+import (
+	"context"
+	"errors"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"testing"
+	"testing/fstest"
+
+	"opencode-cli/pkg/i18n"
+)
+
+const sourceFile = "packages/opencode/src/a.tsx"
+
+// newEngine 使用内存中的汉化包和临时源码目录创建引擎
+func newEngine(t *testing.T) (*i18n.Engine, string) {
+	t.Helper()
+	pack := fstest.MapFS{
+		"config.json":           {Data: []byte(`{"supportedCommit": "abc123", "modules": {"dialogs": ["dialogs/dialog-a.json"]}}`)},
+		"dialogs/dialog-a.json": {Data: []byte(`{"file": "src/a.tsx", "replacements": {"\"Hello\"": "\"你好\""}}`)},
+	}
+	sourceDir := t.TempDir()
+	writeSource(t, sourceDir, `const s = "Hello"`)
+
+	engine, err := i18n.New(i18n.Options{Pack: pack, SourceDir: sourceDir})
+	if err != nil {
+		t.Fatalf("创建引擎失败: %v", err)
+	}
+	return engine, sourceDir
+}
+
+func writeSource(t *testing.T, dir, content string) {
+	t.Helper()
+	path := filepath.Join(dir, sourceFile)
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.WriteFile(path, []byte(content), 0644); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func readSource(t *testing.T, dir string) string {
+	t.Helper()
+	data, err := os.ReadFile(filepath.Join(dir, sourceFile))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+// ========== New 测试 ==========
+
+func TestNew_MissingManifest(t *testing.T) {
+	if _, err := i18n.New(i18n.Options{Pack: fstest.MapFS{}}); err == nil {
+		t.Error("缺少 config.json 时应返回错误")
+	}
+}
+
+func TestNew_EmbeddedPack(t *testing.T) {
+	engine, err := i18n.New(i18n.Options{Pack: i18n.EmbeddedPack()})
+	if err != nil {
+		t.Fatalf("创建引擎失败: %v", err)
+	}
+	rules, err := engine.Rules(context.Background())
+	if err != nil || len(rules) == 0 {
+		t.Errorf("应读取内置汉化包, got %d (%v)", len(rules), err)
+	}
+}
+
+// ========== Engine 测试 ==========
+
+func TestEngine_RulesAndManifest(t *testing.T) {
+	engine, sourceDir := newEngine(t)
+
+	rules, err := engine.Rules(context.Background())
+	if err != nil || len(rules) != 1 {
+		t.Fatalf("应读取 1 个规则文件, got %d (%v)", len(rules), err)
+	}
+	if got := engine.TargetPath(rules[0]); got != filepath.Join(sourceDir, sourceFile) {
+		t.Errorf("目标路径错误: %s", got)
+	}
+	if _, ok := engine.PackDir(); ok {
+		t.Error("fs.FS 汉化包不应视为磁盘目录")
+	}
+
+	manifest, err := engine.Manifest()
+	if err != nil || manifest.SupportedCommit != "abc123" {
+		t.Errorf("清单读取错误: %+v (%v)", manifest, err)
+	}
+
+	selected, err := engine.SelectModules(rules, nil, []string{"dialogs"})
+	if err != nil || len(selected) != 0 {
+		t.Errorf("排除模块后不应有规则, got %d (%v)", len(selected), err)
+	}
+	if _, err := engine.SelectModules(rules, []string{"missing"}, nil); err == nil {
+		t.Error("未知模块应返回错误")
+	}
+}
+
+func TestEngine_Apply(t *testing.T) {
+	engine, sourceDir := newEngine(t)
+	ctx := context.Background()
+	rules, err := engine.Rules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	occurrences, err := engine.Occurrences(ctx, rules, 0)
+	if err != nil || len(occurrences) != 1 {
+		t.Errorf("应找到 1 处匹配, got %d (%v)", len(occurrences), err)
+	}
+
+	report, err := engine.Apply(ctx, rules, i18n.ApplyOptions{DryRun: true})
+	if err != nil || report.Stats.Replacements.Success != 1 {
+		t.Fatalf("试运行结果错误: %+v (%v)", report, err)
+	}
+	if got := readSource(t, sourceDir); got != `const s = "Hello"` {
+		t.Errorf("试运行不应修改文件: %q", got)
+	}
+
+	if _, err := engine.Apply(ctx, rules, i18n.ApplyOptions{}); err != nil {
+		t.Fatal(err)
+	}
+	if got := readSource(t, sourceDir); got != `const s = "你好"` {
+		t.Errorf("文件内容不正确: %q", got)
+	}
+}
+
+func TestEngine_WithSourceDir(t *testing.T) {
+	engine, _ := newEngine(t)
+	other := t.TempDir()
+	writeSource(t, other, `const s = "Hello"`)
+
+	worktree := engine.WithSourceDir(other)
+	if worktree.SourceDir() != other {
+		t.Errorf("源码目录错误: %s", worktree.SourceDir())
+	}
+	ctx := context.Background()
+	rules, err := worktree.Rules(ctx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	match, err := worktree.MatchRate(ctx, rules)
+	if err != nil || match.Rules != 1 || match.Matched != 1 {
+		t.Errorf("匹配率错误: %+v (%v)", match, err)
+	}
+}
+
+func TestEngine_Canceled(t *testing.T) {
+	engine, _ := newEngine(t)
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	if _, err := engine.Rules(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Rules 应返回 context.Canceled, got %v", err)
+	}
+	if _, err := engine.Occurrences(ctx, nil, 0); !errors.Is(err, context.Canceled) {
+		t.Errorf("Occurrences 应返回 context.Canceled, got %v", err)
+	}
+	if _, err := engine.BisectRule(ctx, i18n.RuleBisect{}, "a", "b"); !errors.Is(err, context.Canceled) {
+		t.Errorf("BisectRule 应返回 context.Canceled, got %v", err)
+	}
+}
+
+func TestFindRules(t *testing.T) {
+	engine, _ := newEngine(t)
+	rules, err := engine.Rules(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	found, err := i18n.FindRules(rules, "dialogs/dialog-a", "")
+	if err != nil || len(found) != 1 || found[0].From != `"Hello"` {
+		t.Fatalf("按配置名查找错误: %+v (%v)", found, err)
+	}
+	byID, err := i18n.FindRules(rules, found[0].ID, "")
+	if err != nil || len(byID) != 1 {
+		t.Errorf("按规则 ID 查找错误: %+v (%v)", byID, err)
+	}
+	if _, err := i18n.FindRules(rules, "missing", ""); err == nil {
+		t.Error("找不到规则时应返回错误")
+	}
+}
+
+// runGit 在 dir 中执行 git 命令并返回输出
+func runGit(t *testing.T, dir string, args ...string) string {
+	t.Helper()
+	cmd := exec.Command("git", args...)
+	cmd.Dir = dir
+	out, err := cmd.CombinedOutput()
+	if err != nil {
+		t.Fatalf("git %v 失败: %v\n%s", args, err, out)
+	}
+	return strings.TrimSpace(string(out))
+}
+
+func TestEngine_RebuildBranchSelection(t *testing.T) {
+	pack := fstest.MapFS{
+		"config.json": {Data: []byte(`{"modules": {"dialogs": ["dialogs/dialog-a.json"], "routes": ["routes/route-b.json"]},
+			"flavors": {"formal": {"terms": {"你好": "您好"}}}}`)},
+		"dialogs/dialog-a.json": {Data: []byte(`{"file": "src/a.tsx", "replacements": {"\"Hello\"": "\"你好\""}}`)},
+		"routes/route-b.json":   {Data: []byte(`{"file": "src/b.tsx", "replacements": {"\"Bye\"": "\"再见\""}}`)},
+	}
+	sourceDir := t.TempDir()
+	runGit(t, sourceDir, "init", "-q", "-b", "dev")
+	runGit(t, sourceDir, "config", "user.email", "test@example.com")
+	runGit(t, sourceDir, "config", "user.name", "test")
+	writeSource(t, sourceDir, `const s = "Hello"`)
+	other := filepath.Join(sourceDir, "packages/opencode/src/b.tsx")
+	if err := os.WriteFile(other, []byte(`const s = "Bye"`), 0644); err != nil {
+		t.Fatal(err)
+	}
+	runGit(t, sourceDir, "add", "-A")
+	runGit(t, sourceDir, "commit", "-q", "-m", "upstream")
+
+	engine, err := i18n.New(i18n.Options{Pack: pack, SourceDir: sourceDir})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sel := i18n.Selection{Only: []string{"dialogs"}, Flavor: "formal"}
+	if _, err := engine.RebuildBranch(context.Background(), sel); err != nil {
+		t.Fatalf("重建汉化分支失败: %v", err)
+	}
+
+	// 只提交所选模块，并叠加风味
+	if got := runGit(t, sourceDir, "show", i18n.TranslationBranch+":"+sourceFile); got != `const s = "您好"` {
+		t.Errorf("所选模块应按风味汉化: %q", got)
+	}
+	if got := runGit(t, sourceDir, "show", i18n.TranslationBranch+":packages/opencode/src/b.tsx"); got != `const s = "Bye"` {
+		t.Errorf("未选择的模块不应汉化: %q", got)
+	}
+}